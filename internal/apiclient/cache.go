@@ -0,0 +1,72 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apiclient
+
+import (
+	"strings"
+	"sync"
+	"time"
+)
+
+// responseCacheTTL is how long a cached response is served within a run
+const responseCacheTTL = 5 * time.Minute
+
+type cachedResponse struct {
+	body    []byte
+	expires time.Time
+}
+
+// responseCache holds GET responses of connector provider and version lookups,
+// which don't change during a run but are repeated by bulk operations
+var responseCache = struct {
+	sync.Mutex
+	entries map[string]cachedResponse
+}{entries: make(map[string]cachedResponse)}
+
+// SetNoResponseCache disables caching of provider and version lookups
+func SetNoResponseCache(b bool) {
+	options.NoResponseCache = b
+}
+
+// GetNoResponseCache
+func GetNoResponseCache() bool {
+	return options.NoResponseCache
+}
+
+// isCacheable returns true for the GET requests whose responses are cached
+func isCacheable(params []string) bool {
+	if len(params) != 1 || GetNoResponseCache() {
+		return false
+	}
+	providersURL := GetBaseConnectorProvidersURL()
+	return providersURL != "" && strings.HasPrefix(params[0], providersURL)
+}
+
+func getCachedResponse(u string) ([]byte, bool) {
+	responseCache.Lock()
+	defer responseCache.Unlock()
+	entry, ok := responseCache.entries[u]
+	if !ok || time.Now().After(entry.expires) {
+		delete(responseCache.entries, u)
+		return nil, false
+	}
+	return entry.body, true
+}
+
+func setCachedResponse(u string, body []byte) {
+	responseCache.Lock()
+	defer responseCache.Unlock()
+	responseCache.entries[u] = cachedResponse{body: body, expires: time.Now().Add(responseCacheTTL)}
+}
@@ -202,6 +202,9 @@ func SetProxy(url string) (err error) {
 	if url == "" {
 		return nil
 	}
+	if err = ValidateProxyURL(url); err != nil {
+		return err
+	}
 	cliPref, err := readPreferencesFile()
 	cliPref.ProxyUrl = url
 	data, err := json.Marshal(&cliPref)
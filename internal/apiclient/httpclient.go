@@ -19,9 +19,11 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"io"
 	"net/http"
-	"net/url"
+	"strings"
+	"text/tabwriter"
 	"time"
 
 	"internal/clilog"
@@ -61,13 +63,21 @@ func HttpClient(params ...string) (respBody []byte, err error) {
 	clilog.Debug.Println("Connecting to: ", params[0])
 	ctx := context.Background()
 
+	cacheable := isCacheable(params)
+	if cacheable {
+		if respBody, ok := getCachedResponse(params[0]); ok {
+			clilog.Debug.Println("Using cached response for: ", params[0])
+			return respBody, PrettyPrint(respBody)
+		}
+	}
+
 	switch paramLen := len(params); paramLen {
 	case 1:
 		req, err = http.NewRequestWithContext(ctx, http.MethodGet, params[0], nil)
 	case 2:
 		// some POST functions don't have a body
 		if len([]byte(params[1])) > 0 {
-			payload, _ := PrettifyJson([]byte(params[1]))
+			payload, _ := PrettifyJson(RedactSecrets([]byte(params[1])))
 			clilog.Debug.Println("Payload: ", string(payload))
 		}
 		req, err = http.NewRequestWithContext(ctx, http.MethodPost, params[0], bytes.NewBuffer([]byte(params[1])))
@@ -89,6 +99,15 @@ func HttpClient(params ...string) (respBody []byte, err error) {
 		return nil, err
 	}
 
+	if GetReplayDir() != "" {
+		resp, err := replayResponse(req)
+		if err != nil {
+			clilog.Error.Println("error replaying response: ", err)
+			return nil, err
+		}
+		return handleResponse(resp)
+	}
+
 	req, err = setAuthHeader(req)
 	if err != nil {
 		return nil, err
@@ -107,14 +126,30 @@ func HttpClient(params ...string) (respBody []byte, err error) {
 		return nil, err
 	}
 
-	return handleResponse(resp)
+	if GetRecordDir() != "" {
+		if resp, err = recordResponse(req, resp); err != nil {
+			clilog.Error.Println("error recording response: ", err)
+			return nil, err
+		}
+	}
+
+	respBody, err = handleResponse(resp)
+	if cacheable && err == nil && respBody != nil {
+		setCachedResponse(params[0], respBody)
+	}
+	return respBody, err
 }
 
 // PrettyPrint method prints formatted json
 func PrettyPrint(body []byte) error {
-	if GetCmdPrintHttpResponseSetting() && ClientPrintHttpResponse.Get() {
+	if !GetNoOutput() && GetCmdPrintHttpResponseSetting() && ClientPrintHttpResponse.Get() {
 		var prettyJSON bytes.Buffer
-		err := json.Indent(&prettyJSON, body, "", "\t")
+		var err error
+		if GetCompactOutput() {
+			err = json.Compact(&prettyJSON, body)
+		} else {
+			err = json.Indent(&prettyJSON, body, "", "\t")
+		}
 		if err != nil {
 			clilog.Error.Println("error parsing response: ", err)
 			return err
@@ -125,6 +160,21 @@ func PrettyPrint(body []byte) error {
 	return nil
 }
 
+// PrintTable prints the rows as tab aligned columns. Nothing, not even the headers,
+// is printed with no output
+func PrintTable(headers []string, rows [][]string) {
+	if !GetNoOutput() && GetCmdPrintHttpResponseSetting() && ClientPrintHttpResponse.Get() {
+		var table bytes.Buffer
+		w := tabwriter.NewWriter(&table, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, strings.Join(headers, "\t"))
+		for _, row := range rows {
+			fmt.Fprintln(w, strings.Join(row, "\t"))
+		}
+		w.Flush()
+		clilog.HTTPResponse.Print(table.String())
+	}
+}
+
 func PrettifyJson(body []byte) (prettyJson []byte, err error) {
 	prettyJSON := bytes.Buffer{}
 	err = json.Indent(&prettyJSON, body, "", "\t")
@@ -135,18 +185,68 @@ func PrettifyJson(body []byte) (prettyJson []byte, err error) {
 	return prettyJSON.Bytes(), err
 }
 
+// RedactSecrets returns a copy of the JSON body with the values of secret and
+// password fields replaced. Bodies that are not JSON are returned as is
+func RedactSecrets(body []byte) []byte {
+	var payload interface{}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return body
+	}
+	redacted, err := json.Marshal(redactValue(payload))
+	if err != nil {
+		return body
+	}
+	return redacted
+}
+
+func redactValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		// config variables hold secrets as {"key": "client_secret", "stringValue": "..."}
+		if key, ok := v["key"].(string); ok && isSecretField(key) {
+			if _, ok := v["stringValue"].(string); ok {
+				v["stringValue"] = "REDACTED"
+			}
+		}
+		// secret details can carry the secret payload inline as {"secretName": "...", "value": "..."}
+		if _, ok := v["secretName"]; ok {
+			if _, ok := v["value"].(string); ok {
+				v["value"] = "REDACTED"
+			}
+		}
+		for key, fieldValue := range v {
+			if _, ok := fieldValue.(string); ok && isSecretField(key) {
+				v[key] = "REDACTED"
+			} else {
+				v[key] = redactValue(fieldValue)
+			}
+		}
+	case []interface{}:
+		for index := range v {
+			v[index] = redactValue(v[index])
+		}
+	}
+	return value
+}
+
+func isSecretField(key string) bool {
+	k := strings.ToLower(key)
+	return strings.Contains(k, "secret") || strings.Contains(k, "password") ||
+		k == "clientkey" || k == "privatekey" || k == "token"
+}
+
 func getRequest(params []string) (req *http.Request, err error) {
 	ctx := context.Background()
 	if params[2] == "DELETE" {
 		req, err = http.NewRequestWithContext(ctx, http.MethodDelete, params[0], nil)
 	} else if params[2] == "PUT" {
-		clilog.Debug.Println("Payload: ", params[1])
+		clilog.Debug.Println("Payload: ", string(RedactSecrets([]byte(params[1]))))
 		req, err = http.NewRequestWithContext(ctx, http.MethodPut, params[0], bytes.NewBuffer([]byte(params[1])))
 	} else if params[2] == "PATCH" {
-		clilog.Debug.Println("Payload: ", params[1])
+		clilog.Debug.Println("Payload: ", string(RedactSecrets([]byte(params[1]))))
 		req, err = http.NewRequestWithContext(ctx, http.MethodPatch, params[0], bytes.NewBuffer([]byte(params[1])))
 	} else if params[2] == "POST" {
-		clilog.Debug.Println("Payload: ", params[1])
+		clilog.Debug.Println("Payload: ", string(RedactSecrets([]byte(params[1]))))
 		req, err = http.NewRequestWithContext(ctx, http.MethodPost, params[0], bytes.NewBuffer([]byte(params[1])))
 	} else {
 		return nil, errors.New("unsupported method")
@@ -194,26 +294,14 @@ func getHttpClient() (client *RateLimitedHTTPClient, err error) {
 		apiRateLimit = noAPIRateLimit
 	}
 
-	if GetProxyURL() != "" {
-		if proxyUrl, err := url.Parse(GetProxyURL()); err != nil {
-			integrationCLIAPIClient := &RateLimitedHTTPClient{
-				client: &http.Client{
-					Transport: &http.Transport{
-						Proxy: http.ProxyURL(proxyUrl),
-					},
-				},
-				Ratelimiter: apiRateLimit,
-			}
-			return integrationCLIAPIClient, err
-		}
+	httpClient, err := newHTTPClient()
+	if err != nil {
 		return nil, err
-	} else {
-		integrationCLIAPIClient := &RateLimitedHTTPClient{
-			client:      http.DefaultClient,
-			Ratelimiter: apiRateLimit,
-		}
-		return integrationCLIAPIClient, nil
 	}
+	return &RateLimitedHTTPClient{
+		client:      httpClient,
+		Ratelimiter: apiRateLimit,
+	}, nil
 }
 
 func handleResponse(resp *http.Response) (respBody []byte, err error) {
@@ -243,6 +331,11 @@ func handleResponse(resp *http.Response) (respBody []byte, err error) {
 	return respBody, PrettyPrint(respBody)
 }
 
+// IsNotFound reports if err is the error returned for a 404 response
+func IsNotFound(err error) bool {
+	return err != nil && strings.HasPrefix(err.Error(), getErrorMessage(http.StatusNotFound))
+}
+
 func getErrorMessage(statusCode int) string {
 	switch statusCode {
 	case 400:
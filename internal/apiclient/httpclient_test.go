@@ -0,0 +1,54 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apiclient
+
+import (
+	"bytes"
+	"log"
+	"testing"
+
+	"internal/clilog"
+)
+
+func TestRedactSecrets(t *testing.T) {
+	body := []byte(`{"authConfig":{"userPassword":{"username":"user","password":{"secretVersion":"projects/p/secrets/s/versions/1"}}},` +
+		`"configVariables":[{"key":"client_secret","stringValue":"value"}]}`)
+	expected := `{"authConfig":{"userPassword":{"password":{"secretVersion":"REDACTED"},"username":"user"}},` +
+		`"configVariables":[{"key":"client_secret","stringValue":"REDACTED"}]}`
+	if redacted := string(RedactSecrets(body)); redacted != expected {
+		t.Fatalf("expected %s, got %s", expected, redacted)
+	}
+
+	body = []byte(`{"passwordDetails":{"secretName":"s","value":"inline"}}`)
+	expected = `{"passwordDetails":{"secretName":"REDACTED","value":"REDACTED"}}`
+	if redacted := string(RedactSecrets(body)); redacted != expected {
+		t.Fatalf("expected %s, got %s", expected, redacted)
+	}
+}
+
+func TestPrintTableNoOutput(t *testing.T) {
+	httpResponse := clilog.HTTPResponse
+	t.Cleanup(func() { clilog.HTTPResponse = httpResponse })
+	for _, noOutput := range []bool{false, true} {
+		NewIntegrationClient(IntegrationClientOptions{PrintOutput: true, NoOutput: noOutput})
+		ClientPrintHttpResponse.Set(true)
+		var out bytes.Buffer
+		clilog.HTTPResponse = log.New(&out, "", 0)
+		PrintTable([]string{"NAME", "COUNT"}, [][]string{{"c1", "1"}})
+		if printed := out.Len() > 0; printed == noOutput {
+			t.Errorf("no output %t: expected the table to be printed %t, got %q", noOutput, !noOutput, out.String())
+		}
+	}
+}
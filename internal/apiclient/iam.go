@@ -94,7 +94,7 @@ func iamServiceAccountExists(iamname string) (code int, err error) {
 	resp, err = client.Do(req)
 	if err != nil {
 		clilog.Error.Println("error connecting: ", err)
-		return resp.StatusCode, err
+		return -1, err
 	}
 
 	if resp != nil {
@@ -117,25 +117,92 @@ func iamServiceAccountExists(iamname string) (code int, err error) {
 	}
 }
 
+// ServiceAccountExists returns true if the service account was found
+func ServiceAccountExists(iamname string) (found bool, err error) {
+	statusCode, err := iamServiceAccountExists(iamname)
+	if err != nil {
+		return false, err
+	}
+	return statusCode == http.StatusOK, nil
+}
+
+// ServiceAccountCanActAs returns true if the caller has iam.serviceAccounts.actAs on the
+// service account, which is needed to create resources that run as it
+func ServiceAccountCanActAs(iamname string) (canActAs bool, err error) {
+	const actAs = "iam.serviceAccounts.actAs"
+	testendpoint := fmt.Sprintf("https://iam.googleapis.com/v1/projects/-/serviceAccounts/%s:testIamPermissions", iamname)
+
+	ClientPrintHttpResponse.Set(false)
+	defer ClientPrintHttpResponse.Set(GetCmdPrintHttpResponseSetting())
+
+	respBody, err := HttpClient(testendpoint, fmt.Sprintf("{\"permissions\":[\"%s\"]}", actAs))
+	if err != nil {
+		return false, err
+	}
+	if DryRun() {
+		return true, nil
+	}
+
+	p := struct {
+		Permissions []string `json:"permissions,omitempty"`
+	}{}
+	if err = json.Unmarshal(respBody, &p); err != nil {
+		return false, err
+	}
+	for _, permission := range p.Permissions {
+		if permission == actAs {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
 // setIAMPermission set permissions for a member
 func setIAMPermission(endpoint string, name string, memberName string, role string, memberType string) (err error) {
+	_, getIamPolicy, err := getUpdatedIAMPolicy(endpoint, name, memberName, role, memberType)
+	if err != nil {
+		return err
+	}
+
+	u, _ := url.Parse(endpoint)
+	u.Path = path.Join(u.Path, name+":setIamPolicy")
+
+	setIamPolicy := setIamPolicy{}
+	setIamPolicy.Policy = getIamPolicy
+
+	setIamPolicyBody, err := json.Marshal(setIamPolicy)
+	if err != nil {
+		clilog.Error.Println(err)
+		return err
+	}
+
+	ClientPrintHttpResponse.Set(false)
+	_, err = HttpClient(u.String(), string(setIamPolicyBody))
+	ClientPrintHttpResponse.Set(GetCmdPrintHttpResponseSetting())
+
+	return err
+}
+
+// getUpdatedIAMPolicy fetches the IAM policy of the resource and returns it as read,
+// along with the policy that grants role to the member
+func getUpdatedIAMPolicy(endpoint string, name string, memberName string, role string,
+	memberType string,
+) (getIamPolicyBody []byte, getIamPolicy iamPolicy, err error) {
 	u, _ := url.Parse(endpoint)
 	u.Path = path.Join(u.Path, name+":getIamPolicy")
 
 	ClientPrintHttpResponse.Set(false)
-	getIamPolicyBody, err := HttpClient(u.String())
+	getIamPolicyBody, err = HttpClient(u.String())
 	ClientPrintHttpResponse.Set(GetCmdPrintHttpResponseSetting())
 	if err != nil {
 		clilog.Error.Println(err)
-		return err
+		return nil, getIamPolicy, err
 	}
 
-	getIamPolicy := iamPolicy{}
-
 	err = json.Unmarshal(getIamPolicyBody, &getIamPolicy)
 	if err != nil {
 		clilog.Error.Println(err)
-		return err
+		return nil, getIamPolicy, err
 	}
 
 	foundRole := false
@@ -155,23 +222,7 @@ func setIAMPermission(endpoint string, name string, memberName string, role stri
 		getIamPolicy.Bindings = append(getIamPolicy.Bindings, binding)
 	}
 
-	u, _ = url.Parse(endpoint)
-	u.Path = path.Join(u.Path, name+":setIamPolicy")
-
-	setIamPolicy := setIamPolicy{}
-	setIamPolicy.Policy = getIamPolicy
-
-	setIamPolicyBody, err := json.Marshal(setIamPolicy)
-	if err != nil {
-		clilog.Error.Println(err)
-		return err
-	}
-
-	ClientPrintHttpResponse.Set(false)
-	_, err = HttpClient(u.String(), string(setIamPolicyBody))
-	ClientPrintHttpResponse.Set(GetCmdPrintHttpResponseSetting())
-
-	return err
+	return getIamPolicyBody, getIamPolicy, nil
 }
 
 // setProjectIAMPermission
@@ -241,7 +292,19 @@ func setProjectIAMPermission(project string, memberName string, role string) (er
 	return nil
 }
 
-// CreateServiceAccount
+// ValidateServiceAccount checks a user managed service account email is of the
+// form {account-id}@{project-id}.iam.gserviceaccount.com
+func ValidateServiceAccount(iamname string) error {
+	rsa := regexp.MustCompile(`^[a-z][a-z0-9-]{4,28}[a-z0-9]@[a-z][a-z0-9-]{4,28}[a-z0-9]\.iam\.gserviceaccount\.com$`)
+	if !rsa.MatchString(iamname) {
+		return fmt.Errorf("invalid service account %q, must be of the format "+
+			"{account-id}@{project-id}.iam.gserviceaccount.com where the account and project ids "+
+			"are 6 to 30 lowercase letters, digits or hyphens", iamname)
+	}
+	return nil
+}
+
+// CreateServiceAccount creates the service account if it does not exist
 func CreateServiceAccount(iamname string) (err error) {
 	var statusCode int
 
@@ -266,6 +329,11 @@ func CreateServiceAccount(iamname string) (err error) {
 		ClientPrintHttpResponse.Set(false)
 		defer ClientPrintHttpResponse.Set(GetCmdPrintHttpResponseSetting())
 		if _, err = HttpClient(createendpoint, payload); err != nil {
+			// the service account was created between the check and the create
+			if strings.HasPrefix(err.Error(), getErrorMessage(http.StatusConflict)) {
+				clilog.Debug.Printf("service account %s already exists\n", iamname)
+				return nil
+			}
 			clilog.Error.Println(err)
 			return err
 		}
@@ -277,8 +345,35 @@ func CreateServiceAccount(iamname string) (err error) {
 
 // SetConnectorIAMPermission set permissions for a member on a connection
 func SetConnectorIAMPermission(name string, memberName string, iamRole string, memberType string) (err error) {
-	var role string
+	role, err := getConnectorRole(iamRole)
+	if err != nil {
+		return err
+	}
+	return setIAMPermission(GetBaseConnectorURL(), name, memberName, role, memberType)
+}
 
+// GetConnectorIAMPermissionUpdate returns the current IAM policy of a connection and the
+// policy SetConnectorIAMPermission would set, without changing it
+func GetConnectorIAMPermissionUpdate(name string, memberName string, iamRole string,
+	memberType string,
+) (currentPolicy []byte, updatedPolicy []byte, err error) {
+	role, err := getConnectorRole(iamRole)
+	if err != nil {
+		return nil, nil, err
+	}
+	currentPolicy, policy, err := getUpdatedIAMPolicy(GetBaseConnectorURL(), name, memberName, role, memberType)
+	if err != nil {
+		return nil, nil, err
+	}
+	if updatedPolicy, err = json.Marshal(policy); err != nil {
+		return nil, nil, err
+	}
+	return currentPolicy, updatedPolicy, nil
+}
+
+// getConnectorRole returns the IAM role for the connector role admin, invoker or viewer,
+// or the custom role in iamRole
+func getConnectorRole(iamRole string) (role string, err error) {
 	switch iamRole {
 	case "admin":
 		role = "roles/connectors.admin"
@@ -290,12 +385,11 @@ func SetConnectorIAMPermission(name string, memberName string, iamRole string, m
 		re := regexp.MustCompile(`projects\/([a-zA-Z0-9_-]+)\/roles\/([a-zA-Z0-9_-]+)`)
 		result := re.FindString(iamRole)
 		if result == "" {
-			return fmt.Errorf("custom role must be of the format projects/{project-id}/roles/{role-name}")
+			return "", fmt.Errorf("custom role must be of the format projects/{project-id}/roles/{role-name}")
 		}
 		role = iamRole
 	}
-
-	return setIAMPermission(GetBaseConnectorURL(), name, memberName, role, memberType)
+	return role, nil
 }
 
 // SetPubSubIAMPermission set permissions for a SA on a topic
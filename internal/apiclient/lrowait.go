@@ -15,21 +15,25 @@
 package apiclient
 
 import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
 	"time"
+
+	"internal/clilog"
 )
 
+// Every calls work every duration until it returns false. The returned channel
+// receives a value once work returned false, and work is not called again
 func Every(duration time.Duration, work func(time.Time) bool) chan bool {
 	ticker := time.NewTicker(duration)
 	stop := make(chan bool, 1)
 
 	go func() {
-		for {
-			select {
-			case time := <-ticker.C:
-				if !work(time) {
-					stop <- true
-				}
-			case <-stop:
+		defer ticker.Stop()
+		for time := range ticker.C {
+			if !work(time) {
+				stop <- true
 				return
 			}
 		}
@@ -37,3 +41,100 @@ func Every(duration time.Duration, work func(time.Time) bool) chan bool {
 
 	return stop
 }
+
+// lro is the subset of a long running operation needed to wait on it
+type lro struct {
+	Name  string `json:"name,omitempty"`
+	Done  bool   `json:"done,omitempty"`
+	Error *struct {
+		Code    int    `json:"code,omitempty"`
+		Message string `json:"message,omitempty"`
+	} `json:"error,omitempty"`
+}
+
+// operationEvent is printed for every poll when the operation output is json
+type operationEvent struct {
+	Operation string `json:"operation"`
+	Done      bool   `json:"done"`
+	Error     string `json:"error,omitempty"`
+	Elapsed   int    `json:"elapsed"` // seconds since the wait started
+}
+
+// printOperationEvent prints the state of the operation as a single JSON line
+func printOperationEvent(operationId string, o lro, err error, start time.Time) {
+	event := operationEvent{
+		Operation: operationId,
+		Done:      o.Done,
+		Elapsed:   int(time.Since(start).Seconds()),
+	}
+	if o.Error != nil {
+		event.Error = o.Error.Message
+	} else if err != nil {
+		event.Error = err.Error()
+	}
+	if eventBody, err := json.Marshal(event); err == nil {
+		clilog.HTTPResponse.Println(string(eventBody))
+	}
+}
+
+// WaitForOperation polls the operation in respBody every interval using getOperation
+// until it is done or the wait timeout passes. It returns the final operation and the
+// operation error, if any
+func WaitForOperation(respBody []byte, interval time.Duration,
+	getOperation func(name string) ([]byte, error),
+) (operationBody []byte, err error) {
+	ClientPrintHttpResponse.Set(false)
+	defer ClientPrintHttpResponse.Set(GetCmdPrintHttpResponseSetting())
+
+	o := lro{}
+	if err = json.Unmarshal(respBody, &o); err != nil {
+		return nil, err
+	}
+
+	operationId := filepath.Base(o.Name)
+	jsonOutput := GetOperationOutput() == "json"
+	start := time.Now()
+	if !jsonOutput {
+		clilog.Info.Printf("Checking connection status for %s in %d seconds\n", operationId, int(interval.Seconds()))
+	}
+
+	stop := Every(interval, func(time.Time) bool {
+		if operationBody, err = getOperation(operationId); err == nil {
+			err = json.Unmarshal(operationBody, &o)
+		}
+
+		if jsonOutput {
+			printOperationEvent(operationId, o, err, start)
+		}
+
+		if err != nil {
+			return false
+		}
+
+		if o.Done && jsonOutput {
+			if o.Error != nil {
+				err = fmt.Errorf("operation %s completed with error: %s", operationId, o.Error.Message)
+			}
+			return false
+		} else if o.Done {
+			if o.Error != nil {
+				clilog.Error.Printf("Connection completed with error: %s\n", o.Error.Message)
+				err = fmt.Errorf("operation %s completed with error: %s", operationId, o.Error.Message)
+			} else {
+				clilog.Info.Println("Connection completed successfully!")
+			}
+			return false
+		} else if timeout := GetWaitTimeout(); timeout > 0 && time.Since(start) >= timeout {
+			err = fmt.Errorf("timed out after %s waiting for operation %s", timeout, operationId)
+			return false
+		} else {
+			if !jsonOutput {
+				clilog.Info.Printf("Connection status is: %t. Waiting %d seconds.\n", o.Done, int(interval.Seconds()))
+			}
+			return true
+		}
+	})
+
+	<-stop
+	return operationBody, err
+}
@@ -0,0 +1,128 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apiclient
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"log"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"internal/clilog"
+)
+
+// fakeOperation returns a getOperation that reports the operation done, with message
+// as its error if set, on poll doneAfter and counts the polls. A zero doneAfter never
+// completes the operation
+func fakeOperation(doneAfter int32, message string) (getOperation func(string) ([]byte, error), polls *int32) {
+	polls = new(int32)
+	getOperation = func(name string) ([]byte, error) {
+		poll := atomic.AddInt32(polls, 1)
+		if doneAfter == 0 || poll < doneAfter {
+			return []byte(`{"name":"operations/` + name + `"}`), nil
+		}
+		if message != "" {
+			return []byte(`{"name":"operations/` + name + `","done":true,"error":{"code":3,"message":"` +
+				message + `"}}`), nil
+		}
+		return []byte(`{"name":"operations/` + name + `","done":true}`), nil
+	}
+	return getOperation, polls
+}
+
+func newWaitTestClient(t *testing.T, timeout time.Duration) {
+	t.Helper()
+	NewIntegrationClient(IntegrationClientOptions{NoOutput: true})
+	if err := SetWaitTiming(time.Millisecond, timeout); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		_ = SetWaitTiming(0, 0)
+		_ = SetOperationOutput("")
+	})
+}
+
+func TestWaitForOperation(t *testing.T) {
+	tests := []struct {
+		name      string
+		doneAfter int32
+		message   string
+		timeout   time.Duration
+		err       string
+	}{
+		{"done", 2, "", 0, ""},
+		{"done with error", 2, "bad config", 0, "operation op1 completed with error: bad config"},
+		{"timeout", 0, "", 5 * time.Millisecond, "timed out after 5ms waiting for operation op1"},
+		{"get error", 0, "", 0, "unavailable"},
+	}
+	for _, test := range tests {
+		newWaitTestClient(t, test.timeout)
+		getOperation, polls := fakeOperation(test.doneAfter, test.message)
+		if test.name == "get error" {
+			getOperation = func(string) ([]byte, error) {
+				atomic.AddInt32(polls, 1)
+				return nil, errors.New("unavailable")
+			}
+		}
+
+		_, err := WaitForOperation([]byte(`{"name":"projects/p/operations/op1"}`), time.Millisecond, getOperation)
+		if (err == nil && test.err != "") || (err != nil && err.Error() != test.err) {
+			t.Errorf("%s: expected error %q, got %v", test.name, test.err, err)
+		}
+
+		// the operation is not polled again once the wait returned
+		count := atomic.LoadInt32(polls)
+		time.Sleep(10 * time.Millisecond)
+		if after := atomic.LoadInt32(polls); after != count {
+			t.Errorf("%s: expected no polls after the wait returned, got %d more", test.name, after-count)
+		}
+	}
+}
+
+func TestWaitForOperationJSONOutput(t *testing.T) {
+	newWaitTestClient(t, 0)
+	if err := SetOperationOutput("json"); err != nil {
+		t.Fatal(err)
+	}
+	httpResponse := clilog.HTTPResponse
+	t.Cleanup(func() { clilog.HTTPResponse = httpResponse })
+	var out bytes.Buffer
+	clilog.HTTPResponse = log.New(&out, "", 0)
+
+	getOperation, _ := fakeOperation(3, "")
+	if _, err := WaitForOperation([]byte(`{"name":"projects/p/operations/op1"}`), time.Millisecond,
+		getOperation); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(10 * time.Millisecond)
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("expected one event per poll, got %q", lines)
+	}
+	for index, line := range lines {
+		event := operationEvent{}
+		if err := json.Unmarshal([]byte(line), &event); err != nil {
+			t.Fatal(err)
+		}
+		if event.Operation != "op1" || event.Done != (index == 2) {
+			t.Errorf("unexpected event %d: %s", index, line)
+		}
+	}
+}
@@ -19,6 +19,7 @@ import (
 	"os"
 	"strings"
 	"sync"
+	"time"
 
 	"internal/clilog"
 )
@@ -46,28 +47,49 @@ const (
 	connectorEndpointAttachAutoPushURL = "https://autopush-connectors.sandbox.googleapis.com/v1/projects/%s/locations/%s/endpointAttachments"
 	connectorEndpointAttachStagingURL  = "https://staging-connectors.sandbox.googleapis.com/v1/projects/%s/locations/%s/endpointAttachments"
 
+	connectorProvidersURL         = "https://connectors.googleapis.com/v1/projects/%s/locations/global/providers"
+	connectorProvidersAutoPushURL = "https://autopush-connectors.sandbox.googleapis.com/v1/projects/%s/locations/global/providers"
+	connectorProvidersStagingURL  = "https://staging-connectors.sandbox.googleapis.com/v1/projects/%s/locations/global/providers"
+
+	connectorLocationsURL         = "https://connectors.googleapis.com/v1/projects/%s/locations"
+	connectorLocationsAutoPushURL = "https://autopush-connectors.sandbox.googleapis.com/v1/projects/%s/locations"
+	connectorLocationsStagingURL  = "https://staging-connectors.sandbox.googleapis.com/v1/projects/%s/locations"
+
 	connectorZonesURL         = "https://connectors.googleapis.com/v1/projects/%s/locations/global/managedZones"
 	connectorZonesAutoPushURL = "https://autopush-connectors.sandbox.googleapis.com/v1/projects/%s/locations/global/managedZones"
 	connectorZonesStagingURL  = "https://staging-connectors.sandbox.googleapis.com/v1/projects/%s/locations/global/managedZones"
+
+	connectorRuntimeURL         = "https://connectors.googleapis.com/v2/projects/%s/locations/%s/connections"
+	connectorRuntimeAutoPushURL = "https://autopush-connectors.sandbox.googleapis.com/v2/projects/%s/locations/%s/connections"
+	connectorRuntimeStagingURL  = "https://staging-connectors.sandbox.googleapis.com/v2/projects/%s/locations/%s/connections"
 )
 
 // IntegrationClientOptions is the base struct to hold all command arguments
 type IntegrationClientOptions struct {
-	Api                API    // integrationcli can switch between prod, autopush and staging
-	Region             string // Integration region
-	Token              string // Google OAuth access token
-	ServiceAccount     string // Google service account json
-	ProjectID          string // GCP Project ID
-	DebugLog           bool   // Enable debug logs
-	TokenCheck         bool   // skip checking access token expiry
-	SkipCache          bool   // skip writing access token to file
-	PrintOutput        bool   // prints output from http calls
-	NoOutput           bool   // Disable all statements to stdout
-	SuppressWarnings   bool   // Disable printing of warnings to stdout
-	ProxyUrl           string // use a proxy url
-	MetadataToken      bool   // use metadata outh2 token
-	ExportToFile       string // determine of the contents should be written to file
-	ConflictsAreErrors bool   // treat statusconflict as an error
+	Api                API           // integrationcli can switch between prod, autopush and staging
+	Region             string        // Integration region
+	Token              string        // Google OAuth access token
+	ServiceAccount     string        // Google service account json
+	ProjectID          string        // GCP Project ID
+	DebugLog           bool          // Enable debug logs
+	TokenCheck         bool          // skip checking access token expiry
+	SkipCache          bool          // skip writing access token to file
+	PrintOutput        bool          // prints output from http calls
+	NoOutput           bool          // Disable all statements to stdout
+	SuppressWarnings   bool          // Disable printing of warnings to stdout
+	ProxyUrl           string        // use a proxy url
+	MetadataToken      bool          // use metadata outh2 token
+	ExportToFile       string        // determine of the contents should be written to file
+	ConflictsAreErrors bool          // treat statusconflict as an error
+	ReplayDir          string        // read canned responses from this folder instead of the network
+	RecordDir          string        // save responses received from the network to this folder
+	OperationOutput    string        // format of the progress printed while waiting on operations
+	CompactOutput      bool          // print json responses without indentation
+	NoResponseCache    bool          // disable caching of provider and version lookups
+	WaitInterval       time.Duration // interval between polls of an operation; zero uses the default
+	WaitTimeout        time.Duration // give up waiting on an operation after this long; zero waits forever
+	ListPageSize       int           // page size of the lists that fetch every page; zero uses the maximum
+	TelemetrySink      TelemetrySink // receives an event for each change to a connection; nil is a no-op
 }
 
 var options *IntegrationClientOptions
@@ -350,6 +372,23 @@ func GetBaseCustomConnectorURL() (connectorUrl string) {
 	}
 }
 
+// GetBaseConnectorLocationsURL returns the url to list the locations of the connectors API
+func GetBaseConnectorLocationsURL() (connectorUrl string) {
+	if options.ProjectID == "" {
+		return ""
+	}
+	switch options.Api {
+	case PROD:
+		return fmt.Sprintf(connectorLocationsURL, GetProjectID())
+	case STAGING:
+		return fmt.Sprintf(connectorLocationsStagingURL, GetProjectID())
+	case AUTOPUSH:
+		return fmt.Sprintf(connectorLocationsAutoPushURL, GetProjectID())
+	default:
+		return fmt.Sprintf(connectorLocationsURL, GetProjectID())
+	}
+}
+
 // GetBaseConnectorURLWithRegion
 func GetBaseConnectorURLWithRegion(region string) (connectorUrl string) {
 	if options.ProjectID == "" || region == "" {
@@ -367,6 +406,24 @@ func GetBaseConnectorURLWithRegion(region string) (connectorUrl string) {
 	}
 }
 
+// GetBaseConnectorURLWithProject returns the connections url of the project and region,
+// without changing the project of the client
+func GetBaseConnectorURLWithProject(projectID string, region string) (connectorUrl string) {
+	if projectID == "" || region == "" {
+		return ""
+	}
+	switch options.Api {
+	case PROD:
+		return fmt.Sprintf(connectorBaseURL, projectID, region)
+	case STAGING:
+		return fmt.Sprintf(connectorStagingBaseURL, projectID, region)
+	case AUTOPUSH:
+		return fmt.Sprintf(connectorAutoPushBaseURL, projectID, region)
+	default:
+		return fmt.Sprintf(connectorBaseURL, projectID, region)
+	}
+}
+
 // GetBaseConnectorOperationsURL
 func GetBaseConnectorOperationsrURL() (connectorUrl string) {
 	if options.ProjectID == "" || options.Region == "" {
@@ -384,6 +441,23 @@ func GetBaseConnectorOperationsrURL() (connectorUrl string) {
 	}
 }
 
+// GetBaseConnectorRuntimeURL returns the connections URL of the v2 runtime API
+func GetBaseConnectorRuntimeURL() (connectorUrl string) {
+	if options.ProjectID == "" || options.Region == "" {
+		return ""
+	}
+	switch options.Api {
+	case PROD:
+		return fmt.Sprintf(connectorRuntimeURL, GetProjectID(), GetRegion())
+	case STAGING:
+		return fmt.Sprintf(connectorRuntimeStagingURL, GetProjectID(), GetRegion())
+	case AUTOPUSH:
+		return fmt.Sprintf(connectorRuntimeAutoPushURL, GetProjectID(), GetRegion())
+	default:
+		return fmt.Sprintf(connectorRuntimeURL, GetProjectID(), GetRegion())
+	}
+}
+
 // GetBaseConnectorEndpointAttachURL
 func GetBaseConnectorEndpointAttachURL() (connectorUrl string) {
 	if options.ProjectID == "" || options.Region == "" {
@@ -418,6 +492,23 @@ func GetBaseConnectorZonesURL() (connectorUrl string) {
 	}
 }
 
+// GetBaseConnectorProvidersURL
+func GetBaseConnectorProvidersURL() (connectorUrl string) {
+	if options.ProjectID == "" {
+		return ""
+	}
+	switch options.Api {
+	case PROD:
+		return fmt.Sprintf(connectorProvidersURL, GetProjectID())
+	case STAGING:
+		return fmt.Sprintf(connectorProvidersStagingURL, GetProjectID())
+	case AUTOPUSH:
+		return fmt.Sprintf(connectorProvidersAutoPushURL, GetProjectID())
+	default:
+		return fmt.Sprintf(connectorProvidersURL, GetProjectID())
+	}
+}
+
 // SetExportToFile
 func SetExportToFile(exportToFile string) {
 	options.ExportToFile = exportToFile
@@ -462,6 +553,69 @@ func GetConflictsAsErrors() bool {
 	return options.ConflictsAreErrors
 }
 
+// SetOperationOutput sets the format of the progress printed while waiting on
+// operations. Must be empty for log lines or json for one JSON object per poll
+func SetOperationOutput(output string) error {
+	if output != "" && output != "json" {
+		return fmt.Errorf("invalid operation-output %s, must be json", output)
+	}
+	options.OperationOutput = output
+	return nil
+}
+
+// GetOperationOutput
+func GetOperationOutput() string {
+	return options.OperationOutput
+}
+
+// SetWaitTiming sets how often operations are polled and how long to wait for them.
+// A zero interval uses the default and a zero timeout waits until the operation is done
+func SetWaitTiming(interval time.Duration, timeout time.Duration) error {
+	if interval < 0 {
+		return fmt.Errorf("wait interval must be greater than 0")
+	}
+	if timeout < 0 || (timeout > 0 && timeout < interval) {
+		return fmt.Errorf("wait timeout must be greater than or equal to the wait interval")
+	}
+	options.WaitInterval = interval
+	options.WaitTimeout = timeout
+	return nil
+}
+
+// GetWaitInterval
+func GetWaitInterval() time.Duration {
+	return options.WaitInterval
+}
+
+// GetWaitTimeout
+func GetWaitTimeout() time.Duration {
+	return options.WaitTimeout
+}
+
+// SetListPageSize sets the page size of the lists that fetch every page
+func SetListPageSize(pageSize int) error {
+	if pageSize < 1 || pageSize > 1000 {
+		return fmt.Errorf("page size must be between 1 and 1000")
+	}
+	options.ListPageSize = pageSize
+	return nil
+}
+
+// GetListPageSize
+func GetListPageSize() int {
+	return options.ListPageSize
+}
+
+// SetCompactOutput prints json responses minified when set
+func SetCompactOutput(b bool) {
+	options.CompactOutput = b
+}
+
+// GetCompactOutput
+func GetCompactOutput() bool {
+	return options.CompactOutput
+}
+
 // SetRate
 func SetRate(r Rate) {
 	apiRate = r
@@ -0,0 +1,136 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apiclient
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"internal/clilog"
+)
+
+// recordedResponse is the on disk format of a recorded http response
+type recordedResponse struct {
+	Method     string `json:"method,omitempty"`
+	URL        string `json:"url,omitempty"`
+	StatusCode int    `json:"statusCode,omitempty"`
+	Body       string `json:"body,omitempty"`
+}
+
+// SetReplayDir serves responses from recordings in dir instead of the network
+func SetReplayDir(dir string) {
+	options.ReplayDir = dir
+}
+
+// GetReplayDir
+func GetReplayDir() string {
+	return options.ReplayDir
+}
+
+// SetRecordDir saves every response received from the network to dir
+func SetRecordDir(dir string) {
+	options.RecordDir = dir
+}
+
+// GetRecordDir
+func GetRecordDir() string {
+	return options.RecordDir
+}
+
+// GetRecordingFileName returns the file name of the recording of a request to rawURL,
+// so tests can write the recordings the replay dir serves
+func GetRecordingFileName(method string, rawURL string) string {
+	u, _ := url.Parse(rawURL)
+	return getRecordingFileName(&http.Request{Method: method, URL: u})
+}
+
+// getRecordingFileName returns the file name used for a request, keyed by method and path.
+// A hash of the query string is appended so paged or filtered calls don't collide
+func getRecordingFileName(req *http.Request) string {
+	name := strings.ToUpper(req.Method) + "_" + strings.ReplaceAll(strings.Trim(req.URL.Path, "/"), "/", "_")
+	if req.URL.RawQuery != "" {
+		sum := sha256.Sum256([]byte(req.URL.Query().Encode()))
+		name = name + "_" + hex.EncodeToString(sum[:])[:8]
+	}
+	return name + ".json"
+}
+
+// replayResponse reads the recorded response for req from the replay dir
+func replayResponse(req *http.Request) (*http.Response, error) {
+	fileName := filepath.Join(GetReplayDir(), getRecordingFileName(req))
+	clilog.Debug.Println("Replaying response from: ", fileName)
+
+	content, err := os.ReadFile(fileName)
+	if err != nil {
+		return nil, fmt.Errorf("no recorded response for %s %s: %w", req.Method, req.URL.String(), err)
+	}
+
+	r := recordedResponse{}
+	if err = json.Unmarshal(content, &r); err != nil {
+		return nil, fmt.Errorf("failed to unmarshall recording %s: %w", fileName, err)
+	}
+
+	if r.StatusCode == 0 {
+		r.StatusCode = http.StatusOK
+	}
+
+	return &http.Response{
+		StatusCode: r.StatusCode,
+		Body:       io.NopCloser(strings.NewReader(r.Body)),
+		Request:    req,
+	}, nil
+}
+
+// recordResponse writes resp to the record dir and returns a response whose body can still be read
+func recordResponse(req *http.Request, resp *http.Response) (*http.Response, error) {
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	r := recordedResponse{
+		Method:     req.Method,
+		URL:        req.URL.String(),
+		StatusCode: resp.StatusCode,
+		Body:       string(body),
+	}
+
+	content, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	if err = os.MkdirAll(GetRecordDir(), 0o755); err != nil {
+		return nil, err
+	}
+
+	fileName := filepath.Join(GetRecordDir(), getRecordingFileName(req))
+	clilog.Debug.Println("Recording response to: ", fileName)
+	if err = os.WriteFile(fileName, content, 0o644); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
@@ -0,0 +1,61 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apiclient
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRecordAndReplay(t *testing.T) {
+	NewIntegrationClient(IntegrationClientOptions{
+		Token:    "token",
+		NoOutput: true,
+	})
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"name":"test"}`))
+	}))
+
+	dir := t.TempDir()
+	SetRecordDir(dir)
+	recorded, err := HttpClient(server.URL + "/v1/projects/p/connections?pageSize=10")
+	SetRecordDir("")
+	server.Close()
+	if err != nil {
+		t.Fatalf("failed to record response: %v", err)
+	}
+	if _, err = os.Stat(filepath.Join(dir,
+		GetRecordingFileName("GET", server.URL+"/v1/projects/p/connections?pageSize=10"))); err != nil {
+		t.Errorf("expected the recording to be named by GetRecordingFileName, got %v", err)
+	}
+
+	SetReplayDir(dir)
+	defer SetReplayDir("")
+	replayed, err := HttpClient(server.URL + "/v1/projects/p/connections?pageSize=10")
+	if err != nil {
+		t.Fatalf("failed to replay response: %v", err)
+	}
+	if string(replayed) != string(recorded) {
+		t.Fatalf("expected %s, got %s", recorded, replayed)
+	}
+
+	if _, err = HttpClient(server.URL + "/v1/projects/p/connections?pageSize=20"); err == nil {
+		t.Fatalf("expected an error for a request without a recording")
+	}
+}
@@ -0,0 +1,127 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apiclient
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+
+	"internal/clilog"
+)
+
+// TelemetryEvent describes an operation that changed a connection
+type TelemetryEvent struct {
+	Operation  string `json:"operation"`
+	Name       string `json:"name"`
+	Connector  string `json:"connector,omitempty"`
+	DurationMs int64  `json:"durationMs"`
+	Result     string `json:"result"`
+	Error      string `json:"error,omitempty"`
+}
+
+// TelemetrySink receives an event at the end of each create, delete or patch of a connection
+type TelemetrySink func(TelemetryEvent)
+
+// telemetryTimeout bounds the time spent posting an event to a webhook
+const telemetryTimeout = 10 * time.Second
+
+// SetTelemetrySink sets the sink of the connection telemetry events; nil disables them
+func SetTelemetrySink(sink TelemetrySink) {
+	options.TelemetrySink = sink
+}
+
+// GetTelemetrySink
+func GetTelemetrySink() TelemetrySink {
+	return options.TelemetrySink
+}
+
+// NewTelemetrySink returns the sink for the telemetry flag: stdout prints each event
+// as a JSON line and an http or https url receives each event as a JSON POST
+func NewTelemetrySink(sink string) (TelemetrySink, error) {
+	if sink == "" {
+		return nil, nil
+	}
+	if sink == "stdout" {
+		return StdoutTelemetrySink, nil
+	}
+	u, err := url.Parse(sink)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("telemetry sink must be stdout or an http or https url")
+	}
+	return WebhookTelemetrySink(sink), nil
+}
+
+// StdoutTelemetrySink prints the event as a JSON line
+func StdoutTelemetrySink(event TelemetryEvent) {
+	b, err := json.Marshal(event)
+	if err != nil {
+		clilog.Warning.Println(err)
+		return
+	}
+	fmt.Fprintln(os.Stdout, string(b))
+}
+
+// WebhookTelemetrySink returns a sink that posts each event as JSON to webhookURL. The
+// access token is not sent, and a failed post is logged without failing the operation
+func WebhookTelemetrySink(webhookURL string) TelemetrySink {
+	return func(event TelemetryEvent) {
+		b, err := json.Marshal(event)
+		if err != nil {
+			clilog.Warning.Println(err)
+			return
+		}
+		proxy, err := getProxy()
+		if err != nil {
+			clilog.Warning.Println(err)
+			return
+		}
+		client := &http.Client{Timeout: telemetryTimeout, Transport: &http.Transport{Proxy: proxy}}
+		resp, err := client.Post(webhookURL, "application/json", bytes.NewReader(b))
+		if err != nil {
+			clilog.Warning.Printf("unable to send telemetry event: %v\n", err)
+			return
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode >= http.StatusBadRequest {
+			clilog.Warning.Printf("telemetry webhook returned %s\n", resp.Status)
+		}
+	}
+}
+
+// EmitTelemetry sends the result of an operation on a connection, started at start, to
+// the telemetry sink. It does nothing when no sink is set
+func EmitTelemetry(operation string, name string, connector string, start time.Time, err error) {
+	sink := GetTelemetrySink()
+	if sink == nil {
+		return
+	}
+	event := TelemetryEvent{
+		Operation:  operation,
+		Name:       name,
+		Connector:  connector,
+		DurationMs: time.Since(start).Milliseconds(),
+		Result:     "success",
+	}
+	if err != nil {
+		event.Result = "error"
+		event.Error = err.Error()
+	}
+	sink(event)
+}
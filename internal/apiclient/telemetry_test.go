@@ -0,0 +1,81 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apiclient
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestNewTelemetrySink(t *testing.T) {
+	for _, sink := range []string{"", "stdout", "http://localhost:8080/events", "https://example.com/hook"} {
+		if _, err := NewTelemetrySink(sink); err != nil {
+			t.Errorf("NewTelemetrySink(%q) returned %v", sink, err)
+		}
+	}
+	for _, sink := range []string{"stderr", "ftp://example.com", "https://"} {
+		if _, err := NewTelemetrySink(sink); err == nil {
+			t.Errorf("NewTelemetrySink(%q) expected an error", sink)
+		}
+	}
+}
+
+func TestEmitTelemetry(t *testing.T) {
+	NewIntegrationClient(IntegrationClientOptions{Token: "token", NoOutput: true})
+	defer SetTelemetrySink(nil)
+
+	// no sink is a no-op
+	EmitTelemetry("create", "c1", "pubsub", time.Now(), nil)
+
+	events := []TelemetryEvent{}
+	SetTelemetrySink(func(e TelemetryEvent) { events = append(events, e) })
+	EmitTelemetry("create", "c1", "pubsub", time.Now(), nil)
+	EmitTelemetry("delete", "c2", "", time.Now(), errors.New("Not Found"))
+
+	if len(events) != 2 {
+		t.Fatalf("expected 2 events, got %v", events)
+	}
+	if e := events[0]; e.Operation != "create" || e.Name != "c1" || e.Connector != "pubsub" ||
+		e.Result != "success" || e.Error != "" {
+		t.Errorf("unexpected event %+v", e)
+	}
+	if e := events[1]; e.Operation != "delete" || e.Result != "error" || e.Error != "Not Found" {
+		t.Errorf("unexpected event %+v", e)
+	}
+}
+
+func TestWebhookTelemetrySink(t *testing.T) {
+	NewIntegrationClient(IntegrationClientOptions{Token: "token", NoOutput: true})
+
+	received := TelemetryEvent{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "" {
+			t.Errorf("expected no access token to be sent to the webhook")
+		}
+		if err := json.NewDecoder(r.Body).Decode(&received); err != nil {
+			t.Error(err)
+		}
+	}))
+	defer server.Close()
+
+	WebhookTelemetrySink(server.URL)(TelemetryEvent{Operation: "patch", Name: "c1", Result: "success"})
+	if received.Operation != "patch" || received.Name != "c1" || received.Result != "success" {
+		t.Errorf("unexpected event %+v", received)
+	}
+}
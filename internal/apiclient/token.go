@@ -34,6 +34,7 @@ import (
 
 	"github.com/lestrrat-go/jwx/v2/jwa"
 	"github.com/lestrrat-go/jwx/v2/jwt"
+	"golang.org/x/oauth2"
 	"golang.org/x/oauth2/google"
 )
 
@@ -117,7 +118,10 @@ func generateAccessToken(privateKey string) (string, error) {
 	form.Add("grant_type", grantType)
 	form.Add("assertion", token)
 
-	client := &http.Client{}
+	client, err := newHTTPClient()
+	if err != nil {
+		return "", err
+	}
 	req, err := http.NewRequest("POST", tokenUri, strings.NewReader(form.Encode()))
 	if err != nil {
 		clilog.Error.Println("error in client: ", err)
@@ -195,7 +199,11 @@ func checkAccessToken() bool {
 	q.Set("access_token", GetIntegrationToken())
 	u.RawQuery = q.Encode()
 
-	client := &http.Client{}
+	client, err := newHTTPClient()
+	if err != nil {
+		clilog.Error.Println("error in client:", err)
+		return false
+	}
 
 	clilog.Debug.Println("Connecting to : ", u.String())
 	req, err := http.NewRequest("GET", u.String(), nil)
@@ -264,7 +272,12 @@ func SetAccessToken() error {
 
 // GetDefaultAccessToken
 func GetDefaultAccessToken() (err error) {
-	ctx := context.Background()
+	client, err := newHTTPClient()
+	if err != nil {
+		return err
+	}
+	// fetch the token through the same proxy as the API calls
+	ctx := context.WithValue(context.Background(), oauth2.HTTPClient, client)
 	tokenSource, err := google.DefaultTokenSource(ctx, "https://www.googleapis.com/auth/cloud-platform")
 	if err != nil {
 		return err
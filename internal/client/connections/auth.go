@@ -0,0 +1,93 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package connections
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"internal/apiclient"
+	"internal/clilog"
+)
+
+// authField describes a field of an auth type in the connection JSON
+type authField struct {
+	Path     string // json path under authConfig
+	Required bool
+	Secret   bool // set through a secretDetails block, with secretName and reference or value
+}
+
+// authTypeFields lists the fields of each supported auth type, following the authConfig
+// struct definitions. Secret fields take the secretDetails form the toolkit resolves on
+// create, the resulting secretVersion fields are not listed
+var authTypeFields = map[string][]authField{
+	"USER_PASSWORD": {
+		{Path: "userPassword.username", Required: true},
+		{Path: "userPassword.passwordDetails", Required: true, Secret: true},
+	},
+	"OAUTH2_JWT_BEARER": {
+		{Path: "oauth2JwtBearer.clientKeyDetails", Required: true, Secret: true},
+		{Path: "oauth2JwtBearer.jwtClaims.issuer", Required: true},
+		{Path: "oauth2JwtBearer.jwtClaims.subject", Required: true},
+		{Path: "oauth2JwtBearer.jwtClaims.audience", Required: true},
+	},
+	"OAUTH2_CLIENT_CREDENTIALS": {
+		{Path: "oauth2ClientCredentials.clientId", Required: true},
+		{Path: "oauth2ClientCredentials.clientSecretDetails", Required: true, Secret: true},
+	},
+	"SSH_PUBLIC_KEY": {
+		{Path: "sshPublicKey.username", Required: true},
+		{Path: "sshPublicKey.sshClientCertDetails", Required: true, Secret: true},
+		{Path: "sshPublicKey.certType"},
+		{Path: "sshPublicKey.passwordDetails", Secret: true},
+		{Path: "sshPublicKey.sslClientCertPassDetails", Secret: true},
+	},
+	// the auth code flow block is not modelled by authConfig yet
+	"OAUTH2_AUTH_CODE_FLOW": {},
+}
+
+// authTypes is the order the auth types are described in
+var authTypes = []string{
+	"USER_PASSWORD", "OAUTH2_JWT_BEARER", "OAUTH2_CLIENT_CREDENTIALS",
+	"SSH_PUBLIC_KEY", "OAUTH2_AUTH_CODE_FLOW",
+}
+
+// DescribeAuth prints the fields the auth type needs in the connection authConfig
+// and which of them are secrets. All supported auth types are described when authType is empty
+func DescribeAuth(authType string) (err error) {
+	describe := authTypes
+	if authType != "" {
+		if _, ok := authTypeFields[authType]; !ok {
+			return fmt.Errorf("auth type %s is not supported, supported types are: %s",
+				authType, strings.Join(authTypes, ", "))
+		}
+		describe = []string{authType}
+	}
+
+	rows := [][]string{}
+	for _, t := range describe {
+		if len(authTypeFields[t]) == 0 {
+			clilog.Warning.Printf("The fields for %s are not modelled, see the connector documentation\n", t)
+			continue
+		}
+		for _, f := range authTypeFields[t] {
+			rows = append(rows, []string{t, "authConfig." + f.Path,
+				strconv.FormatBool(f.Required), strconv.FormatBool(f.Secret)})
+		}
+	}
+	apiclient.PrintTable([]string{"AUTH TYPE", "FIELD", "REQUIRED", "SECRET"}, rows)
+	return nil
+}
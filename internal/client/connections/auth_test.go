@@ -0,0 +1,57 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package connections
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// TestAuthTypeFields checks every described field resolves to a json field of authConfig
+func TestAuthTypeFields(t *testing.T) {
+	for _, authType := range authTypes {
+		fields, ok := authTypeFields[authType]
+		if !ok {
+			t.Errorf("auth type %s has no fields entry", authType)
+		}
+		for _, f := range fields {
+			typ := reflect.TypeOf(authConfig{})
+			for _, name := range strings.Split(f.Path, ".") {
+				field, found := jsonField(typ, name)
+				if !found {
+					t.Fatalf("%s: %s is not a field of authConfig", authType, f.Path)
+				}
+				typ = field.Type
+				if typ.Kind() == reflect.Pointer {
+					typ = typ.Elem()
+				}
+			}
+			if isSecret := typ == reflect.TypeOf(secretDetails{}); isSecret != f.Secret {
+				t.Errorf("%s: %s secret is %t, want %t", authType, f.Path, f.Secret, isSecret)
+			}
+		}
+	}
+}
+
+func jsonField(typ reflect.Type, name string) (reflect.StructField, bool) {
+	for i := 0; i < typ.NumField(); i++ {
+		tag, _, _ := strings.Cut(typ.Field(i).Tag.Get("json"), ",")
+		if tag == name {
+			return typ.Field(i), true
+		}
+	}
+	return reflect.StructField{}, false
+}
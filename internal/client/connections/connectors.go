@@ -18,12 +18,18 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"net"
 	"net/url"
 	"os"
 	"path"
 	"path/filepath"
+	"reflect"
+	"regexp"
+	"slices"
+	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"internal/apiclient"
@@ -35,42 +41,148 @@ import (
 
 const maxPageSize = 1000
 
+// getListPageSize returns the page size to fetch every page of a list with
+func getListPageSize() int {
+	if pageSize := apiclient.GetListPageSize(); pageSize != 0 {
+		return pageSize
+	}
+	return maxPageSize
+}
+
+// defaultsFileName is the file in an import folder whose fields are applied to every connection
+const defaultsFileName = "_defaults.json"
+
+// manifestFileName is the file Export writes with the hash of every exported connection
+const manifestFileName = "manifest.json"
+
+// ConnectionView is the view of a connection returned by Get
+type ConnectionView string
+
+const (
+	// BasicView omits the runtime configs of the connection
+	BasicView ConnectionView = "BASIC"
+	// FullView includes the runtime configs of the connection
+	FullView ConnectionView = "FULL"
+)
+
+// ValidateView returns an error if view is not empty or one of the supported views
+func ValidateView(view string) error {
+	switch ConnectionView(view) {
+	case "", BasicView, FullView:
+		return nil
+	}
+	return fmt.Errorf("view must be %s or %s, found %s", BasicView, FullView, view)
+}
+
+// orderByFields are the connection fields the list API can order by
+var orderByFields = []string{"name", "createTime", "updateTime", "create_time", "update_time"}
+
+// ValidateOrderBy returns an error if orderBy is not a comma separated list of
+// supported fields, each optionally followed by asc or desc
+func ValidateOrderBy(orderBy string) error {
+	if orderBy == "" {
+		return nil
+	}
+	for _, term := range strings.Split(orderBy, ",") {
+		parts := strings.Fields(term)
+		if len(parts) == 0 || len(parts) > 2 || !slices.Contains(orderByFields, parts[0]) ||
+			(len(parts) == 2 && parts[1] != "asc" && parts[1] != "desc") {
+			return fmt.Errorf("orderBy must be a comma separated list of %s, each optionally "+
+				"followed by asc or desc, found %s", strings.Join(orderByFields, ", "), orderBy)
+		}
+	}
+	return nil
+}
+
+// validConnectionStates are the states of a connection that list can filter by
+var validConnectionStates = []string{
+	"STATE_UNSPECIFIED", "CREATING", "ACTIVE", "INACTIVE", "DELETING", "UPDATING",
+	"ERROR", "AUTHORIZATION_REQUIRED",
+}
+
+// ValidateState returns an error if state is not empty or a connection state
+func ValidateState(state string) error {
+	if state == "" || slices.Contains(validConnectionStates, strings.ToUpper(state)) {
+		return nil
+	}
+	return fmt.Errorf("state must be one of %s, found %s", strings.Join(validConnectionStates, ", "), state)
+}
+
+// filterConnectionsByState returns the connections in state. A connection without a
+// status is in STATE_UNSPECIFIED
+func filterConnectionsByState(lconnections []connection, state string) (filtered []connection) {
+	for _, c := range lconnections {
+		cstate := "STATE_UNSPECIFIED"
+		if c.Status != nil && c.Status.State != "" {
+			cstate = c.Status.State
+		}
+		if strings.EqualFold(cstate, state) {
+			filtered = append(filtered, c)
+		}
+	}
+	return filtered
+}
+
+// connectionNameRegex matches valid connection ids
+var connectionNameRegex = regexp.MustCompile(`^[a-z]([a-z0-9-]{0,61}[a-z0-9])?$`)
+
+// serviceAccountNotFoundRegex matches the create errors returned while a new
+// service account is still propagating
+var serviceAccountNotFoundRegex = regexp.MustCompile(`(?i)service ?account[^"]*(not found|does not exist)`)
+
+// retries and delay for creates that fail on a service account that was just created
+const (
+	serviceAccountRetries    = 5
+	serviceAccountRetryDelay = 5 * time.Second
+)
+
 type listconnections struct {
 	Connections   []connection `json:"connections,omitempty"`
 	NextPageToken string       `json:"nextPageToken,omitempty"`
 }
 
 type connection struct {
-	Name                   *string             `json:"name,omitempty"`
-	Description            string              `json:"description,omitempty"`
-	ConnectorVersion       *string             `json:"connectorVersion,omitempty"`
-	ConnectorDetails       *connectorDetails   `json:"connectorDetails,omitempty"`
-	ConfigVariables        []configVar         `json:"configVariables,omitempty"`
-	AuthConfig             authConfig          `json:"authConfig,omitempty"`
-	DestinationConfig      []destinationConfig `json:"destinationConfigs,omitempty"`
-	Suspended              bool                `json:"suspended,omitempty"`
-	LogConfig              *logConfig          `json:"logConfig,omitempty"`
-	SslConfig              *sslConfig          `json:"sslConfig,omitempty"`
-	EventingEnablementType *string             `json:"eventingEnablementType,omitempty"`
-	EventingConfig         *eventingConfig     `json:"eventingConfig,omitempty"`
+	Name                        *string                      `json:"name,omitempty"`
+	Description                 string                       `json:"description,omitempty"`
+	Labels                      map[string]string            `json:"labels,omitempty"`
+	ConnectorVersion            *string                      `json:"connectorVersion,omitempty"`
+	ConnectorDetails            *connectorDetails            `json:"connectorDetails,omitempty"`
+	ConfigVariables             []configVar                  `json:"configVariables,omitempty"`
+	AuthConfig                  authConfig                   `json:"authConfig,omitempty"`
+	DestinationConfig           []destinationConfig          `json:"destinationConfigs,omitempty"`
+	Suspended                   bool                         `json:"suspended,omitempty"`
+	NodeConfig                  *nodeConfig                  `json:"nodeConfig,omitempty"`
+	ConnectorVersionInfraConfig *connectorVersionInfraConfig `json:"connectorVersionInfraConfig,omitempty"`
+	LogConfig                   *logConfig                   `json:"logConfig,omitempty"`
+	SslConfig                   *sslConfig                   `json:"sslConfig,omitempty"`
+	EventingEnablementType      *string                      `json:"eventingEnablementType,omitempty"`
+	EventingConfig              *eventingConfig              `json:"eventingConfig,omitempty"`
+	Status                      *connectionStatus            `json:"status,omitempty"`
+}
+
+type connectionStatus struct {
+	State       string `json:"state,omitempty"`
+	Description string `json:"description,omitempty"`
+	Status      string `json:"status,omitempty"`
 }
 
 type connectionRequest struct {
-	Labels                 *map[string]string   `json:"labels,omitempty"`
-	Description            *string              `json:"description,omitempty"`
-	ConnectorDetails       *connectorDetails    `json:"connectorDetails,omitempty"`
-	ConnectorVersion       *string              `json:"connectorVersion,omitempty"`
-	ConfigVariables        *[]configVar         `json:"configVariables,omitempty"`
-	LockConfig             *lockConfig          `json:"lockConfig,omitempty"`
-	DestinationConfigs     *[]destinationConfig `json:"destinationConfigs,omitempty"`
-	AuthConfig             *authConfig          `json:"authConfig,omitempty"`
-	ServiceAccount         *string              `json:"serviceAccount,omitempty"`
-	Suspended              *bool                `json:"suspended,omitempty"`
-	NodeConfig             *nodeConfig          `json:"nodeConfig,omitempty"`
-	LogConfig              *logConfig           `json:"logConfig,omitempty"`
-	SslConfig              *sslConfig           `json:"sslConfig,omitempty"`
-	EventingEnablementType *string              `json:"eventingEnablementType,omitempty"`
-	EventingConfig         *eventingConfig      `json:"eventingConfig,omitempty"`
+	Labels                      *map[string]string           `json:"labels,omitempty"`
+	Description                 *string                      `json:"description,omitempty"`
+	ConnectorDetails            *connectorDetails            `json:"connectorDetails,omitempty"`
+	ConnectorVersion            *string                      `json:"connectorVersion,omitempty"`
+	ConfigVariables             *[]configVar                 `json:"configVariables,omitempty"`
+	LockConfig                  *lockConfig                  `json:"lockConfig,omitempty"`
+	DestinationConfigs          *[]destinationConfig         `json:"destinationConfigs,omitempty"`
+	AuthConfig                  *authConfig                  `json:"authConfig,omitempty"`
+	ServiceAccount              *string                      `json:"serviceAccount,omitempty"`
+	Suspended                   *bool                        `json:"suspended,omitempty"`
+	NodeConfig                  *nodeConfig                  `json:"nodeConfig,omitempty"`
+	ConnectorVersionInfraConfig *connectorVersionInfraConfig `json:"connectorVersionInfraConfig,omitempty"`
+	LogConfig                   *logConfig                   `json:"logConfig,omitempty"`
+	SslConfig                   *sslConfig                   `json:"sslConfig,omitempty"`
+	EventingEnablementType      *string                      `json:"eventingEnablementType,omitempty"`
+	EventingConfig              *eventingConfig              `json:"eventingConfig,omitempty"`
 }
 
 type authConfig struct {
@@ -88,18 +200,21 @@ type lockConfig struct {
 }
 
 type logConfig struct {
-	Enabled bool `json:"enabled,omitempty"`
+	Enabled bool   `json:"enabled,omitempty"`
+	Level   string `json:"level,omitempty"`
 }
 
 type sslConfig struct {
 	UseSSL                   bool                      `json:"useSsl,omitempty"`
 	Type                     *string                   `json:"type,omitempty"`
+	TrustModel               *string                   `json:"trustModel,omitempty"`
 	PrivateServerCertificate *privateServerCertificate `json:"privateServerCertificate,omitempty"`
 	ClientCertificate        *clientCertificate        `json:"clientCertificate,omitempty"`
 	ClientPrivateKey         *clientPrivateKey         `json:"clientPrivateKey,omitempty"`
 	ClientPrivateKeyPass     *clientPrivateKeyPass     `json:"clientPrivateKeyPass,omitempty"`
 	ClientCertType           *string                   `json:"clientCertType,omitempty"`
 	ServerCertType           *string                   `json:"serverCertType,omitempty"`
+	AdditionalVariables      *[]configVar              `json:"additionalVariables,omitempty"`
 }
 
 type connectorDetails struct {
@@ -107,6 +222,33 @@ type connectorDetails struct {
 	Provider  string  `json:"provider,omitempty"`
 	Version   *int    `json:"version,omitempty"`
 	VersionId *string `json:"versionId,omitempty"`
+	// LaunchStage is informational only and is ignored on create
+	LaunchStage string `json:"launchStage,omitempty"`
+}
+
+// latestVersion is the connectorDetails version alias for the highest GA version
+const latestVersion = "latest"
+
+// UnmarshalJSON accepts latest as the connector version, leaving Version unset so
+// the highest GA version is resolved on create
+func (d *connectorDetails) UnmarshalJSON(data []byte) error {
+	type details connectorDetails
+	aux := struct {
+		Version json.RawMessage `json:"version,omitempty"`
+		*details
+	}{details: (*details)(d)}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	d.Version = nil
+	if len(aux.Version) == 0 || string(aux.Version) == "null" || string(aux.Version) == strconv.Quote(latestVersion) {
+		return nil
+	}
+	d.Version = new(int)
+	if err := json.Unmarshal(aux.Version, d.Version); err != nil {
+		return fmt.Errorf("connectorDetails version must be a number or %s: %w", latestVersion, err)
+	}
+	return nil
 }
 
 type configVar struct {
@@ -148,6 +290,7 @@ type secret struct {
 type secretDetails struct {
 	SecretName string `json:"secretName,omitempty"`
 	Reference  string `json:"reference,omitempty"`
+	Value      string `json:"value,omitempty"` // inline secret payload, used instead of reference
 }
 
 type jwtClaims struct {
@@ -178,6 +321,12 @@ type nodeConfig struct {
 	MaxNodeCount int `json:"maxNodeCount,omitempty"`
 }
 
+type connectorVersionInfraConfig struct {
+	RatelimitThreshold               string `json:"ratelimitThreshold,omitempty"`
+	ConnectionRatelimitWindowSeconds string `json:"connectionRatelimitWindowSeconds,omitempty"`
+	MaxInstanceRequestConcurrency    int    `json:"maxInstanceRequestConcurrency,omitempty"`
+}
+
 type privateServerCertificate struct {
 	SecretVersion *string        `json:"secretVersion,omitempty"`
 	SecretDetails *secretDetails `json:"secretDetails,omitempty"`
@@ -217,8 +366,8 @@ type eventingConfig struct {
 	AdditionalVariables           []additionalVariables `json:"additionalVariables,omitempty"`
 	RegistrationDestinationConfig *destinationConfig    `json:"registrationDestinationConfig,omitempty"`
 	AuthConfig                    *authConfig           `json:"authConfig,omitempty"`
-	ListenerAuthConfig            authConfig            `json:"listenerAuthConfig,omitempty"`
-	DeadLetterConfig              deadLetterConfig      `json:"deadLetterConfig,omitempty"`
+	ListenerAuthConfig            *authConfig           `json:"listenerAuthConfig,omitempty"`
+	DeadLetterConfig              *deadLetterConfig     `json:"deadLetterConfig,omitempty"`
 	ProxyDestinationConfig        *destinationConfig    `json:"proxyDestinationConfig,omitempty"`
 }
 
@@ -251,66 +400,307 @@ type proxyDestinationConfig struct {
 
 const interval = 10
 
+// CreateOptions are the options of a command that creates connections, other than the
+// ones that shape the connection request
+type CreateOptions struct {
+	AllowPreview bool   // allow creating connections on connector versions that are not GA
+	LocalKeyFile string // decrypt secret files with this local AES key instead of Cloud KMS
+}
+
 // Create
 func Create(name string, content []byte, serviceAccountName string, serviceAccountProject string,
-	encryptionKey string, grantPermission bool, createSecret bool, wait bool,
+	encryptionKey string, grantPermission bool, createSecret bool, wait bool, strictIAM bool,
+	updateSecrets bool, opts CreateOptions,
 ) (respBody []byte, err error) {
-	if serviceAccountName != "" && strings.Contains(serviceAccountName, ".iam.gserviceaccount.com") {
-		serviceAccountName = strings.Split(serviceAccountName, "@")[0]
+	start := time.Now()
+	defer func() { emitTelemetry("create", name, content, start, err) }()
+
+	if err = ValidateConnectionName(name); err != nil {
+		return nil, err
 	}
 
+	serviceAccountName, serviceAccountProject = splitServiceAccount(serviceAccountName, serviceAccountProject)
+
 	operationsBytes, err := create(name, content, serviceAccountName,
-		serviceAccountProject, encryptionKey, grantPermission, createSecret)
+		serviceAccountProject, encryptionKey, grantPermission, createSecret, strictIAM, updateSecrets, opts)
 	if err != nil {
 		return nil, err
 	}
 
 	if wait {
-		apiclient.ClientPrintHttpResponse.Set(false)
-		defer apiclient.ClientPrintHttpResponse.Set(apiclient.GetCmdPrintHttpResponseSetting())
-
-		o := operation{}
-		if err = json.Unmarshal(operationsBytes, &o); err != nil {
+		var operationBody []byte
+		if operationBody, err = waitForOperation(operationsBytes); err != nil {
 			return nil, err
 		}
+		printOperationConnection(operationBody)
+	}
 
-		operationId := filepath.Base(o.Name)
-		clilog.Info.Printf("Checking connection status for %s in %d seconds\n", operationId, interval)
+	return operationsBytes, nil
+}
 
-		stop := apiclient.Every(interval*time.Second, func(time.Time) bool {
-			var respBody []byte
+// emitTelemetry sends the result of an operation on the connection in content to the
+// telemetry sink, if one is set
+func emitTelemetry(operation string, name string, content []byte, start time.Time, err error) {
+	if apiclient.GetTelemetrySink() == nil {
+		return
+	}
+	apiclient.EmitTelemetry(operation, name, getTelemetryConnector(content), start, err)
+}
 
-			if respBody, err = GetOperation(operationId); err != nil {
-				return false
-			}
+// getTelemetryConnector returns the connector of the connection in content, if it is set
+func getTelemetryConnector(content []byte) string {
+	c := connectionRequest{}
+	if json.Unmarshal(content, &c) != nil {
+		return ""
+	}
+	if c.ConnectorDetails != nil && c.ConnectorDetails.Name != "" {
+		return c.ConnectorDetails.Name
+	}
+	if c.ConnectorVersion != nil && validateConnectorVersion(*c.ConnectorVersion) == nil {
+		return getConnectorName(*c.ConnectorVersion)
+	}
+	return ""
+}
 
-			if err = json.Unmarshal(respBody, &o); err != nil {
-				return false
-			}
+// splitServiceAccount returns the account id and project of a service account.
+// When a full email is passed and no project is set, the project is taken from the email
+func splitServiceAccount(serviceAccountName string, serviceAccountProject string) (string, string) {
+	if serviceAccountName == "" || !strings.Contains(serviceAccountName, ".iam.gserviceaccount.com") {
+		return serviceAccountName, serviceAccountProject
+	}
+	parts := strings.SplitN(serviceAccountName, "@", 2)
+	if serviceAccountProject == "" && len(parts) == 2 {
+		serviceAccountProject = strings.TrimSuffix(parts[1], ".iam.gserviceaccount.com")
+	}
+	return parts[0], serviceAccountProject
+}
 
-			if o.Done {
-				if o.Error != nil {
-					clilog.Error.Printf("Connection completed with error: %s\n", o.Error.Message)
-				} else {
-					clilog.Info.Println("Connection completed successfully!")
-				}
-				return false
-			} else {
-				clilog.Info.Printf("Connection status is: %t. Waiting %d seconds.\n", o.Done, interval)
-				return true
-			}
-		})
+// Apply creates the connection if it does not exist, otherwise it patches the fields
+// that differ from the existing connection. Service accounts, secrets and IAM grants are
+// only set up when the connection is created; an existing connection keeps the secret
+// versions it references
+func Apply(name string, content []byte, serviceAccountName string, serviceAccountProject string,
+	encryptionKey string, grantPermission bool, createSecret bool, wait bool, strictIAM bool,
+	updateSecrets bool, opts CreateOptions,
+) (respBody []byte, err error) {
+	if err = ValidateConnectionName(name); err != nil {
+		return nil, err
+	}
 
-		<-stop
+	apiclient.ClientPrintHttpResponse.Set(false)
+	existing, err := Get(name, "", false, false, false)
+	apiclient.ClientPrintHttpResponse.Set(apiclient.GetCmdPrintHttpResponseSetting())
+	if apiclient.IsNotFound(err) {
+		clilog.Info.Printf("connection %s not found, creating it\n", name)
+		return Create(name, content, serviceAccountName, serviceAccountProject,
+			encryptionKey, grantPermission, createSecret, wait, strictIAM, updateSecrets, opts)
+	}
+	if err != nil {
+		return nil, err
 	}
 
-	return respBody, err
+	serviceAccountName, serviceAccountProject = splitServiceAccount(serviceAccountName, serviceAccountProject)
+
+	// the side effects of a create are recorded by a plan instead of executed
+	payload, err := prepareConnection(&createPlan{}, content, serviceAccountName, serviceAccountProject,
+		encryptionKey, false, false, strictIAM, false, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	if payload, err = pinSecretVersions(existing, payload); err != nil {
+		return nil, err
+	}
+
+	if payload, err = mergeExistingDestinationConfigs(existing, payload); err != nil {
+		return nil, err
+	}
+
+	updateMask, err := getUpdateMask(existing, payload)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(updateMask) == 0 {
+		clilog.Info.Printf("connection %s is up to date\n", name)
+		return existing, nil
+	}
+
+	if err = CheckImmutableConfigVars(name, payload, updateMask, false); err != nil {
+		return nil, err
+	}
+
+	clilog.Info.Printf("updating connection %s fields %s\n", name, strings.Join(updateMask, ","))
+	return patch(name, payload, updateMask, wait)
+}
+
+// Replace deletes the connection, waits for the delete to complete and creates it again
+// from content. Use it for changes to fields that can't be patched. The connection is
+// prepared, including its secrets and IAM grants, before the delete so an invalid file
+// leaves the connection in place. With dryRun, only the fields that would change are printed
+func Replace(name string, content []byte, serviceAccountName string, serviceAccountProject string,
+	encryptionKey string, grantPermission bool, createSecret bool, wait bool, dryRun bool, opts CreateOptions,
+) (respBody []byte, err error) {
+	if err = ValidateConnectionName(name); err != nil {
+		return nil, err
+	}
+
+	// offline checks first, so a dry run reports them too
+	if problems := Validate(name+".json", content); len(problems) > 0 {
+		return nil, fmt.Errorf("connection %s is not replaced, the file is invalid:\n%s",
+			name, strings.Join(problems, "\n"))
+	}
+
+	apiclient.ClientPrintHttpResponse.Set(false)
+	existing, err := Get(name, "", false, false, false)
+	apiclient.ClientPrintHttpResponse.Set(apiclient.GetCmdPrintHttpResponseSetting())
+	if err != nil {
+		return nil, fmt.Errorf("connection %s not found: %w", name, err)
+	}
+
+	updateMask, err := getUpdateMask(existing, content)
+	if err != nil {
+		return nil, err
+	}
+
+	if dryRun {
+		clilog.Info.Printf("dry run: connection %s would be deleted and created again with changes to %s\n",
+			name, strings.Join(updateMask, ","))
+		return nil, nil
+	}
+
+	serviceAccountName, serviceAccountProject = splitServiceAccount(serviceAccountName, serviceAccountProject)
+
+	payload, err := prepareConnection(nil, content, serviceAccountName, serviceAccountProject,
+		encryptionKey, grantPermission, createSecret, false, false, opts)
+	if err != nil {
+		return nil, fmt.Errorf("connection %s is not replaced: %w", name, err)
+	}
+
+	clilog.Warning.Printf("replacing connection %s: it is deleted and created again, and is unavailable "+
+		"until the create completes\n", name)
+
+	apiclient.ClientPrintHttpResponse.Set(false)
+	_, err = Delete(name, true)
+	apiclient.ClientPrintHttpResponse.Set(apiclient.GetCmdPrintHttpResponseSetting())
+	if err != nil {
+		return nil, fmt.Errorf("unable to delete connection %s: %w", name, err)
+	}
+	clilog.Info.Printf("deleted connection %s, creating it again\n", name)
+
+	// the payload already has its service account, secrets and grants
+	return Create(name, payload, "", "", "", false, false, wait, false, false, opts)
+}
+
+// getUpdateMask returns the top level fields of the desired connection that differ from
+// the existing one. Secret versions are compared by secret, since a connection file
+// references a default version of the secrets it doesn't create
+func getUpdateMask(existing []byte, desired []byte) (updateMask []string, err error) {
+	e, d := map[string]interface{}{}, map[string]interface{}{}
+	if err = json.Unmarshal(existing, &e); err != nil {
+		return nil, err
+	}
+	if err = json.Unmarshal(desired, &d); err != nil {
+		return nil, err
+	}
+	for key, value := range d {
+		if !reflect.DeepEqual(mapSecretVersions(e[key], getSecretOfVersion),
+			mapSecretVersions(value, getSecretOfVersion)) {
+			updateMask = append(updateMask, key)
+		}
+	}
+	sort.Strings(updateMask)
+	return updateMask, nil
+}
+
+// pinSecretVersions replaces the secret versions of the desired connection with the
+// versions of the same secrets referenced by the existing connection, so a patch keeps them
+func pinSecretVersions(existing []byte, desired []byte) ([]byte, error) {
+	var e, d interface{}
+	if err := json.Unmarshal(existing, &e); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(desired, &d); err != nil {
+		return nil, err
+	}
+	versions := map[string]string{}
+	mapSecretVersions(e, func(secretVersion string) string {
+		versions[getSecretOfVersion(secretVersion)] = secretVersion
+		return secretVersion
+	})
+	d = mapSecretVersions(d, func(secretVersion string) string {
+		if version, ok := versions[getSecretOfVersion(secretVersion)]; ok {
+			return version
+		}
+		return secretVersion
+	})
+	return json.Marshal(d)
+}
+
+// mapSecretVersions returns a copy of v with every secret version replaced by f
+func mapSecretVersions(v interface{}, f func(string) string) interface{} {
+	switch value := v.(type) {
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(value))
+		for key, item := range value {
+			m[key] = mapSecretVersions(item, f)
+		}
+		return m
+	case []interface{}:
+		l := make([]interface{}, len(value))
+		for index, item := range value {
+			l[index] = mapSecretVersions(item, f)
+		}
+		return l
+	case string:
+		if isSecretVersionPath(value) {
+			return f(value)
+		}
+	}
+	return v
+}
+
+// getSecretOfVersion returns the secret of a secret version
+func getSecretOfVersion(secretVersion string) string {
+	return secretVersion[:strings.LastIndex(secretVersion, "/versions/")]
 }
 
 // create
 func create(name string, content []byte, serviceAccountName string, serviceAccountProject string,
-	encryptionKey string, grantPermission bool, createSecret bool,
+	encryptionKey string, grantPermission bool, createSecret bool, strictIAM bool,
+	updateSecrets bool, opts CreateOptions,
 ) (respBody []byte, err error) {
+	if content, err = prepareConnection(nil, content, serviceAccountName, serviceAccountProject,
+		encryptionKey, grantPermission, createSecret, strictIAM, updateSecrets, opts); err != nil {
+		return nil, err
+	}
+
+	u, _ := url.Parse(apiclient.GetBaseConnectorURL())
+	q := u.Query()
+	q.Set("connectionId", name)
+	u.RawQuery = q.Encode()
+
+	clilog.Debug.Printf("Connection request: %s\n", getConnectionLogBody(content))
+
+	for retry := 0; ; retry++ {
+		respBody, err = apiclient.HttpClient(u.String(), string(content))
+		// a service account created by prepareConnection may not be visible to the API yet
+		if err == nil || serviceAccountName == "" || retry == serviceAccountRetries ||
+			!serviceAccountNotFoundRegex.MatchString(err.Error()) {
+			return respBody, err
+		}
+		clilog.Warning.Printf("service account not found, retrying connection create in %s\n",
+			serviceAccountRetryDelay)
+		time.Sleep(serviceAccountRetryDelay)
+	}
+}
+
+// prepareConnection validates the connection file, grants permissions, handles secrets
+// and returns the connection request to send to the API
+func prepareConnection(plan *createPlan, content []byte, serviceAccountName string,
+	serviceAccountProject string, encryptionKey string, grantPermission bool, createSecret bool,
+	strictIAM bool, updateSecrets bool, opts CreateOptions,
+) (payload []byte, err error) {
 	var secretVersion string
 
 	c := connectionRequest{}
@@ -325,8 +715,11 @@ func create(name string, content []byte, serviceAccountName string, serviceAccou
 			serviceAccountProject = apiclient.GetProjectID()
 		}
 		serviceAccountName = fmt.Sprintf("%s@%s.iam.gserviceaccount.com", serviceAccountName, serviceAccountProject)
+		if err = apiclient.ValidateServiceAccount(serviceAccountName); err != nil {
+			return nil, err
+		}
 		// create the SA if it doesn't exist
-		if err = apiclient.CreateServiceAccount(serviceAccountName); err != nil {
+		if err = plan.createServiceAccount(serviceAccountName); err != nil {
 			return nil, err
 		}
 	} else if grantPermission { // use the default compute engine SA to grant permissions
@@ -341,160 +734,71 @@ func create(name string, content []byte, serviceAccountName string, serviceAccou
 		*c.ServiceAccount = serviceAccountName
 	}
 
-	if c.ConnectorDetails == nil {
-		return nil, fmt.Errorf("connectorDetails must be set." +
-			" See https://github.com/GoogleCloudPlatform/application-integration-management-toolkit" +
-			"#connectors-for-third-party-applications for more details")
+	// a connectorVersion resource name is used as is, otherwise it is built from connectorDetails
+	if c.ConnectorVersion != nil {
+		if c.ConnectorDetails != nil {
+			return nil, fmt.Errorf("connectorVersion and connectorDetails cannot both be set")
+		}
+		if err = validateConnectorVersion(*c.ConnectorVersion); err != nil {
+			return nil, err
+		}
+	} else if err = setConnectorVersion(&c); err != nil {
+		return nil, err
 	}
 
-	if c.ConnectorDetails.Version != nil && c.ConnectorDetails.VersionId != nil {
-		return nil, fmt.Errorf("Version and VersionId cannot be set")
+	if err = checkLaunchStage(*c.ConnectorVersion, opts.AllowPreview); err != nil {
+		return nil, err
 	}
 
-	if c.ConnectorDetails.Name == "" || c.ConnectorDetails.Provider == "" {
-		return nil, fmt.Errorf("connectorDetails Name and Provider must be set." +
-			" See https://github.com/GoogleCloudPlatform/application-integration-management-toolkit" +
-			"#connectors-for-third-party-applications for more details")
+	if err = validateDestinationConfigs(c); err != nil {
+		return nil, err
 	}
 
-	if c.ConnectorDetails.Provider == "customconnector" && c.ConnectorDetails.VersionId == nil {
-		return nil, fmt.Errorf("connectorDetails VersionId must be set for customconnectors")
-	} else if c.ConnectorDetails.Provider != "customconnector" && c.ConnectorDetails.Version == nil {
-		return nil, fmt.Errorf("connectorDetails Version must be set")
-	}
-
-	// handle project id & region overrides
-	if c.ConfigVariables != nil && len(*c.ConfigVariables) > 0 {
-		for index := range *c.ConfigVariables {
-			if (*c.ConfigVariables)[index].Key == "project_id" && *(*c.ConfigVariables)[index].StringValue == "$PROJECT_ID$" {
-				*(*c.ConfigVariables)[index].StringValue = apiclient.GetProjectID()
-			} else if strings.Contains((*c.ConfigVariables)[index].Key, "_region") &&
-				*(*c.ConfigVariables)[index].StringValue == "$REGION$" {
-				*(*c.ConfigVariables)[index].StringValue = apiclient.GetRegion()
-			}
+	if c.NodeConfig != nil && c.NodeConfig.MaxNodeCount != 0 &&
+		c.NodeConfig.MinNodeCount > c.NodeConfig.MaxNodeCount {
+		return nil, fmt.Errorf("nodeConfig minNodeCount %d cannot be greater than maxNodeCount %d",
+			c.NodeConfig.MinNodeCount, c.NodeConfig.MaxNodeCount)
+	}
+
+	// handle project id, region & service account overrides
+	if c.ConfigVariables != nil {
+		serviceAccount := ""
+		if c.ServiceAccount != nil {
+			serviceAccount = *c.ServiceAccount
 		}
+		substituteConfigVars(*c.ConfigVariables, serviceAccount)
 	}
 
 	// check if permissions need to be set
 	if grantPermission && c.ServiceAccount != nil {
-		var projectID string
-
-		switch c.ConnectorDetails.Name {
-		case "pubsub":
-			var topicName string
-
-			for _, configVar := range *c.ConfigVariables {
-				if configVar.Key == "project_id" {
-					projectID = *configVar.StringValue
-				}
-				if configVar.Key == "topic_id" {
-					topicName = *configVar.StringValue
-				}
-			}
-
-			if projectID == "" || topicName == "" {
-				return nil, fmt.Errorf("projectId or topicName was not set")
-			}
-
-			if err = apiclient.SetPubSubIAMPermission(projectID, topicName, *c.ServiceAccount); err != nil {
-				clilog.Warning.Printf("Unable to update permissions for the service account: %v\n", err)
-			}
-		case "bigquery":
-			var datasetID string
-
-			for _, configVar := range *c.ConfigVariables {
-				if configVar.Key == "project_id" {
-					projectID = *configVar.StringValue
-				}
-				if configVar.Key == "dataset_id" {
-					datasetID = *configVar.StringValue
-				}
-			}
-			if projectID == "" || datasetID == "" {
-				return nil, fmt.Errorf("project_id or dataset_id was not set")
-			}
-
-			if err = apiclient.SetBigQueryIAMPermission(projectID, datasetID, *c.ServiceAccount); err != nil {
-				clilog.Warning.Printf("Unable to update permissions for the service account: %v\n", err)
-			}
-		case "gcs":
-			for _, configVar := range *c.ConfigVariables {
-				if configVar.Key == "project_id" {
-					projectID = *configVar.StringValue
-				}
-			}
-			if projectID == "" {
-				return nil, fmt.Errorf("project_id was not set")
-			}
-			if err = apiclient.SetCloudStorageIAMPermission(projectID, *c.ServiceAccount); err != nil {
-				clilog.Warning.Printf("Unable to update permissions for the service account: %v\n", err)
-			}
-		case "cloudsql-mysql", "cloudsql-postgresql", "cloudsql-sqlserver":
-			for _, configVar := range *c.ConfigVariables {
-				if configVar.Key == "project_id" {
-					projectID = *configVar.StringValue
-				}
-			}
-			if projectID == "" {
-				return nil, fmt.Errorf("projectId was not set")
-			}
-			if err = apiclient.SetCloudSQLIAMPermission(projectID, *c.ServiceAccount); err != nil {
-				clilog.Warning.Printf("Unable to update permissions for the service account: %v\n", err)
-			}
-		case "cloudspanner":
-			for _, configVar := range *c.ConfigVariables {
-				if configVar.Key == "project_id" {
-					projectID = *configVar.StringValue
-				}
-			}
-			if projectID == "" {
-				return nil, fmt.Errorf("project_id was not set")
-			}
-			if err = apiclient.SetCloudSpannerIAMPermission(projectID, *c.ServiceAccount); err != nil {
-				clilog.Warning.Printf("Unable to update permissions for the service account: %v\n", err)
-			}
+		configVars := []configVar{}
+		if c.ConfigVariables != nil {
+			configVars = *c.ConfigVariables
+		}
+		if err = grantConnectorPermissions(plan, getConnectorName(*c.ConnectorVersion), configVars,
+			*c.ServiceAccount, strictIAM); err != nil {
+			return nil, err
 		}
 	}
 
-	c.ConnectorVersion = new(string)
-	if c.ConnectorDetails.VersionId != nil {
-		*c.ConnectorVersion = fmt.Sprintf("projects/%s/locations/global/providers/%s/connectors/%s/versions/%s",
-			apiclient.GetProjectID(), c.ConnectorDetails.Provider, c.ConnectorDetails.Name, *c.ConnectorDetails.VersionId)
-	} else {
-		*c.ConnectorVersion = fmt.Sprintf("projects/%s/locations/global/providers/%s/connectors/%s/versions/%d",
-			apiclient.GetProjectID(), c.ConnectorDetails.Provider, c.ConnectorDetails.Name, *c.ConnectorDetails.Version)
-	}
-
-	// remove the element
-	c.ConnectorDetails = nil
-
 	// handle secrets for username
 	if c.AuthConfig != nil {
 		switch c.AuthConfig.AuthType {
 		case "USER_PASSWORD":
 			if c.AuthConfig.UserPassword != nil && c.AuthConfig.UserPassword.PasswordDetails != nil {
 				if createSecret {
-					if c.AuthConfig.UserPassword.PasswordDetails.Reference == "" {
-						return nil, fmt.Errorf("create-secret is enabled, but reference is not passed")
+					if c.AuthConfig.UserPassword.PasswordDetails.Reference == "" &&
+						c.AuthConfig.UserPassword.PasswordDetails.Value == "" {
+						return nil, fmt.Errorf("create-secret is enabled, but reference or value is not passed")
 					}
-					payload, err := readSecretFile(c.AuthConfig.UserPassword.PasswordDetails.Reference)
+					payload, err := getSecretPayload(c.AuthConfig.UserPassword.PasswordDetails, encryptionKey, opts.LocalKeyFile)
 					if err != nil {
 						return nil, err
 					}
 
-					// check if a Cloud KMS key was passsed, assume the file is encrypted
-					if encryptionKey != "" {
-						encryptionKey := path.Join("projects", apiclient.GetProjectID(), encryptionKey)
-						payload, err = cloudkms.DecryptSymmetric(encryptionKey, payload)
-						if err != nil {
-							return nil, err
-						}
-					}
-
-					if secretVersion, err = secmgr.Create(
-						apiclient.GetProjectID(),
+					if secretVersion, err = plan.createSecretVersion(
 						c.AuthConfig.UserPassword.PasswordDetails.SecretName,
-						payload); err != nil {
+						payload, updateSecrets); err != nil {
 						return nil, err
 					}
 
@@ -504,17 +808,21 @@ func create(name string, content []byte, serviceAccountName string, serviceAccou
 					c.AuthConfig.UserPassword.PasswordDetails = nil // clean the input
 					if grantPermission && c.ServiceAccount != nil {
 						// grant connector service account access to secretVersion
-						if err = apiclient.SetSecretManagerIAMPermission(
+						if err = handleIAMError(plan.setSecretManagerIAMPermission(
 							apiclient.GetProjectID(),
 							secretName,
-							*c.ServiceAccount); err != nil {
+							*c.ServiceAccount), strictIAM); err != nil {
 							return nil, err
 						}
 					}
 				} else {
+					existing := ""
+					if c.AuthConfig.UserPassword.Password != nil {
+						existing = c.AuthConfig.UserPassword.Password.SecretVersion
+					}
 					c.AuthConfig.UserPassword.Password = new(secret)
-					c.AuthConfig.UserPassword.Password.SecretVersion = fmt.Sprintf("projects/%s/secrets/%s/versions/1",
-						apiclient.GetProjectID(), c.AuthConfig.UserPassword.PasswordDetails.SecretName)
+					c.AuthConfig.UserPassword.Password.SecretVersion = getSecretVersion(existing,
+						c.AuthConfig.UserPassword.PasswordDetails.SecretName)
 					c.AuthConfig.UserPassword.PasswordDetails = nil // clean the input
 				}
 			}
@@ -522,22 +830,13 @@ func create(name string, content []byte, serviceAccountName string, serviceAccou
 			if c.AuthConfig.Oauth2JwtBearer != nil && c.AuthConfig.Oauth2JwtBearer.ClientKeyDetails != nil {
 				if createSecret {
 					clilog.Warning.Printf("Creating secrets for %s is not implemented\n", c.AuthConfig.AuthType)
-					payload, err := readSecretFile(c.AuthConfig.Oauth2JwtBearer.ClientKeyDetails.Reference)
+					payload, err := getSecretPayload(c.AuthConfig.Oauth2JwtBearer.ClientKeyDetails, encryptionKey, opts.LocalKeyFile)
 					if err != nil {
 						return nil, err
 					}
-					// check if a Cloud KMS key was passsed, assume the file is encrypted
-					if encryptionKey != "" {
-						encryptionKey := path.Join("projects", apiclient.GetProjectID(), encryptionKey)
-						payload, err = cloudkms.DecryptSymmetric(encryptionKey, payload)
-						if err != nil {
-							return nil, err
-						}
-					}
-					if secretVersion, err = secmgr.Create(
-						apiclient.GetProjectID(),
+					if secretVersion, err = plan.createSecretVersion(
 						c.AuthConfig.Oauth2JwtBearer.ClientKeyDetails.SecretName,
-						payload); err != nil {
+						payload, updateSecrets); err != nil {
 						return nil, err
 					}
 					secretName := c.AuthConfig.Oauth2JwtBearer.ClientKeyDetails.SecretName
@@ -546,17 +845,20 @@ func create(name string, content []byte, serviceAccountName string, serviceAccou
 					c.AuthConfig.Oauth2JwtBearer.ClientKeyDetails = nil // clean the input
 					if grantPermission && c.ServiceAccount != nil {
 						// grant connector service account access to secret version
-						if err = apiclient.SetSecretManagerIAMPermission(
+						if err = handleIAMError(plan.setSecretManagerIAMPermission(
 							apiclient.GetProjectID(),
 							secretName,
-							*c.ServiceAccount); err != nil {
+							*c.ServiceAccount), strictIAM); err != nil {
 							return nil, err
 						}
 					}
 				} else {
+					existing := ""
+					if c.AuthConfig.Oauth2JwtBearer.ClientKey != nil {
+						existing = c.AuthConfig.Oauth2JwtBearer.ClientKey.SecretVersion
+					}
 					c.AuthConfig.Oauth2JwtBearer.ClientKey = new(secret)
-					c.AuthConfig.Oauth2JwtBearer.ClientKey.SecretVersion = fmt.Sprintf("projects/%s/secrets/%s/versions/1",
-						apiclient.GetProjectID(),
+					c.AuthConfig.Oauth2JwtBearer.ClientKey.SecretVersion = getSecretVersion(existing,
 						c.AuthConfig.Oauth2JwtBearer.ClientKeyDetails.SecretName)
 					c.AuthConfig.Oauth2JwtBearer.ClientKeyDetails = nil
 				}
@@ -582,164 +884,547 @@ func create(name string, content []byte, serviceAccountName string, serviceAccou
 	if c.SslConfig != nil {
 		if c.SslConfig.PrivateServerCertificate != nil && c.SslConfig.PrivateServerCertificate.SecretDetails != nil {
 			if createSecret {
-				payload, err := readSecretFile(c.SslConfig.PrivateServerCertificate.SecretDetails.Reference)
+				payload, err := getSecretPayload(c.SslConfig.PrivateServerCertificate.SecretDetails,
+					encryptionKey, opts.LocalKeyFile)
 				if err != nil {
 					return nil, err
 				}
-				// check if a Cloud KMS key was passsed, assume the file is encrypted
-				if encryptionKey != "" {
-					encryptionKey := path.Join("projects", apiclient.GetProjectID(), encryptionKey)
-					payload, err = cloudkms.DecryptSymmetric(encryptionKey, payload)
-					if err != nil {
-						return nil, err
-					}
-				}
 
-				if secretVersion, err = secmgr.Create(
-					apiclient.GetProjectID(),
+				if secretVersion, err = plan.createSecretVersion(
 					c.SslConfig.PrivateServerCertificate.SecretDetails.SecretName,
-					payload); err != nil {
+					payload, updateSecrets); err != nil {
 					return nil, err
 				}
 
+				if grantPermission && c.ServiceAccount != nil {
+					// grant connector service account access to secret version
+					if err = handleIAMError(plan.setSecretManagerIAMPermission(
+						apiclient.GetProjectID(),
+						c.SslConfig.PrivateServerCertificate.SecretDetails.SecretName,
+						*c.ServiceAccount), strictIAM); err != nil {
+						return nil, err
+					}
+				}
+
 				c.SslConfig.PrivateServerCertificate.SecretVersion = new(string)
 				*c.SslConfig.PrivateServerCertificate.SecretVersion = secretVersion
 				c.SslConfig.PrivateServerCertificate.SecretDetails = nil // clean the input
 
 			} else {
+				existing := ""
+				if c.SslConfig.PrivateServerCertificate.SecretVersion != nil {
+					existing = *c.SslConfig.PrivateServerCertificate.SecretVersion
+				}
 				c.SslConfig.PrivateServerCertificate.SecretVersion = new(string)
-				*c.SslConfig.PrivateServerCertificate.SecretVersion = fmt.Sprintf("projects/%s/secrets/%s/versions/1",
-					apiclient.GetProjectID(), c.SslConfig.PrivateServerCertificate.SecretDetails.SecretName)
+				*c.SslConfig.PrivateServerCertificate.SecretVersion = getSecretVersion(existing,
+					c.SslConfig.PrivateServerCertificate.SecretDetails.SecretName)
 				c.SslConfig.PrivateServerCertificate.SecretDetails = nil // clean the input
 			}
 		}
 		if c.SslConfig.ClientCertificate != nil && c.SslConfig.ClientCertificate.SecretDetails != nil {
 			if createSecret {
-				payload, err := readSecretFile(c.SslConfig.ClientCertificate.SecretDetails.Reference)
+				payload, err := getSecretPayload(c.SslConfig.ClientCertificate.SecretDetails, encryptionKey, opts.LocalKeyFile)
 				if err != nil {
 					return nil, err
 				}
-				// check if a Cloud KMS key was passsed, assume the file is encrypted
-				if encryptionKey != "" {
-					encryptionKey := path.Join("projects", apiclient.GetProjectID(), encryptionKey)
-					payload, err = cloudkms.DecryptSymmetric(encryptionKey, payload)
-					if err != nil {
-						return nil, err
-					}
-				}
 
-				if secretVersion, err = secmgr.Create(
-					apiclient.GetProjectID(),
+				if secretVersion, err = plan.createSecretVersion(
 					c.SslConfig.ClientCertificate.SecretDetails.SecretName,
-					payload); err != nil {
+					payload, updateSecrets); err != nil {
 					return nil, err
 				}
 
+				if grantPermission && c.ServiceAccount != nil {
+					// grant connector service account access to secret version
+					if err = handleIAMError(plan.setSecretManagerIAMPermission(
+						apiclient.GetProjectID(),
+						c.SslConfig.ClientCertificate.SecretDetails.SecretName,
+						*c.ServiceAccount), strictIAM); err != nil {
+						return nil, err
+					}
+				}
+
 				c.SslConfig.ClientCertificate.SecretVersion = new(string)
 				*c.SslConfig.ClientCertificate.SecretVersion = secretVersion
 				c.SslConfig.ClientCertificate.SecretDetails = nil // clean the input
 			} else {
+				existing := ""
+				if c.SslConfig.ClientCertificate.SecretVersion != nil {
+					existing = *c.SslConfig.ClientCertificate.SecretVersion
+				}
 				c.SslConfig.ClientCertificate.SecretVersion = new(string)
-				*c.SslConfig.ClientCertificate.SecretVersion = fmt.Sprintf("projects/%s/secrets/%s/versions/1",
-					apiclient.GetProjectID(), c.SslConfig.ClientCertificate.SecretDetails.SecretName)
+				*c.SslConfig.ClientCertificate.SecretVersion = getSecretVersion(existing,
+					c.SslConfig.ClientCertificate.SecretDetails.SecretName)
 				c.SslConfig.ClientCertificate.SecretDetails = nil // clean the input
 			}
 		}
 		if c.SslConfig.ClientPrivateKey != nil && c.SslConfig.ClientPrivateKey.SecretDetails != nil {
 			if createSecret {
-				payload, err := readSecretFile(c.SslConfig.ClientPrivateKey.SecretDetails.Reference)
+				payload, err := getSecretPayload(c.SslConfig.ClientPrivateKey.SecretDetails, encryptionKey, opts.LocalKeyFile)
 				if err != nil {
 					return nil, err
 				}
-				// check if a Cloud KMS key was passsed, assume the file is encrypted
-				if encryptionKey != "" {
-					encryptionKey := path.Join("projects", apiclient.GetProjectID(), encryptionKey)
-					payload, err = cloudkms.DecryptSymmetric(encryptionKey, payload)
-					if err != nil {
-						return nil, err
-					}
-				}
 
-				if secretVersion, err = secmgr.Create(
-					apiclient.GetProjectID(),
+				if secretVersion, err = plan.createSecretVersion(
 					c.SslConfig.ClientPrivateKey.SecretDetails.SecretName,
-					payload); err != nil {
+					payload, updateSecrets); err != nil {
 					return nil, err
 				}
 
+				if grantPermission && c.ServiceAccount != nil {
+					// grant connector service account access to secret version
+					if err = handleIAMError(plan.setSecretManagerIAMPermission(
+						apiclient.GetProjectID(),
+						c.SslConfig.ClientPrivateKey.SecretDetails.SecretName,
+						*c.ServiceAccount), strictIAM); err != nil {
+						return nil, err
+					}
+				}
+
 				c.SslConfig.ClientPrivateKey.SecretVersion = new(string)
 				*c.SslConfig.ClientPrivateKey.SecretVersion = secretVersion
 				c.SslConfig.ClientPrivateKey.SecretDetails = nil // clean the input
 			} else {
+				existing := ""
+				if c.SslConfig.ClientPrivateKey.SecretVersion != nil {
+					existing = *c.SslConfig.ClientPrivateKey.SecretVersion
+				}
 				c.SslConfig.ClientPrivateKey.SecretVersion = new(string)
-				*c.SslConfig.ClientPrivateKey.SecretVersion = fmt.Sprintf("projects/%s/secrets/%s/versions/1",
-					apiclient.GetProjectID(), c.SslConfig.ClientPrivateKey.SecretDetails.SecretName)
+				*c.SslConfig.ClientPrivateKey.SecretVersion = getSecretVersion(existing,
+					c.SslConfig.ClientPrivateKey.SecretDetails.SecretName)
 				c.SslConfig.ClientPrivateKey.SecretDetails = nil // clean the input
 			}
 		}
 		if c.SslConfig.ClientPrivateKeyPass != nil && c.SslConfig.ClientPrivateKeyPass.SecretDetails != nil {
 			if createSecret {
-				payload, err := readSecretFile(c.SslConfig.ClientPrivateKeyPass.SecretDetails.Reference)
+				payload, err := getSecretPayload(c.SslConfig.ClientPrivateKeyPass.SecretDetails, encryptionKey, opts.LocalKeyFile)
 				if err != nil {
 					return nil, err
 				}
-				// check if a Cloud KMS key was passsed, assume the file is encrypted
-				if encryptionKey != "" {
-					encryptionKey := path.Join("projects", apiclient.GetProjectID(), encryptionKey)
-					payload, err = cloudkms.DecryptSymmetric(encryptionKey, payload)
-					if err != nil {
-						return nil, err
-					}
-				}
 
-				if secretVersion, err = secmgr.Create(
-					apiclient.GetProjectID(),
+				if secretVersion, err = plan.createSecretVersion(
 					c.SslConfig.ClientPrivateKeyPass.SecretDetails.SecretName,
-					payload); err != nil {
+					payload, updateSecrets); err != nil {
 					return nil, err
 				}
 
+				if grantPermission && c.ServiceAccount != nil {
+					// grant connector service account access to secret version
+					if err = handleIAMError(plan.setSecretManagerIAMPermission(
+						apiclient.GetProjectID(),
+						c.SslConfig.ClientPrivateKeyPass.SecretDetails.SecretName,
+						*c.ServiceAccount), strictIAM); err != nil {
+						return nil, err
+					}
+				}
+
 				c.SslConfig.ClientPrivateKeyPass.SecretVersion = new(string)
 				*c.SslConfig.ClientPrivateKeyPass.SecretVersion = secretVersion
 				c.SslConfig.ClientPrivateKeyPass.SecretDetails = nil // clean the input
 			} else {
+				existing := ""
+				if c.SslConfig.ClientPrivateKeyPass.SecretVersion != nil {
+					existing = *c.SslConfig.ClientPrivateKeyPass.SecretVersion
+				}
 				c.SslConfig.ClientPrivateKeyPass.SecretVersion = new(string)
-				*c.SslConfig.ClientPrivateKeyPass.SecretVersion = fmt.Sprintf("projects/%s/secrets/%s/versions/1",
-					apiclient.GetProjectID(), c.SslConfig.ClientPrivateKeyPass.SecretDetails.SecretName)
+				*c.SslConfig.ClientPrivateKeyPass.SecretVersion = getSecretVersion(existing,
+					c.SslConfig.ClientPrivateKeyPass.SecretDetails.SecretName)
 				c.SslConfig.ClientPrivateKeyPass.SecretDetails = nil // clean the input
 			}
 		}
 	}
 
-	u, _ := url.Parse(apiclient.GetBaseConnectorURL())
-	q := u.Query()
-	q.Set("connectionId", name)
-	u.RawQuery = q.Encode()
+	// handle secrets for config variables and additional auth variables
+	for _, configVars := range []*[]configVar{c.ConfigVariables, getAuthAdditionalVariables(c.AuthConfig)} {
+		if configVars == nil {
+			continue
+		}
+		if err = prepareConfigVarSecrets(plan, *configVars, c.ServiceAccount, encryptionKey, opts.LocalKeyFile,
+			grantPermission, createSecret, strictIAM, updateSecrets); err != nil {
+			return nil, err
+		}
+	}
 
-	if content, err = json.Marshal(c); err != nil {
-		return nil, err
+	// handle secrets for the eventing auth configs
+	if c.EventingConfig != nil {
+		for _, a := range []*authConfig{c.EventingConfig.AuthConfig, c.EventingConfig.ListenerAuthConfig} {
+			if a == nil || a.UserPassword == nil || a.UserPassword.PasswordDetails == nil {
+				continue
+			}
+			if err = prepareUserPasswordSecret(plan, a.UserPassword, c.ServiceAccount, encryptionKey,
+				opts.LocalKeyFile, grantPermission, createSecret, strictIAM, updateSecrets); err != nil {
+				return nil, err
+			}
+		}
 	}
 
-	respBody, err = apiclient.HttpClient(u.String(), string(content))
-	return respBody, err
+	return json.Marshal(c)
+}
+
+// setConnectorVersion validates the connectorDetails of the connection and replaces them
+// with the connectorVersion resource name. The latest GA version is used if neither
+// version nor versionId is set
+func setConnectorVersion(c *connectionRequest) (err error) {
+	if c.ConnectorDetails == nil {
+		return fmt.Errorf("connectorDetails or connectorVersion must be set." +
+			" See https://github.com/GoogleCloudPlatform/application-integration-management-toolkit" +
+			"#connectors-for-third-party-applications for more details")
+	}
+
+	if c.ConnectorDetails.Version != nil && c.ConnectorDetails.VersionId != nil {
+		return fmt.Errorf("Version and VersionId cannot be set")
+	}
+
+	if c.ConnectorDetails.Name == "" || c.ConnectorDetails.Provider == "" {
+		return fmt.Errorf("connectorDetails Name and Provider must be set." +
+			" See https://github.com/GoogleCloudPlatform/application-integration-management-toolkit" +
+			"#connectors-for-third-party-applications for more details")
+	}
+
+	if c.ConnectorDetails.Provider == "customconnector" && c.ConnectorDetails.VersionId == nil {
+		return fmt.Errorf("connectorDetails VersionId must be set for customconnectors")
+	} else if c.ConnectorDetails.Provider != "customconnector" && c.ConnectorDetails.Version == nil &&
+		c.ConnectorDetails.VersionId == nil {
+		if c.ConnectorDetails.Version, err = getLatestGAVersion(c.ConnectorDetails.Provider,
+			c.ConnectorDetails.Name); err != nil {
+			return err
+		}
+	}
+
+	c.ConnectorVersion = new(string)
+	if c.ConnectorDetails.VersionId != nil {
+		*c.ConnectorVersion = getConnectorVersionName(c.ConnectorDetails.Provider,
+			c.ConnectorDetails.Name, *c.ConnectorDetails.VersionId)
+	} else {
+		*c.ConnectorVersion = getConnectorVersionName(c.ConnectorDetails.Provider,
+			c.ConnectorDetails.Name, strconv.Itoa(*c.ConnectorDetails.Version))
+	}
+
+	// remove the element
+	c.ConnectorDetails = nil
+	return nil
+}
+
+// connectorVersionRegex matches a connector version resource name
+var connectorVersionRegex = regexp.MustCompile(
+	`^projects/[^/]+/locations/[^/]+/providers/[^/]+/connectors/[^/]+/versions/[^/]+$`)
+
+// validateConnectorVersion returns an error if version is not a connector version resource name
+func validateConnectorVersion(version string) error {
+	if !connectorVersionRegex.MatchString(version) {
+		return fmt.Errorf("connectorVersion %s must be of the format projects/{project}/locations/global/"+
+			"providers/{provider}/connectors/{connector}/versions/{version}", version)
+	}
+	return nil
+}
+
+// prepareConfigVarSecrets creates the secrets of the config variables that carry
+// secretDetails, or points them to the latest version of the secret, and cleans the input.
+// Config variables that only reference an existing secret version are passed through
+func prepareConfigVarSecrets(plan *createPlan, configVars []configVar, serviceAccount *string,
+	encryptionKey string, localKeyFile string, grantPermission bool, createSecret bool, strictIAM bool,
+	updateSecrets bool,
+) (err error) {
+	var secretVersion string
+
+	for index := range configVars {
+		configVar := &configVars[index]
+		if configVar.SecretDetails == nil {
+			continue
+		}
+		if createSecret && (configVar.SecretDetails.Reference != "" || configVar.SecretDetails.Value != "") {
+			payload, err := getSecretPayload(configVar.SecretDetails, encryptionKey, localKeyFile)
+			if err != nil {
+				return err
+			}
+
+			if secretVersion, err = plan.createSecretVersion(
+				configVar.SecretDetails.SecretName,
+				payload, updateSecrets); err != nil {
+				return err
+			}
+
+			if grantPermission && serviceAccount != nil {
+				// grant connector service account access to secret version
+				if err = handleIAMError(plan.setSecretManagerIAMPermission(
+					apiclient.GetProjectID(),
+					configVar.SecretDetails.SecretName,
+					*serviceAccount), strictIAM); err != nil {
+					return err
+				}
+			}
+
+			configVar.SecretValue = new(secret)
+			configVar.SecretValue.SecretVersion = secretVersion
+			configVar.SecretDetails = nil // clean the input
+		} else {
+			existing := ""
+			if configVar.SecretValue != nil {
+				existing = configVar.SecretValue.SecretVersion
+			}
+			configVar.SecretValue = new(secret)
+			configVar.SecretValue.SecretVersion = getSecretVersion(existing,
+				configVar.SecretDetails.SecretName)
+			configVar.SecretDetails = nil // clean the input
+		}
+	}
+	return nil
+}
+
+// getAuthAdditionalVariables returns the additional variables of the auth config, if any
+func getAuthAdditionalVariables(a *authConfig) *[]configVar {
+	if a == nil {
+		return nil
+	}
+	return a.AdditionalVariables
+}
+
+// prepareUserPasswordSecret replaces the password details with the secret version,
+// creating the secret from the reference or value when createSecret is set
+func prepareUserPasswordSecret(plan *createPlan, up *userPassword, serviceAccount *string,
+	encryptionKey string, localKeyFile string, grantPermission bool, createSecret bool, strictIAM bool,
+	updateSecrets bool,
+) (err error) {
+	if !createSecret {
+		existing := ""
+		if up.Password != nil {
+			existing = up.Password.SecretVersion
+		}
+		up.Password = new(secret)
+		up.Password.SecretVersion = getSecretVersion(existing, up.PasswordDetails.SecretName)
+		up.PasswordDetails = nil // clean the input
+		return nil
+	}
+
+	if up.PasswordDetails.Reference == "" && up.PasswordDetails.Value == "" {
+		return fmt.Errorf("create-secret is enabled, but reference or value is not passed")
+	}
+	payload, err := getSecretPayload(up.PasswordDetails, encryptionKey, localKeyFile)
+	if err != nil {
+		return err
+	}
+
+	secretName := up.PasswordDetails.SecretName
+	secretVersion, err := plan.createSecretVersion(secretName, payload, updateSecrets)
+	if err != nil {
+		return err
+	}
+
+	if grantPermission && serviceAccount != nil {
+		// grant connector service account access to secret version
+		if err = handleIAMError(plan.setSecretManagerIAMPermission(
+			apiclient.GetProjectID(),
+			secretName,
+			*serviceAccount), strictIAM); err != nil {
+			return err
+		}
+	}
+
+	up.Password = new(secret)
+	up.Password.SecretVersion = secretVersion
+	up.PasswordDetails = nil // clean the input
+	return nil
+}
+
+// substituteConfigVars replaces the $PROJECT_ID$ and $REGION$ placeholders in the
+// string values of the config variables with the current project and region, and the
+// $SERVICE_ACCOUNT$ placeholder with the service account email of the connection
+func substituteConfigVars(configVars []configVar, serviceAccount string) {
+	for index := range configVars {
+		cv := &configVars[index]
+		if cv.StringValue == nil {
+			continue
+		}
+		if cv.Key == "project_id" && *cv.StringValue == "$PROJECT_ID$" {
+			*cv.StringValue = apiclient.GetProjectID()
+		} else if strings.Contains(cv.Key, "_region") && *cv.StringValue == "$REGION$" {
+			*cv.StringValue = apiclient.GetRegion()
+		} else if *cv.StringValue == "$SERVICE_ACCOUNT$" {
+			if serviceAccount == "" {
+				clilog.Warning.Printf("config variable %s uses $SERVICE_ACCOUNT$, but the connection has no "+
+					"service account; set one with --sa or serviceAccount\n", cv.Key)
+				continue
+			}
+			*cv.StringValue = serviceAccount
+		}
+	}
+}
+
+// getStringConfigVar returns the string value of the config variable with key.
+// ok is false when the key is not found or has no string value
+func getStringConfigVar(configVars []configVar, key string) (value string, ok bool) {
+	for _, cv := range configVars {
+		if cv.Key == key && cv.StringValue != nil {
+			return *cv.StringValue, true
+		}
+	}
+	return "", false
+}
+
+// grantConnectorPermissions grants the service account access to the Google Cloud
+// resources used by the Google connectors, based on the connection config variables
+func grantConnectorPermissions(plan *createPlan, connectorName string, configVars []configVar,
+	serviceAccount string, strictIAM bool,
+) (err error) {
+	var projectID string
+
+	switch connectorName {
+	case "pubsub":
+		projectID, _ = getStringConfigVar(configVars, "project_id")
+		topicName, _ := getStringConfigVar(configVars, "topic_id")
+
+		if projectID == "" || topicName == "" {
+			return fmt.Errorf("projectId or topicName was not set")
+		}
+
+		if err = handleIAMError(plan.setIAMPermission(fmt.Sprintf("%s on pubsub topic projects/%s/topics/%s", serviceAccount, projectID, topicName),
+			func() error { return apiclient.SetPubSubIAMPermission(projectID, topicName, serviceAccount) }), strictIAM); err != nil {
+			return err
+		}
+	case "bigquery":
+		projectID, _ = getStringConfigVar(configVars, "project_id")
+		datasetID, _ := getStringConfigVar(configVars, "dataset_id")
+		if projectID == "" || datasetID == "" {
+			return fmt.Errorf("project_id or dataset_id was not set")
+		}
+
+		if err = handleIAMError(plan.setIAMPermission(fmt.Sprintf("%s on bigquery dataset %s:%s", serviceAccount, projectID, datasetID),
+			func() error { return apiclient.SetBigQueryIAMPermission(projectID, datasetID, serviceAccount) }), strictIAM); err != nil {
+			return err
+		}
+	case "gcs":
+		projectID, _ = getStringConfigVar(configVars, "project_id")
+		if projectID == "" {
+			return fmt.Errorf("project_id was not set")
+		}
+		if err = handleIAMError(plan.setIAMPermission(fmt.Sprintf("%s on cloud storage in project %s", serviceAccount, projectID),
+			func() error { return apiclient.SetCloudStorageIAMPermission(projectID, serviceAccount) }), strictIAM); err != nil {
+			return err
+		}
+	case "cloudsql-mysql", "cloudsql-postgresql", "cloudsql-sqlserver":
+		projectID, _ = getStringConfigVar(configVars, "project_id")
+		if projectID == "" {
+			return fmt.Errorf("projectId was not set")
+		}
+		if err = handleIAMError(plan.setIAMPermission(fmt.Sprintf("%s on cloud sql in project %s", serviceAccount, projectID),
+			func() error { return apiclient.SetCloudSQLIAMPermission(projectID, serviceAccount) }), strictIAM); err != nil {
+			return err
+		}
+	case "cloudspanner":
+		projectID, _ = getStringConfigVar(configVars, "project_id")
+		if projectID == "" {
+			return fmt.Errorf("project_id was not set")
+		}
+		if err = handleIAMError(plan.setIAMPermission(fmt.Sprintf("%s on cloud spanner in project %s", serviceAccount, projectID),
+			func() error { return apiclient.SetCloudSpannerIAMPermission(projectID, serviceAccount) }), strictIAM); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 // Delete
-func Delete(name string) (respBody []byte, err error) {
+func Delete(name string, wait bool) (respBody []byte, err error) {
+	start := time.Now()
+	defer func() { apiclient.EmitTelemetry("delete", name, "", start, err) }()
+
 	u, _ := url.Parse(apiclient.GetBaseConnectorURL())
 	u.Path = path.Join(u.Path, name)
-	respBody, err = apiclient.HttpClient(u.String(), "", "DELETE")
-	return respBody, err
+	if respBody, err = apiclient.HttpClient(u.String(), "", "DELETE"); err != nil {
+		return nil, err
+	}
+	if wait {
+		if _, err = waitForOperation(respBody); err != nil {
+			return nil, err
+		}
+	}
+	return respBody, nil
+}
+
+// RepairEventing repairs the eventing of an event enabled connection, for ex: when the
+// event subscription registration is broken. The connection itself is not changed
+func RepairEventing(name string, wait bool) (respBody []byte, err error) {
+	u, _ := url.Parse(apiclient.GetBaseConnectorURL())
+	u.Path = path.Join(u.Path, name+":repairEventing")
+	if respBody, err = apiclient.HttpClient(u.String(), "{}"); err != nil {
+		return nil, err
+	}
+	if wait {
+		if _, err = waitForOperation(respBody); err != nil {
+			return nil, err
+		}
+	}
+	return respBody, nil
+}
+
+// authCodeFlowAuthType is the auth type of connections authorized interactively with OAuth
+const authCodeFlowAuthType = "OAUTH2_AUTH_CODE_FLOW"
+
+// RefreshToken refreshes the access token of an OAUTH2_AUTH_CODE_FLOW connection with its
+// refresh token and reports when the new token expires. The tokens are not printed
+func RefreshToken(name string) (respBody []byte, err error) {
+	c, err := GetConnection(name)
+	if err != nil {
+		return nil, err
+	}
+	if c.AuthConfig.AuthType != authCodeFlowAuthType {
+		return nil, fmt.Errorf("connection %s uses auth type %q, only %s connections have tokens to refresh",
+			name, c.AuthConfig.AuthType, authCodeFlowAuthType)
+	}
+
+	u, _ := url.Parse(apiclient.GetBaseConnectorRuntimeURL())
+	u.Path = path.Join(u.Path, name+":refreshAccessToken")
+	apiclient.ClientPrintHttpResponse.Set(false)
+	respBody, err = apiclient.HttpClient(u.String(), "{}")
+	apiclient.ClientPrintHttpResponse.Set(apiclient.GetCmdPrintHttpResponseSetting())
+	if err != nil {
+		return nil, fmt.Errorf("unable to refresh the access token of %s, the connection may need "+
+			"to be authorized again: %w", name, err)
+	}
+
+	r := struct {
+		AccessCredentials struct {
+			ExpiresIn string `json:"expiresIn,omitempty"`
+		} `json:"accessCredentials,omitempty"`
+	}{}
+	if err = json.Unmarshal(respBody, &r); err != nil {
+		return nil, fmt.Errorf("failed to unmarshall: %w", err)
+	}
+	if r.AccessCredentials.ExpiresIn != "" {
+		clilog.Info.Printf("Refreshed the access token of %s, it expires in %s\n", name, r.AccessCredentials.ExpiresIn)
+	} else {
+		clilog.Info.Printf("Refreshed the access token of %s\n", name)
+	}
+	return respBody, nil
+}
+
+// GetConnection returns the connection as a struct without printing the response
+func GetConnection(name string) (c connection, err error) {
+	apiclient.ClientPrintHttpResponse.Set(false)
+	defer apiclient.ClientPrintHttpResponse.Set(apiclient.GetCmdPrintHttpResponseSetting())
+
+	respBody, err := Get(name, "", false, false, false)
+	if err != nil {
+		return connection{}, err
+	}
+	if err = json.Unmarshal(respBody, &c); err != nil {
+		return connection{}, fmt.Errorf("failed to unmarshall: %w", err)
+	}
+	return c, nil
 }
 
 // Get
-func Get(name string, view string, minimal bool, overrides bool) (respBody []byte, err error) {
+func Get(name string, view string, minimal bool, overrides bool, launchStage bool) (respBody []byte, err error) {
 	var connectionPayload []byte
+	if err = ValidateView(view); err != nil {
+		return nil, err
+	}
 	u, _ := url.Parse(apiclient.GetBaseConnectorURL())
 	q := u.Query()
 	if view != "" {
 		q.Set("view", view)
 	}
+	u.RawQuery = q.Encode()
 	u.Path = path.Join(u.Path, name)
 
 	if minimal {
@@ -766,8 +1451,13 @@ func Get(name string, view string, minimal bool, overrides bool) (respBody []byt
 			*c.ConnectorDetails.VersionId = getConnectorVersionId(*c.ConnectorVersion)
 		}
 
+		if launchStage {
+			setConnectorLaunchStage(c.ConnectorDetails, *c.ConnectorVersion)
+		}
+
 		c.ConnectorVersion = nil
 		c.Name = nil
+		c.Status = nil
 		if overrides {
 			switch c.AuthConfig.AuthType {
 			case "USER_PASSWORD":
@@ -781,9 +1471,10 @@ func Get(name string, view string, minimal bool, overrides bool) (respBody []byt
 				c.AuthConfig.Oauth2JwtBearer.ClientKeyDetails.SecretName = strings.Split(p, "/")[3]
 				c.AuthConfig.Oauth2JwtBearer.ClientKey = nil
 			}
+			setSecretDetailsOverrides(&c)
 			if isGoogleConnection(c.ConnectorDetails.Name) {
 				for _, configVar := range c.ConfigVariables {
-					if configVar.Key == "project_id" {
+					if configVar.Key == "project_id" && configVar.StringValue != nil {
 						*configVar.StringValue = "$PROJECT_ID$"
 					}
 				}
@@ -827,14 +1518,153 @@ func Get(name string, view string, minimal bool, overrides bool) (respBody []byt
 	return respBody, err
 }
 
+// redactedValue replaces secret material in logged connections
+const redactedValue = "REDACTED"
+
+// getConnectionLogBody returns the connection in content with its secret material
+// redacted. Connection bodies must only be logged through it
+func getConnectionLogBody(content []byte) string {
+	c := connectionRequest{}
+	if err := json.Unmarshal(content, &c); err != nil {
+		return string(apiclient.RedactSecrets(content))
+	}
+	body, err := json.Marshal(redactConnection(c))
+	if err != nil {
+		return string(apiclient.RedactSecrets(content))
+	}
+	// also catch secret looking config variables that hold plain strings
+	return string(apiclient.RedactSecrets(body))
+}
+
+// redactConnection returns a copy of the connection with the secret versions, passwords,
+// client secrets, keys and certificates replaced, including inline secret values
+func redactConnection(c connectionRequest) connectionRequest {
+	// copy through json, so the pointers of c are not shared with the result
+	body, _ := json.Marshal(c)
+	r := connectionRequest{}
+	_ = json.Unmarshal(body, &r)
+
+	if r.ConfigVariables != nil {
+		redactConfigVars(*r.ConfigVariables)
+	}
+	redactAuthConfig(r.AuthConfig)
+	if r.SslConfig != nil {
+		redactCertificate(r.SslConfig.PrivateServerCertificate)
+		redactCertificate((*privateServerCertificate)(r.SslConfig.ClientCertificate))
+		redactCertificate((*privateServerCertificate)(r.SslConfig.ClientPrivateKey))
+		redactCertificate((*privateServerCertificate)(r.SslConfig.ClientPrivateKeyPass))
+		if r.SslConfig.AdditionalVariables != nil {
+			redactConfigVars(*r.SslConfig.AdditionalVariables)
+		}
+	}
+	if r.EventingConfig != nil {
+		redactAuthConfig(r.EventingConfig.AuthConfig)
+		redactAuthConfig(r.EventingConfig.ListenerAuthConfig)
+		for index := range r.EventingConfig.AdditionalVariables {
+			if sv := r.EventingConfig.AdditionalVariables[index].SecretValue; sv != nil && sv.SecretVersion != nil {
+				*sv.SecretVersion = redactedValue
+			}
+		}
+	}
+	return r
+}
+
+func redactAuthConfig(a *authConfig) {
+	if a == nil {
+		return
+	}
+	if a.UserPassword != nil {
+		redactSecret(a.UserPassword.Password, a.UserPassword.PasswordDetails)
+	}
+	if a.Oauth2JwtBearer != nil {
+		redactSecret(a.Oauth2JwtBearer.ClientKey, a.Oauth2JwtBearer.ClientKeyDetails)
+	}
+	if a.Oauth2ClientCredentials != nil {
+		redactSecret(a.Oauth2ClientCredentials.ClientSecret, a.Oauth2ClientCredentials.ClientSecretDetails)
+	}
+	if a.SshPublicKey != nil {
+		redactSecret(a.SshPublicKey.Password, a.SshPublicKey.PasswordDetails)
+		redactSecret(a.SshPublicKey.SshClientCert, a.SshPublicKey.SshClientCertDetails)
+		redactSecret(a.SshPublicKey.SslClientCertPass, a.SshPublicKey.SslClientCertPassDetails)
+	}
+	if a.AdditionalVariables != nil {
+		redactConfigVars(*a.AdditionalVariables)
+	}
+}
+
+func redactConfigVars(configVars []configVar) {
+	for index := range configVars {
+		redactSecret(configVars[index].SecretValue, configVars[index].SecretDetails)
+	}
+}
+
+func redactSecret(s *secret, d *secretDetails) {
+	if s != nil && s.SecretVersion != "" {
+		s.SecretVersion = redactedValue
+	}
+	if d != nil && d.Value != "" {
+		d.Value = redactedValue
+	}
+}
+
+// redactCertificate redacts an ssl config certificate. The certificate types share
+// the same fields, so the others convert to privateServerCertificate
+func redactCertificate(c *privateServerCertificate) {
+	if c == nil {
+		return
+	}
+	if c.SecretVersion != nil {
+		*c.SecretVersion = redactedValue
+	}
+	if c.SecretDetails != nil && c.SecretDetails.Value != "" {
+		c.SecretDetails.Value = redactedValue
+	}
+}
+
+// setSecretDetailsOverrides replaces the secret versions of the config variables and
+// eventing auth configs with secret details, so the connection can be imported elsewhere
+func setSecretDetailsOverrides(c *connection) {
+	setConfigVarSecretDetails(c.ConfigVariables)
+	if c.AuthConfig.AdditionalVariables != nil {
+		setConfigVarSecretDetails(*c.AuthConfig.AdditionalVariables)
+	}
+	if c.EventingConfig == nil {
+		return
+	}
+	for _, a := range []*authConfig{c.EventingConfig.AuthConfig, c.EventingConfig.ListenerAuthConfig} {
+		if a == nil || a.UserPassword == nil || a.UserPassword.Password == nil ||
+			!isSecretVersionPath(a.UserPassword.Password.SecretVersion) {
+			continue
+		}
+		a.UserPassword.PasswordDetails = new(secretDetails)
+		a.UserPassword.PasswordDetails.SecretName = strings.Split(a.UserPassword.Password.SecretVersion, "/")[3]
+		a.UserPassword.Password = nil
+	}
+}
+
+// setConfigVarSecretDetails replaces the secret versions of the config variables with the secret names
+func setConfigVarSecretDetails(configVars []configVar) {
+	for index := range configVars {
+		if sv := configVars[index].SecretValue; sv != nil && isSecretVersionPath(sv.SecretVersion) {
+			configVars[index].SecretDetails = new(secretDetails)
+			configVars[index].SecretDetails.SecretName = strings.Split(sv.SecretVersion, "/")[3]
+			configVars[index].SecretValue = nil
+		}
+	}
+}
+
 // Get Connection details With region
 func GetConnectionDetailWithRegion(name string, region string, view string, minimal bool, overrides bool) (respBody []byte, err error) {
 	var connectionPayload []byte
+	if err = ValidateView(view); err != nil {
+		return nil, err
+	}
 	u, _ := url.Parse(apiclient.GetBaseConnectorURLWithRegion(region))
 	q := u.Query()
 	if view != "" {
 		q.Set("view", view)
 	}
+	u.RawQuery = q.Encode()
 	u.Path = path.Join(u.Path, name)
 
 	if minimal {
@@ -863,6 +1693,7 @@ func GetConnectionDetailWithRegion(name string, region string, view string, mini
 
 		c.ConnectorVersion = nil
 		c.Name = nil
+		c.Status = nil
 		if overrides {
 			switch c.AuthConfig.AuthType {
 			case "USER_PASSWORD":
@@ -880,9 +1711,10 @@ func GetConnectionDetailWithRegion(name string, region string, view string, mini
 					c.AuthConfig.Oauth2JwtBearer.ClientKey = nil
 				}
 			}
+			setSecretDetailsOverrides(&c)
 			if isGoogleConnection(c.ConnectorDetails.Name) {
 				for _, configVar := range c.ConfigVariables {
-					if configVar.Key == "project_id" {
+					if configVar.Key == "project_id" && configVar.StringValue != nil {
 						*configVar.StringValue = "$PROJECT_ID$"
 					}
 				}
@@ -928,7 +1760,16 @@ func GetConnectionDetailWithRegion(name string, region string, view string, mini
 
 // List
 func List(pageSize int, pageToken string, filter string, orderBy string) (respBody []byte, err error) {
-	u, _ := url.Parse(apiclient.GetBaseConnectorURL())
+	return list(apiclient.GetBaseConnectorURL(), pageSize, pageToken, filter, orderBy)
+}
+
+// list lists a page of the connections at connectionsURL
+func list(connectionsURL string, pageSize int, pageToken string, filter string, orderBy string,
+) (respBody []byte, err error) {
+	if err = ValidateOrderBy(orderBy); err != nil {
+		return nil, err
+	}
+	u, _ := url.Parse(connectionsURL)
 	q := u.Query()
 	if pageSize != -1 {
 		q.Set("pageSize", strconv.Itoa(pageSize))
@@ -948,76 +1789,1301 @@ func List(pageSize int, pageToken string, filter string, orderBy string) (respBo
 	return respBody, err
 }
 
-func Patch(name string, content []byte, updateMask []string) (respBody []byte, err error) {
-	c := connectionRequest{}
-	if err = json.Unmarshal(content, &c); err != nil {
+// ListAll lists all connections in the region, following page tokens
+func ListAll(filter string, orderBy string) (respBody []byte, err error) {
+	apiclient.ClientPrintHttpResponse.Set(false)
+	l := listconnections{}
+	l.Connections, err = listAllConnections(filter, orderBy)
+	apiclient.ClientPrintHttpResponse.Set(apiclient.GetCmdPrintHttpResponseSetting())
+	if err != nil {
 		return nil, err
 	}
-
-	u, _ := url.Parse(apiclient.GetBaseConnectorURL())
-
-	if len(updateMask) != 0 {
-		updates := strings.Join(updateMask, ",")
-		q := u.Query()
-		q.Set("updateMask", updates)
-		u.RawQuery = q.Encode()
+	if respBody, err = json.Marshal(l); err != nil {
+		return nil, err
 	}
+	return respBody, apiclient.PrettyPrint(respBody)
+}
+
+// ListByState lists all connections in the region that are in state, following page
+// tokens. The list API can't filter by state, so the connections are filtered locally
+func ListByState(state string, filter string, orderBy string) (respBody []byte, err error) {
+	if err = ValidateState(state); err != nil {
+		return nil, err
+	}
+	apiclient.ClientPrintHttpResponse.Set(false)
+	l := listconnections{}
+	l.Connections, err = listAllConnections(filter, orderBy)
+	apiclient.ClientPrintHttpResponse.Set(apiclient.GetCmdPrintHttpResponseSetting())
+	if err != nil {
+		return nil, err
+	}
+	l.Connections = filterConnectionsByState(l.Connections, state)
+	if respBody, err = json.Marshal(l); err != nil {
+		return nil, err
+	}
+	return respBody, apiclient.PrettyPrint(respBody)
+}
+
+// Count prints the number of connections in the regions that match the filter
+func Count(regions []string, filter string) (count int, err error) {
+	apiclient.ClientPrintHttpResponse.Set(false)
+	defer apiclient.ClientPrintHttpResponse.Set(apiclient.GetCmdPrintHttpResponseSetting())
+
+	for _, region := range regions {
+		lconnections, err := listAllConnectionsAt(apiclient.GetBaseConnectorURLWithRegion(region), filter, "")
+		if err != nil {
+			return 0, fmt.Errorf("%s: %w", region, err)
+		}
+		count += len(lconnections)
+	}
+	clilog.HTTPResponse.Println(count)
+	return count, nil
+}
+
+// listAllConnections lists the connections of all pages. The same filter and orderBy
+// are sent with every page, so the order is kept across pages
+func listAllConnections(filter string, orderBy string) (connections []connection, err error) {
+	return listAllConnectionsAt(apiclient.GetBaseConnectorURL(), filter, orderBy)
+}
+
+// listAllConnectionsAt lists the connections of all pages at connectionsURL, the
+// connections of a project and region
+func listAllConnectionsAt(connectionsURL string, filter string, orderBy string) (connections []connection, err error) {
+	if err = ValidateOrderBy(orderBy); err != nil {
+		return nil, err
+	}
+	pageToken := ""
+
+	for {
+		l := listconnections{}
+		respBody, err := list(connectionsURL, getListPageSize(), pageToken, filter, orderBy)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch connections: %w", err)
+		}
+		err = json.Unmarshal(respBody, &l)
+		if err != nil {
+			return nil, fmt.Errorf("failed to unmarshall: %w", err)
+		}
+		connections = append(connections, l.Connections...)
+		pageToken = l.NextPageToken
+		if l.NextPageToken == "" {
+			break
+		}
+	}
+	return connections, nil
+}
+
+// Patch updates the fields of the connection in updateMask. Destination configs are
+// merged by key with the ones already set on the connection
+func Patch(name string, content []byte, updateMask []string, wait bool) (respBody []byte, err error) {
+	c := connectionRequest{}
+	if err = json.Unmarshal(content, &c); err != nil {
+		return nil, err
+	}
+
+	if c.DestinationConfigs != nil && slices.Contains(updateMask, "destinationConfigs") {
+		apiclient.ClientPrintHttpResponse.Set(false)
+		existing, err := Get(name, "", false, false, false)
+		apiclient.ClientPrintHttpResponse.Set(apiclient.GetCmdPrintHttpResponseSetting())
+		if err != nil {
+			return nil, err
+		}
+		if content, err = mergeExistingDestinationConfigs(existing, content); err != nil {
+			return nil, err
+		}
+	}
+
+	return patch(name, content, updateMask, wait)
+}
+
+// patch sends content as is to the connection
+func patch(name string, content []byte, updateMask []string, wait bool) (respBody []byte, err error) {
+	start := time.Now()
+	defer func() { emitTelemetry("patch", name, content, start, err) }()
+
+	u, _ := url.Parse(apiclient.GetBaseConnectorURL())
+
+	if len(updateMask) != 0 {
+		updates := strings.Join(updateMask, ",")
+		q := u.Query()
+		q.Set("updateMask", updates)
+		u.RawQuery = q.Encode()
+	}
+
+	u.Path = path.Join(u.Path, name)
+
+	if respBody, err = apiclient.HttpClient(u.String(), string(content), "PATCH"); err != nil {
+		return nil, err
+	}
+	if wait {
+		if _, err = waitForOperation(respBody); err != nil {
+			return nil, err
+		}
+	}
+	return respBody, nil
+}
+
+// SetServiceAccount changes the service account of the connection. Short names are
+// expanded to service accounts in the current project. When grantPermission is set,
+// the new service account is granted access to the connector resources and secrets
+func SetServiceAccount(name string, serviceAccount string, grantPermission bool) (respBody []byte, err error) {
+	serviceAccountName, serviceAccountProject := splitServiceAccount(serviceAccount, "")
+	if serviceAccountProject == "" {
+		serviceAccountProject = apiclient.GetProjectID()
+	}
+	serviceAccount = fmt.Sprintf("%s@%s.iam.gserviceaccount.com", serviceAccountName, serviceAccountProject)
+	if err = apiclient.ValidateServiceAccount(serviceAccount); err != nil {
+		return nil, err
+	}
+
+	if grantPermission {
+		c, err := GetConnection(name)
+		if err != nil {
+			return nil, err
+		}
+
+		if c.ConnectorVersion != nil {
+			if err = grantConnectorPermissions(nil, getConnectorName(*c.ConnectorVersion),
+				c.ConfigVariables, serviceAccount, false); err != nil {
+				return nil, err
+			}
+		}
+
+		if err = grantSecretAccess(c, serviceAccount, false); err != nil {
+			return nil, err
+		}
+	}
+
+	c := connectionRequest{ServiceAccount: &serviceAccount}
+	content, err := json.Marshal(c)
+	if err != nil {
+		return nil, err
+	}
+	return Patch(name, content, []string{"serviceAccount"}, false)
+}
+
+// GrantSecretAccess grants the service account of the connection access to every
+// secret referenced by the connection, without changing the connection
+func GrantSecretAccess(name string) (err error) {
+	apiclient.ClientPrintHttpResponse.Set(false)
+	respBody, err := Get(name, "", false, false, false)
+	apiclient.ClientPrintHttpResponse.Set(apiclient.GetCmdPrintHttpResponseSetting())
+	if err != nil {
+		return err
+	}
+
+	c, r := connection{}, connectionRequest{}
+	if err = json.Unmarshal(respBody, &c); err != nil {
+		return fmt.Errorf("failed to unmarshall: %w", err)
+	}
+	if err = json.Unmarshal(respBody, &r); err != nil {
+		return fmt.Errorf("failed to unmarshall: %w", err)
+	}
+	if r.ServiceAccount == nil || *r.ServiceAccount == "" {
+		return fmt.Errorf("connection %s does not have a service account", name)
+	}
+
+	return grantSecretAccess(c, *r.ServiceAccount, true)
+}
+
+// grantSecretAccess grants the service account access to the secrets referenced by the connection
+func grantSecretAccess(c connection, serviceAccount string, strictIAM bool) (err error) {
+	for _, secretVersion := range getSecretVersions(c) {
+		parts := strings.Split(secretVersion, "/")
+		iamErr := apiclient.SetSecretManagerIAMPermission(parts[1], parts[3], serviceAccount)
+		if err = handleIAMError(iamErr, strictIAM); err != nil {
+			return err
+		}
+		if iamErr == nil {
+			clilog.Info.Printf("Granted %s access to secret %s\n", serviceAccount, strings.Join(parts[:4], "/"))
+		}
+	}
+	return nil
+}
+
+// RotateSecret adds a new version to the secret referenced by the connection's
+// auth config and repoints the connection to it
+func RotateSecret(name string, secretFile string, encryptionKey string, localKeyFile string,
+) (respBody []byte, err error) {
+	payload, err := readSecretFile(secretFile)
+	if err != nil {
+		return nil, err
+	}
+
+	if payload, err = decryptSecretPayload(payload, encryptionKey, localKeyFile); err != nil {
+		return nil, err
+	}
+
+	c, err := GetConnection(name)
+	if err != nil {
+		return nil, err
+	}
+
+	var s *secret
+	switch c.AuthConfig.AuthType {
+	case "USER_PASSWORD":
+		if c.AuthConfig.UserPassword != nil {
+			s = c.AuthConfig.UserPassword.Password
+		}
+	case "OAUTH2_JWT_BEARER":
+		if c.AuthConfig.Oauth2JwtBearer != nil {
+			s = c.AuthConfig.Oauth2JwtBearer.ClientKey
+		}
+	case "OAUTH2_CLIENT_CREDENTIALS":
+		if c.AuthConfig.Oauth2ClientCredentials != nil {
+			s = c.AuthConfig.Oauth2ClientCredentials.ClientSecret
+		}
+	default:
+		return nil, fmt.Errorf("rotating secrets for auth type %s is not supported", c.AuthConfig.AuthType)
+	}
+
+	if s == nil || !isSecretVersionPath(s.SecretVersion) {
+		return nil, fmt.Errorf("connection %s does not reference a secret version", name)
+	}
+
+	parts := strings.Split(s.SecretVersion, "/")
+	if s.SecretVersion, err = secmgr.AddVersion(parts[1], parts[3], payload); err != nil {
+		return nil, err
+	}
+	clilog.Info.Printf("Created secret version %s\n", s.SecretVersion)
+
+	content, err := json.Marshal(connectionRequest{AuthConfig: &c.AuthConfig})
+	if err != nil {
+		return nil, err
+	}
+
+	return Patch(name, content, []string{"authConfig"}, false)
+}
+
+// PatchLogConfig patches only the logConfig field of a connection
+func PatchLogConfig(name string, enabled bool, level string) (respBody []byte, err error) {
+	switch level {
+	case "", "ERROR", "INFO", "DEBUG":
+	default:
+		return nil, fmt.Errorf("invalid log level %s, must be one of ERROR, INFO or DEBUG", level)
+	}
+
+	content, err := json.Marshal(connectionRequest{LogConfig: &logConfig{Enabled: enabled, Level: level}})
+	if err != nil {
+		return nil, err
+	}
+
+	return Patch(name, content, []string{"logConfig"}, false)
+}
+
+// patchDestinationConfigs patches only the destinationConfigs field. Patch merges the
+// configs with the ones already set on the connection by key
+func patchDestinationConfigs(name string, configs []destinationConfig) (respBody []byte, err error) {
+	content, err := json.Marshal(connectionRequest{DestinationConfigs: &configs})
+	if err != nil {
+		return nil, err
+	}
+
+	return Patch(name, content, []string{"destinationConfigs"}, false)
+}
+
+// PatchDestinations merges the JSON array of destination configs in content into the connection
+func PatchDestinations(name string, content []byte) (respBody []byte, err error) {
+	configs := []destinationConfig{}
+	if err = json.Unmarshal(content, &configs); err != nil {
+		return nil, fmt.Errorf("destination configs must be a JSON array: %w", err)
+	}
+	if err = validateDestinationConfigs(connectionRequest{DestinationConfigs: &configs}); err != nil {
+		return nil, err
+	}
+	return patchDestinationConfigs(name, configs)
+}
+
+// mergeDestinationConfigs replaces the destination configs in current with the ones
+// in updates that have the same key and appends the rest
+func mergeDestinationConfigs(current []destinationConfig, updates []destinationConfig) []destinationConfig {
+	merged := append([]destinationConfig{}, current...)
+	for _, update := range updates {
+		found := false
+		for index := range merged {
+			if merged[index].Key == update.Key {
+				merged[index] = update
+				found = true
+				break
+			}
+		}
+		if !found {
+			merged = append(merged, update)
+		}
+	}
+	return merged
+}
+
+// mergeExistingDestinationConfigs merges the destination configs of the desired connection
+// with the ones of the existing connection by key. Other fields of desired are unchanged
+func mergeExistingDestinationConfigs(existing []byte, desired []byte) ([]byte, error) {
+	d := map[string]interface{}{}
+	if err := json.Unmarshal(desired, &d); err != nil {
+		return nil, err
+	}
+	if _, ok := d["destinationConfigs"]; !ok {
+		return desired, nil
+	}
+
+	e := connection{}
+	if err := json.Unmarshal(existing, &e); err != nil {
+		return nil, err
+	}
+	c := connectionRequest{}
+	if err := json.Unmarshal(desired, &c); err != nil {
+		return nil, err
+	}
+	if c.DestinationConfigs == nil {
+		return desired, nil
+	}
+
+	d["destinationConfigs"] = mergeDestinationConfigs(e.DestinationConfig, *c.DestinationConfigs)
+	return json.Marshal(d)
+}
+
+// PatchConfigVars merges the provided config variables with the ones already
+// set on the connection and patches only the configVariables field
+func PatchConfigVars(name string, vars map[string]interface{}) (respBody []byte, err error) {
+	c, err := GetConnection(name)
+	if err != nil {
+		return nil, err
+	}
+
+	configVars := c.ConfigVariables
+	for key, value := range vars {
+		found := false
+		for index := range configVars {
+			if configVars[index].Key == key {
+				if err = setConfigVarValue(&configVars[index], value); err != nil {
+					return nil, err
+				}
+				found = true
+				break
+			}
+		}
+		if !found {
+			cv := configVar{Key: key}
+			if err = setConfigVarValue(&cv, value); err != nil {
+				return nil, err
+			}
+			configVars = append(configVars, cv)
+		}
+	}
+
+	content, err := json.Marshal(connectionRequest{ConfigVariables: &configVars})
+	if err != nil {
+		return nil, err
+	}
+
+	return Patch(name, content, []string{"configVariables"}, false)
+}
+
+// setConfigVarValue sets the typed value on the config variable based on the
+// type of the value, clearing any previous value
+func setConfigVarValue(cv *configVar, value interface{}) error {
+	cv.IntValue, cv.BoolValue, cv.StringValue = nil, nil, nil
+	cv.SecretValue, cv.SecretDetails = nil, nil
+
+	switch v := value.(type) {
+	case string:
+		cv.StringValue = new(string)
+		*cv.StringValue = v
+	case bool:
+		cv.BoolValue = new(bool)
+		*cv.BoolValue = v
+	case int:
+		cv.IntValue = new(string)
+		*cv.IntValue = strconv.Itoa(v)
+	case float64:
+		if v != float64(int64(v)) {
+			return fmt.Errorf("config variable %s must be an integer", cv.Key)
+		}
+		cv.IntValue = new(string)
+		*cv.IntValue = strconv.FormatInt(int64(v), 10)
+	default:
+		return fmt.Errorf("unsupported value type %T for config variable %s", value, cv.Key)
+	}
+	return nil
+}
+
+// AddLabel sets the label key to value on the connection and patches only the
+// labels field, other labels on the connection are preserved
+func AddLabel(name string, key string, value string) (respBody []byte, err error) {
+	if key == "" {
+		return nil, fmt.Errorf("label key must not be empty")
+	}
+	c, err := GetConnection(name)
+	if err != nil {
+		return nil, err
+	}
+
+	labels := map[string]string{}
+	for k, v := range c.Labels {
+		labels[k] = v
+	}
+	labels[key] = value
+	return patchLabels(name, labels)
+}
+
+// RemoveLabel removes the label key from the connection and patches only the
+// labels field, other labels on the connection are preserved
+func RemoveLabel(name string, key string) (respBody []byte, err error) {
+	c, err := GetConnection(name)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := c.Labels[key]; !ok {
+		return nil, fmt.Errorf("label %s is not set on connection %s", key, name)
+	}
+
+	labels := map[string]string{}
+	for k, v := range c.Labels {
+		if k != key {
+			labels[k] = v
+		}
+	}
+	return patchLabels(name, labels)
+}
+
+// patchLabels replaces the labels of the connection. An empty map clears them
+func patchLabels(name string, labels map[string]string) (respBody []byte, err error) {
+	content, err := json.Marshal(connectionRequest{Labels: &labels})
+	if err != nil {
+		return nil, err
+	}
+	return Patch(name, content, []string{"labels"}, false)
+}
+
+// Find lists all connections and prints the names of the ones with a config variable
+// or destination matching key whose value contains value. An empty key matches any key
+func Find(key string, value string) (names []string, err error) {
+	apiclient.ClientPrintHttpResponse.Set(false)
+	lconnections, err := listAllConnections("", "")
+	apiclient.ClientPrintHttpResponse.Set(apiclient.GetCmdPrintHttpResponseSetting())
+	if err != nil {
+		return nil, err
+	}
+
+	for _, c := range lconnections {
+		if connectionMatches(c, key, value) {
+			names = append(names, getConnectionName(*c.Name))
+		}
+	}
+
+	for _, name := range names {
+		clilog.HTTPResponse.Println(name)
+	}
+	return names, nil
+}
+
+// connectionMatches checks the config variables and destinations of a connection
+func connectionMatches(c connection, key string, value string) bool {
+	for _, cv := range c.ConfigVariables {
+		if key != "" && cv.Key != key {
+			continue
+		}
+		if cv.StringValue != nil && strings.Contains(*cv.StringValue, value) {
+			return true
+		}
+		if cv.IntValue != nil && strings.Contains(*cv.IntValue, value) {
+			return true
+		}
+		if cv.BoolValue != nil && strings.Contains(strconv.FormatBool(*cv.BoolValue), value) {
+			return true
+		}
+	}
+	for _, dc := range c.DestinationConfig {
+		for _, d := range dc.Destinations {
+			if key == "" || key == dc.Key || key == "host" {
+				if strings.Contains(d.Host, value) {
+					return true
+				}
+			}
+			if key == "" || key == dc.Key || key == "serviceAttachment" {
+				if d.ServiceAttachment != "" && strings.Contains(d.ServiceAttachment, value) {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// PrintFields prints the requested dot-path fields of a connection, or of each
+// connection in a list response, as JSON lines
+func PrintFields(respBody []byte, fields []string) (err error) {
+	var lines [][]byte
+	if lines, err = selectFields(respBody, fields); err != nil {
+		return err
+	}
+	for _, line := range lines {
+		clilog.HTTPResponse.Println(string(line))
+	}
+	return nil
+}
+
+// selectFields projects the dot-path fields from a connection or a list of connections
+func selectFields(respBody []byte, fields []string) (lines [][]byte, err error) {
+	var payload map[string]interface{}
+	if err = json.Unmarshal(respBody, &payload); err != nil {
+		return nil, err
+	}
+
+	// an empty list of connections is returned as an empty object
+	items := []interface{}{payload}
+	if list, ok := payload["connections"].([]interface{}); ok {
+		items = list
+	} else if _, ok := payload["nextPageToken"]; ok || len(payload) == 0 {
+		items = nil
+	}
+
+	for _, item := range items {
+		projection := make(map[string]interface{})
+		for _, field := range fields {
+			if value, ok := lookupField(item, strings.Split(field, ".")); ok {
+				projection[field] = value
+			}
+		}
+		line, err := json.Marshal(projection)
+		if err != nil {
+			return nil, err
+		}
+		lines = append(lines, line)
+	}
+	return lines, nil
+}
+
+// lookupField walks a parsed JSON document following the path elements
+func lookupField(item interface{}, elements []string) (interface{}, bool) {
+	for _, element := range elements {
+		m, ok := item.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		if item, ok = m[element]; !ok {
+			return nil, false
+		}
+	}
+	return item, true
+}
+
+// handleIAMError returns the IAM grant error when strictIAM is set, otherwise
+// the failure is logged as a warning and the create continues
+func handleIAMError(err error, strictIAM bool) error {
+	if err == nil {
+		return nil
+	}
+	if strictIAM {
+		return fmt.Errorf("unable to update permissions for the service account: %w", err)
+	}
+	clilog.Warning.Printf("Unable to update permissions for the service account: %v\n", err)
+	return nil
+}
+
+// createSecretVersion creates or updates the secret and reports if a new version was added
+func (p *createPlan) createSecretVersion(secretName string, payload []byte,
+	updateSecrets bool,
+) (secretVersion string, err error) {
+	if p != nil {
+		return p.addSecret(secretName), nil
+	}
+	secretVersion, created, err := secmgr.Upsert(apiclient.GetProjectID(), secretName, payload, updateSecrets)
+	if err != nil {
+		return "", err
+	}
+	if created {
+		clilog.Info.Printf("Created secret version %s\n", secretVersion)
+	} else {
+		clilog.Info.Printf("Secret %s exists, using version %s\n", secretName, secretVersion)
+	}
+	return secretVersion, nil
+}
+
+// getSecretPayload returns the inline value of the secret, or the content of the
+// reference file decrypted with the Cloud KMS key when one is passed
+func getSecretPayload(details *secretDetails, encryptionKey string, localKeyFile string) (payload []byte, err error) {
+	if details.Value != "" {
+		return []byte(details.Value), nil
+	}
+	if payload, err = readSecretFile(details.Reference); err != nil {
+		return nil, err
+	}
+	return decryptSecretPayload(payload, encryptionKey, localKeyFile)
+}
+
+// decryptSecretPayload decrypts the secret file payload with the local key file when one
+// is passed, or with the Cloud KMS key when one is passed. Otherwise the payload is returned
+func decryptSecretPayload(payload []byte, encryptionKey string, localKeyFile string) ([]byte, error) {
+	if localKeyFile != "" {
+		return cloudkms.DecryptLocal(localKeyFile, payload)
+	}
+	// check if a Cloud KMS key was passsed, assume the file is encrypted
+	if encryptionKey != "" {
+		encryptionKey := getKMSKeyName(encryptionKey)
+		return cloudkms.DecryptSymmetric(encryptionKey, payload)
+	}
+	return payload, nil
+}
+
+// getKMSKeyName returns the full name of the Cloud KMS key. Keys given as
+// locations/*/keyRings/*/cryptoKeys/* are in the current project, a key given with its
+// projects/* prefix can be in another project, like the one a backup was exported from
+func getKMSKeyName(encryptionKey string) string {
+	if strings.HasPrefix(encryptionKey, "projects/") {
+		return encryptionKey
+	}
+	return path.Join("projects", apiclient.GetProjectID(), encryptionKey)
+}
+
+func readSecretFile(name string) (payload []byte, err error) {
+	if _, err := os.Stat(name); os.IsNotExist(err) {
+		return nil, fmt.Errorf("unable to open secret file %s, err: %w", name, err)
+	}
+
+	content, err := os.ReadFile(name)
+	if err != nil {
+		return nil, err
+	}
+	return content, nil
+}
+
+// ValidateConnectionName checks the name is a valid connection id
+func ValidateConnectionName(name string) error {
+	if !connectionNameRegex.MatchString(name) {
+		return fmt.Errorf("invalid connection name %q: must start with a lowercase letter, "+
+			"contain only lowercase letters, digits or hyphens, not end with a hyphen "+
+			"and be at most 63 characters", name)
+	}
+	return nil
+}
+
+// SanitizeConnectionName converts name into a valid connection id
+func SanitizeConnectionName(name string) string {
+	sanitized := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9', r == '-':
+			return r
+		case r >= 'A' && r <= 'Z':
+			return r + ('a' - 'A')
+		default:
+			return '-'
+		}
+	}, name)
+	sanitized = strings.TrimLeft(sanitized, "-0123456789")
+	if len(sanitized) > 63 {
+		sanitized = sanitized[:63]
+	}
+	return strings.TrimRight(sanitized, "-")
+}
+
+// getImportConnectionName returns the connection name for an import file. The
+// name is taken from the optional connectionName field in the file, falling back
+// to the file name
+func getImportConnectionName(file string, content []byte, sanitize bool) (string, error) {
+	name, err := deriveConnectionName(file, content)
+	if err != nil {
+		return "", err
+	}
+
+	if sanitize {
+		if sanitized := SanitizeConnectionName(name); sanitized != name {
+			clilog.Warning.Printf("connection name %s was changed to %s\n", name, sanitized)
+			name = sanitized
+		}
+	}
+
+	if err := ValidateConnectionName(name); err != nil {
+		return "", fmt.Errorf("%s: %w", file, err)
+	}
+	return name, nil
+}
+
+// deriveConnectionName returns the connectionName field of the connection file,
+// or the file name without extension when it is not set
+func deriveConnectionName(file string, content []byte) (string, error) {
+	n := struct {
+		ConnectionName string `json:"connectionName,omitempty"`
+	}{}
+	if err := json.Unmarshal(content, &n); err != nil {
+		return "", fmt.Errorf("unable to parse %s: %w", file, err)
+	}
+
+	if n.ConnectionName != "" {
+		return n.ConnectionName, nil
+	}
+	return strings.TrimSuffix(filepath.Base(file), filepath.Ext(filepath.Base(file))), nil
+}
+
+// checkDuplicateConnectionNames returns an error listing the files of every connection
+// name that is derived from more than one file. Files that can't be read are skipped
+// here and reported by the import
+func checkDuplicateConnectionNames(files []string, defaults map[string]interface{}, sanitize bool) error {
+	paths := make(map[string][]string)
+	for _, file := range files {
+		content, err := readConnectionFile(file, defaults)
+		if err != nil {
+			continue
+		}
+		name, err := deriveConnectionName(file, content)
+		if err != nil {
+			continue
+		}
+		if sanitize {
+			name = SanitizeConnectionName(name)
+		}
+		paths[name] = append(paths[name], file)
+	}
+
+	duplicates := []string{}
+	for name, files := range paths {
+		if len(files) > 1 {
+			duplicates = append(duplicates, fmt.Sprintf("%s: %s", name, strings.Join(files, ", ")))
+		}
+	}
+	if len(duplicates) == 0 {
+		return nil
+	}
+	sort.Strings(duplicates)
+	return fmt.Errorf("connection names derived from more than one file:\n%s", strings.Join(duplicates, "\n"))
+}
+
+// getConnectionFiles returns the connection files in folder and its sub folders,
+// skipping the defaults file and the export manifest
+func getConnectionFiles(folder string) (files []string, err error) {
+	err = filepath.Walk(folder, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			clilog.Warning.Println("connection folder not found")
+			return nil
+		}
+		if info.IsDir() {
+			if filepath.Base(path) == endpointsFolderName {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if filepath.Ext(path) != ".json" || filepath.Base(path) == defaultsFileName ||
+			filepath.Base(path) == manifestFileName {
+			return nil
+		}
+		files = append(files, path)
+		return nil
+	})
+	return files, err
+}
+
+// Import creates the connections in folder that don't exist. With prune, the live
+// connections that no file in folder imports are deleted once every file is imported;
+// pruneDryRun only lists them and imports nothing
+func Import(folder string, createSecret bool, wait bool, sanitizeNames bool, updateSecrets bool,
+	valuesFile string, encryptionKey string, prune bool, force bool, pruneDryRun bool, opts CreateOptions,
+) (err error) {
+	apiclient.ClientPrintHttpResponse.Set(false)
+	defer apiclient.ClientPrintHttpResponse.Set(apiclient.GetCmdPrintHttpResponseSetting())
+	errs := []string{}
+
+	files, err := getConnectionFiles(folder)
+	if err != nil {
+		return nil
+	}
+
+	defaults, err := readDefaultsFile(folder)
+	if err != nil {
+		return err
+	}
+
+	// fail early if two files would import the same connection
+	if err = checkDuplicateConnectionNames(files, defaults, sanitizeNames); err != nil {
+		return err
+	}
+
+	var local map[string]bool
+	if prune {
+		if len(files) == 0 {
+			return fmt.Errorf("folder %s has no connection files, not pruning every connection", folder)
+		}
+		if local, err = getFolderConnectionNames(files, defaults, sanitizeNames); err != nil {
+			return fmt.Errorf("unable to prune: %w", err)
+		}
+		if pruneDryRun {
+			return pruneConnections(local, false, true, wait)
+		}
+	}
+
+	// fail early if the connections reference service accounts that don't exist
+	if err = checkServiceAccounts(files, defaults); err != nil {
+		return err
+	}
+
+	// the connections can only use endpoint attachments that exist
+	if err = importEndpoints(folder); err != nil {
+		return err
+	}
+
+	values, err := readValuesFile(valuesFile)
+	if err != nil {
+		return err
+	}
+	matched := make(map[string]bool)
+
+	summary := newRunSummary("import", len(files))
+	defer summary.print()
+
+	for _, path := range files {
+		content, err := readConnectionFile(path, defaults)
+		if err != nil {
+			errs = append(errs, err.Error())
+			summary.failed++
+			summary.progress()
+			continue
+		}
+
+		name, err := getImportConnectionName(path, content, sanitizeNames)
+		if err != nil {
+			errs = append(errs, err.Error())
+			summary.failed++
+			summary.progress()
+			continue
+		}
+
+		if content, err = applyValues(name, content, values, matched); err != nil {
+			errs = append(errs, err.Error())
+			summary.failed++
+			summary.progress()
+			continue
+		}
+
+		if content, err = resolveSecretReferences(content, folder); err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %v", path, err))
+			summary.failed++
+			summary.progress()
+			continue
+		}
+
+		if _, err := Get(name, "", false, false, false); err != nil { // create only if connection doesn't exist
+			clilog.Info.Printf("creating connection %s\n", name)
+			_, err = Create(name, content, "", "", encryptionKey, false, createSecret, wait, false, updateSecrets,
+				opts)
+			if err != nil {
+				errs = append(errs, err.Error())
+				summary.failed++
+			} else {
+				summary.created++
+			}
+		} else {
+			clilog.Info.Printf("connection %s already exists, skipping creations\n", name)
+			summary.skipped++
+		}
+		summary.progress()
+	}
+
+	for key := range values {
+		if !matched[key] {
+			clilog.Warning.Printf("value %s does not match a config variable of an imported connection\n", key)
+		}
+	}
+
+	if len(errs) > 0 {
+		if prune {
+			clilog.Warning.Println("not pruning connections because the import failed")
+		}
+		return errors.New(strings.Join(errs, "\n"))
+	}
+
+	if prune {
+		return pruneConnections(local, force, false, wait)
+	}
+	return nil
+}
+
+// readValuesFile reads the optional values file, a JSON object whose keys are
+// connectionName.configVarKey
+func readValuesFile(file string) (values map[string]interface{}, err error) {
+	if file == "" {
+		return nil, nil
+	}
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	if err = json.Unmarshal(content, &values); err != nil {
+		return nil, fmt.Errorf("unable to parse %s: %w", file, err)
+	}
+	return values, nil
+}
+
+// applyValues sets the config variables of the connection from the values keyed by
+// name.configVarKey and records the keys that were applied in matched
+func applyValues(name string, content []byte, values map[string]interface{},
+	matched map[string]bool,
+) ([]byte, error) {
+	if len(values) == 0 {
+		return content, nil
+	}
+	c := map[string]interface{}{}
+	if err := json.Unmarshal(content, &c); err != nil {
+		return nil, err
+	}
+	configVars, _ := c["configVariables"].([]interface{})
+
+	applied := false
+	for key, value := range values {
+		configVarKey, found := strings.CutPrefix(key, name+".")
+		if !found {
+			continue
+		}
+		for _, cv := range configVars {
+			configVar, ok := cv.(map[string]interface{})
+			if !ok || configVar["key"] != configVarKey {
+				continue
+			}
+			if err := overlayConfigVarValue(configVar, value); err != nil {
+				return nil, fmt.Errorf("value %s: %w", key, err)
+			}
+			matched[key] = true
+			applied = true
+		}
+	}
+	if !applied {
+		return content, nil
+	}
+	return json.Marshal(c)
+}
+
+// SetAuthAdditionalVariables sets the additional variables of the auth config in
+// content to vars. Existing variables keep their value type, new ones are strings
+func SetAuthAdditionalVariables(content []byte, vars map[string]string) ([]byte, error) {
+	if len(vars) == 0 {
+		return content, nil
+	}
+	c := map[string]interface{}{}
+	if err := json.Unmarshal(content, &c); err != nil {
+		return nil, err
+	}
+	a, ok := c["authConfig"].(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("authConfig must be set to add additional auth variables")
+	}
+	additionalVariables, _ := a["additionalVariables"].([]interface{})
+
+	keys := make([]string, 0, len(vars))
+	for key := range vars {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		found := false
+		for _, v := range additionalVariables {
+			if configVar, ok := v.(map[string]interface{}); ok && configVar["key"] == key {
+				if err := overlayConfigVarValue(configVar, vars[key]); err != nil {
+					return nil, fmt.Errorf("auth variable %s: %w", key, err)
+				}
+				found = true
+			}
+		}
+		if !found {
+			additionalVariables = append(additionalVariables, map[string]interface{}{
+				"key":         key,
+				"stringValue": vars[key],
+			})
+		}
+	}
+	a["additionalVariables"] = additionalVariables
+	return json.Marshal(c)
+}
+
+// SetDestinations sets the destination configs in content from destinations in the
+// format key=host:port. The destinations of a key replace the ones for that key in the
+// file, the other destination configs are kept
+func SetDestinations(content []byte, destinations []string) ([]byte, error) {
+	if len(destinations) == 0 {
+		return content, nil
+	}
+	keys := []string{}
+	destinationsByKey := make(map[string][]interface{})
+	for _, d := range destinations {
+		key, dest, err := parseDestination(d)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := destinationsByKey[key]; !ok {
+			keys = append(keys, key)
+		}
+		destinationsByKey[key] = append(destinationsByKey[key], map[string]interface{}{
+			"host": dest.Host,
+			"port": dest.Port,
+		})
+	}
+
+	c := map[string]interface{}{}
+	if err := json.Unmarshal(content, &c); err != nil {
+		return nil, err
+	}
+	destinationConfigs, _ := c["destinationConfigs"].([]interface{})
+	for _, key := range keys {
+		found := false
+		for _, v := range destinationConfigs {
+			if d, ok := v.(map[string]interface{}); ok && d["key"] == key {
+				d["destinations"] = destinationsByKey[key]
+				found = true
+			}
+		}
+		if !found {
+			destinationConfigs = append(destinationConfigs, map[string]interface{}{
+				"key":          key,
+				"destinations": destinationsByKey[key],
+			})
+		}
+	}
+	c["destinationConfigs"] = destinationConfigs
+	return json.Marshal(c)
+}
+
+// caBundleSecretSuffix is appended to the connection name for the CA bundle secret
+const caBundleSecretSuffix = "-ca-bundle"
+
+// SetCABundle sets the sslConfig in content to trust the private CA certificates in
+// caBundleFile. The bundle becomes the privateServerCertificate secret named after the
+// connection, created from the file when the connection is created with create-secret.
+// Like every secret file of the connection, the bundle is decrypted with the encryption
+// key when one is passed, so it must be encrypted with the same key as the passwords
+func SetCABundle(content []byte, name string, caBundleFile string) ([]byte, error) {
+	if caBundleFile == "" {
+		return content, nil
+	}
+	if _, err := os.Stat(caBundleFile); err != nil {
+		return nil, fmt.Errorf("unable to open CA bundle file %w", err)
+	}
+
+	c := map[string]interface{}{}
+	if err := json.Unmarshal(content, &c); err != nil {
+		return nil, err
+	}
+	ssl, _ := c["sslConfig"].(map[string]interface{})
+	if ssl == nil {
+		ssl = map[string]interface{}{}
+	}
+	ssl["useSsl"] = true
+	ssl["trustModel"] = "PRIVATE"
+	if _, ok := ssl["serverCertType"]; !ok {
+		ssl["serverCertType"] = "PEM"
+	}
+	ssl["privateServerCertificate"] = map[string]interface{}{
+		"secretDetails": map[string]interface{}{
+			"secretName": name + caBundleSecretSuffix,
+			"reference":  caBundleFile,
+		},
+	}
+	c["sslConfig"] = ssl
+	return json.Marshal(c)
+}
+
+// ValidateNodeCounts returns an error if the node counts can't be applied. A count
+// of -1 is not set; at least one must be set
+func ValidateNodeCounts(min int, max int) error {
+	if min == -1 && max == -1 {
+		return errors.New("min or max must be set")
+	}
+	if (min != -1 && min < 1) || (max != -1 && max < 1) {
+		return errors.New("min and max node counts must be at least 1")
+	}
+	if min > max && max != -1 {
+		return errors.New("min cannot be set higher than max")
+	}
+	return nil
+}
+
+// SetNodeCounts sets the node config counts of the connection in content. A count
+// of -1 keeps the value in the file
+func SetNodeCounts(content []byte, min int, max int) ([]byte, error) {
+	if min == -1 && max == -1 {
+		return content, nil
+	}
+	if err := ValidateNodeCounts(min, max); err != nil {
+		return nil, err
+	}
+	c := map[string]interface{}{}
+	if err := json.Unmarshal(content, &c); err != nil {
+		return nil, err
+	}
+	n, _ := c["nodeConfig"].(map[string]interface{})
+	if n == nil {
+		n = map[string]interface{}{}
+	}
+	if min != -1 {
+		n["minNodeCount"] = min
+	}
+	if max != -1 {
+		n["maxNodeCount"] = max
+	}
+	c["nodeConfig"] = n
+	return json.Marshal(c)
+}
 
-	u.Path = path.Join(u.Path, name)
+// PatchNodeCounts patches only the node config counts of the connection. A count
+// of -1 is left unchanged
+func PatchNodeCounts(name string, min int, max int, wait bool) (respBody []byte, err error) {
+	if err = ValidateNodeCounts(min, max); err != nil {
+		return nil, err
+	}
+	n := nodeConfig{}
+	updateMask := []string{}
+	if min != -1 {
+		n.MinNodeCount = min
+		updateMask = append(updateMask, "nodeConfig.minNodeCount")
+	}
+	if max != -1 {
+		n.MaxNodeCount = max
+		updateMask = append(updateMask, "nodeConfig.maxNodeCount")
+	}
+	content, err := json.Marshal(connectionRequest{NodeConfig: &n})
+	if err != nil {
+		return nil, err
+	}
+	return Patch(name, content, updateMask, wait)
+}
 
-	return apiclient.HttpClient(u.String(), string(content), "PATCH")
+// parseDestination parses a destination in the format key=host:port
+func parseDestination(d string) (key string, dest destination, err error) {
+	key, hostPort, ok := strings.Cut(d, "=")
+	if !ok || key == "" {
+		return "", dest, fmt.Errorf("destination %s must be in the format key=host:port", d)
+	}
+	host, port, err := net.SplitHostPort(hostPort)
+	if err != nil || host == "" {
+		return "", dest, fmt.Errorf("destination %s must be in the format key=host:port", d)
+	}
+	if dest.Port, err = strconv.Atoi(port); err != nil || dest.Port < 1 || dest.Port > 65535 {
+		return "", dest, fmt.Errorf("destination %s has an invalid port %s", d, port)
+	}
+	dest.Host = host
+	return key, dest, nil
 }
 
-func readSecretFile(name string) (payload []byte, err error) {
-	if _, err := os.Stat(name); os.IsNotExist(err) {
-		return nil, fmt.Errorf("unable to open secret file %s, err: %w", name, err)
+// overlayConfigVarValue replaces the value of the config variable, keeping its value type
+func overlayConfigVarValue(configVar map[string]interface{}, value interface{}) error {
+	switch {
+	case configVar["intValue"] != nil:
+		switch v := value.(type) {
+		case float64:
+			configVar["intValue"] = strconv.FormatInt(int64(v), 10)
+		case string:
+			configVar["intValue"] = v
+		default:
+			return fmt.Errorf("expected a number for %v", configVar["key"])
+		}
+	case configVar["boolValue"] != nil:
+		switch v := value.(type) {
+		case bool:
+			configVar["boolValue"] = v
+		case string:
+			b, err := strconv.ParseBool(v)
+			if err != nil {
+				return fmt.Errorf("expected a boolean for %v", configVar["key"])
+			}
+			configVar["boolValue"] = b
+		default:
+			return fmt.Errorf("expected a boolean for %v", configVar["key"])
+		}
+	default:
+		configVar["stringValue"] = fmt.Sprint(value)
 	}
+	return nil
+}
 
-	content, err := os.ReadFile(name)
-	if err != nil {
+// readDefaultsFile reads the optional defaults file from the import folder
+func readDefaultsFile(folder string) (defaults map[string]interface{}, err error) {
+	content, err := os.ReadFile(filepath.Join(folder, defaultsFileName))
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
 		return nil, err
 	}
-	return content, nil
+	if err = json.Unmarshal(content, &defaults); err != nil {
+		return nil, fmt.Errorf("unable to parse %s: %w", defaultsFileName, err)
+	}
+	clilog.Info.Printf("applying defaults from %s\n", defaultsFileName)
+	return defaults, nil
+}
+
+// readConnectionFile reads a connection file and applies the defaults to it
+func readConnectionFile(file string, defaults map[string]interface{}) (content []byte, err error) {
+	if content, err = os.ReadFile(file); err != nil {
+		return nil, err
+	}
+	if defaults == nil {
+		return content, nil
+	}
+	c := map[string]interface{}{}
+	if err = json.Unmarshal(content, &c); err != nil {
+		return nil, fmt.Errorf("unable to parse %s: %w", file, err)
+	}
+	return json.Marshal(mergeDefaults(defaults, c))
+}
+
+// mergeDefaults merges defaults into c. Objects are merged recursively, while
+// scalars and arrays set in c replace the default value entirely
+func mergeDefaults(defaults map[string]interface{}, c map[string]interface{}) map[string]interface{} {
+	for key, defaultValue := range defaults {
+		value, ok := c[key]
+		if !ok {
+			c[key] = defaultValue
+			continue
+		}
+		defaultMap, defaultIsMap := defaultValue.(map[string]interface{})
+		valueMap, valueIsMap := value.(map[string]interface{})
+		if defaultIsMap && valueIsMap {
+			c[key] = mergeDefaults(defaultMap, valueMap)
+		}
+	}
+	return c
 }
 
-// Import
-func Import(folder string, createSecret bool, wait bool) (err error) {
+// PatchFolder patches connections from the partial connection files in folder. The update
+// mask is read from an updateMask array in the file, from a sidecar <file>.mask file with
+// comma separated fields, or else derived from the top level fields in the file
+func PatchFolder(folder string, wait bool) (err error) {
 	apiclient.ClientPrintHttpResponse.Set(false)
 	defer apiclient.ClientPrintHttpResponse.Set(apiclient.GetCmdPrintHttpResponseSetting())
 	errs := []string{}
+	files := []string{}
 
 	err = filepath.Walk(folder, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
 			clilog.Warning.Println("connection folder not found")
 			return nil
 		}
-		if info.IsDir() {
-			return nil
-		}
-		if filepath.Ext(path) != ".json" {
+		if info.IsDir() || filepath.Ext(path) != ".json" || filepath.Base(path) == defaultsFileName ||
+			filepath.Base(path) == manifestFileName {
 			return nil
 		}
-		name := strings.TrimSuffix(filepath.Base(path), filepath.Ext(filepath.Base(path)))
-		content, err := os.ReadFile(path)
+		files = append(files, path)
+		return nil
+	})
+	if err != nil {
+		return nil
+	}
+
+	summary := newRunSummary("patch", len(files))
+	defer summary.print()
+
+	for _, file := range files {
+		name, content, updateMask, err := readPatchFile(file)
 		if err != nil {
-			return err
+			errs = append(errs, err.Error())
+			summary.failed++
+			summary.progress()
+			continue
 		}
 
-		if _, err := Get(name, "", false, false); err != nil { // create only if connection doesn't exist
-			_, err = Create(name, content, "", "", "", false, createSecret, wait)
-			if err != nil {
-				errs = append(errs, err.Error())
-			}
-			clilog.Info.Printf("creating connection %s\n", name)
+		clilog.Info.Printf("patching connection %s fields %s\n", name, strings.Join(updateMask, ","))
+		if _, err = Patch(name, content, updateMask, wait); err != nil {
+			errs = append(errs, err.Error())
+			summary.failed++
 		} else {
-			clilog.Info.Printf("connection %s already exists, skipping creations\n", name)
+			summary.patched++
 		}
-
-		return nil
-	})
-
-	if err != nil {
-		return nil
+		summary.progress()
 	}
 
 	if len(errs) > 0 {
@@ -1026,68 +3092,515 @@ func Import(folder string, createSecret bool, wait bool) (err error) {
 	return nil
 }
 
-// Export
-func Export(folder string) (err error) {
-	apiclient.SetExportToFile(folder)
-	apiclient.ClientPrintHttpResponse.Set(false)
-	defer apiclient.ClientPrintHttpResponse.Set(apiclient.GetCmdPrintHttpResponseSetting())
+// readPatchFile returns the connection name, the partial connection and the update mask of a patch file
+func readPatchFile(file string) (name string, content []byte, updateMask []string, err error) {
+	if content, err = os.ReadFile(file); err != nil {
+		return "", nil, nil, err
+	}
 
-	pageToken := ""
-	lconnections := listconnections{}
+	if name, err = getImportConnectionName(file, content, false); err != nil {
+		return "", nil, nil, err
+	}
 
-	for {
-		l := listconnections{}
-		respBody, err := List(maxPageSize, pageToken, "", "")
+	c := map[string]interface{}{}
+	if err = json.Unmarshal(content, &c); err != nil {
+		return "", nil, nil, fmt.Errorf("unable to parse %s: %w", file, err)
+	}
+
+	if mask, ok := c["updateMask"].([]interface{}); ok {
+		for _, field := range mask {
+			updateMask = append(updateMask, fmt.Sprint(field))
+		}
+	} else if mask, err := os.ReadFile(strings.TrimSuffix(file, filepath.Ext(file)) + ".mask"); err == nil {
+		for _, field := range strings.Split(strings.TrimSpace(string(mask)), ",") {
+			updateMask = append(updateMask, strings.TrimSpace(field))
+		}
+	}
+
+	delete(c, "updateMask")
+	delete(c, "connectionName")
+
+	if len(updateMask) == 0 {
+		for field := range c {
+			updateMask = append(updateMask, field)
+		}
+		sort.Strings(updateMask)
+	}
+
+	if content, err = json.Marshal(c); err != nil {
+		return "", nil, nil, err
+	}
+	return name, content, updateMask, nil
+}
+
+// checkServiceAccounts verifies, before any connection is created, that the distinct
+// service accounts the connection files run as exist and that the caller can act as
+// them. A connection without a service account runs as the default compute service
+// account of the project
+func checkServiceAccounts(files []string, defaults map[string]interface{}) error {
+	serviceAccounts := make(map[string]bool)
+	useDefault := false
+
+	for _, file := range files {
+		// unreadable files are reported when they are imported
+		content, err := readConnectionFile(file, defaults)
 		if err != nil {
-			return fmt.Errorf("failed to fetch Integrations: %w", err)
+			clilog.Warning.Printf("unable to check the service account of %s: %v\n", file, err)
+			continue
 		}
-		err = json.Unmarshal(respBody, &l)
+		c := connectionRequest{}
+		if err = json.Unmarshal(content, &c); err != nil {
+			clilog.Warning.Printf("unable to check the service account of %s: %v\n", file, err)
+			continue
+		}
+		if c.ServiceAccount != nil && *c.ServiceAccount != "" {
+			serviceAccounts[*c.ServiceAccount] = true
+		} else {
+			useDefault = true
+		}
+	}
+
+	failed := []string{}
+
+	// the default service account always exists, only the permission is checked
+	defaultServiceAccount := ""
+	if useDefault {
+		var err error
+		if defaultServiceAccount, err = apiclient.GetComputeEngineDefaultServiceAccount(
+			apiclient.GetProjectID()); err != nil {
+			failed = append(failed, fmt.Sprintf("default compute service account (%v)", err))
+		} else {
+			serviceAccounts[defaultServiceAccount] = true
+		}
+	}
+
+	mu := sync.Mutex{}
+	wg := sync.WaitGroup{}
+
+	for serviceAccount := range serviceAccounts {
+		wg.Add(1)
+		go func(serviceAccount string) {
+			defer wg.Done()
+			if reason := checkServiceAccount(serviceAccount, serviceAccount != defaultServiceAccount); reason != "" {
+				mu.Lock()
+				defer mu.Unlock()
+				failed = append(failed, fmt.Sprintf("%s (%s)", serviceAccount, reason))
+			}
+		}(serviceAccount)
+	}
+	wg.Wait()
+
+	if len(failed) > 0 {
+		sort.Strings(failed)
+		return fmt.Errorf("the following service accounts were not found or cannot be used by the caller:\n%s",
+			strings.Join(failed, "\n"))
+	}
+	return nil
+}
+
+// checkServiceAccount returns why the caller cannot create connections that run as
+// the service account, or an empty string if it can
+func checkServiceAccount(serviceAccount string, checkExists bool) string {
+	if checkExists {
+		found, err := apiclient.ServiceAccountExists(serviceAccount)
 		if err != nil {
-			return fmt.Errorf("failed to unmarshall: %w", err)
+			return err.Error()
 		}
-		lconnections.Connections = append(lconnections.Connections, l.Connections...)
-		pageToken = l.NextPageToken
-		if l.NextPageToken == "" {
-			break
+		if !found {
+			return "not found"
 		}
 	}
+	canActAs, err := apiclient.ServiceAccountCanActAs(serviceAccount)
+	if err != nil {
+		return err.Error()
+	}
+	if !canActAs {
+		return "missing iam.serviceAccounts.actAs"
+	}
+	return ""
+}
+
+// ExportConnection writes a single connection to folder as <name>.json, the file name
+// Export uses. The content is the minimal view with overrides returned by Get, so
+// secrets are replaced by their secret names and the project id by $PROJECT_ID$
+func ExportConnection(folder string, name string, launchStage bool) (err error) {
+	respBody, err := Get(name, "", true, true, launchStage)
+	if err != nil {
+		return err
+	}
+
+	fileName := name + ".json"
+	if err = apiclient.WriteByteArrayToFile(path.Join(folder, fileName), false, respBody); err != nil {
+		clilog.Error.Println(err)
+		return err
+	}
+	clilog.Info.Printf("Downloaded %s\n", fileName)
+	return nil
+}
+
+// Export writes the connections that have all the labels to folder. An empty labels
+// map exports all the connections. With onlyChanged, files that already hold the same
+// connection, ignoring formatting and key order, are not written again. With launchStage,
+// the files include the launch stage of the connector version, and with secretReferences
+// they reference the exported secret files
+func Export(folder string, labels map[string]string, onlyChanged bool, launchStage bool,
+	secretReferences bool,
+) (err error) {
+	apiclient.SetExportToFile(folder)
+	apiclient.ClientPrintHttpResponse.Set(false)
+	defer apiclient.ClientPrintHttpResponse.Set(apiclient.GetCmdPrintHttpResponseSetting())
+
+	lconnections := listconnections{}
+	if lconnections.Connections, err = listAllConnections("", ""); err != nil {
+		return err
+	}
+	lconnections.Connections = filterConnectionsByLabels(lconnections.Connections, labels)
 
 	// no connections where found
 	if len(lconnections.Connections) == 0 {
 		return nil
 	}
 
-	for _, lconnection := range lconnections.Connections {
-		lconnection.ConnectorDetails = new(connectorDetails)
-		lconnection.ConnectorDetails.Name = getConnectorName(*lconnection.ConnectorVersion)
-		if lconnection.ConnectorDetails.Provider != "customconnector" {
-			lconnection.ConnectorDetails.Version = new(int)
-			*lconnection.ConnectorDetails.Version = getConnectorVersion(*lconnection.ConnectorVersion)
-		} else {
-			lconnection.ConnectorDetails.VersionId = new(string)
-			*lconnection.ConnectorDetails.VersionId = getConnectorVersionId(*lconnection.ConnectorVersion)
+	// fail before any file is written
+	if secretReferences {
+		if err = checkSecretReferences(lconnections.Connections); err != nil {
+			return err
 		}
+	}
+
+	summary := newRunSummary("export", len(lconnections.Connections))
+	defer summary.print()
 
-		lconnection.ConnectorVersion = nil
-		fileName := getConnectionName(*lconnection.Name) + ".json"
-		lconnection.Name = nil
-		connectionPayload, err := json.Marshal(lconnection)
+	m := manifest{Labels: labels, LaunchStage: launchStage, SecretReferences: secretReferences}
+	for _, lconnection := range lconnections.Connections {
+		fileName, connectionPayload, err := getExportPayload(lconnection, launchStage, secretReferences)
 		if err != nil {
+			summary.failed++
 			return err
 		}
+		m.Connections = append(m.Connections, newManifestEntry(fileName, connectionPayload))
+		if onlyChanged && isSameJSONFile(path.Join(apiclient.GetExportToFile(), fileName), connectionPayload) {
+			clilog.Debug.Printf("Skipping %s, it is unchanged\n", fileName)
+			summary.skipped++
+			summary.progress()
+			continue
+		}
 		if err = apiclient.WriteByteArrayToFile(
 			path.Join(apiclient.GetExportToFile(), fileName),
 			false,
 			connectionPayload); err != nil {
 			clilog.Error.Println(err)
+			summary.failed++
 			return err
 		}
 		clilog.Info.Printf("Downloaded %s\n", fileName)
+		summary.created++
+		summary.progress()
+	}
+
+	return writeManifest(apiclient.GetExportToFile(), m)
+}
+
+// isSameJSONFile returns true if file holds the same JSON value as content, ignoring
+// formatting and the order of object keys
+func isSameJSONFile(file string, content []byte) bool {
+	existing, err := os.ReadFile(file)
+	if err != nil {
+		return false
+	}
+	var e, c interface{}
+	if json.Unmarshal(existing, &e) != nil || json.Unmarshal(content, &c) != nil {
+		return false
+	}
+	return reflect.DeepEqual(e, c)
+}
+
+// filterConnectionsByLabels returns the connections that have all the labels
+func filterConnectionsByLabels(lconnections []connection, labels map[string]string) []connection {
+	if len(labels) == 0 {
+		return lconnections
+	}
+	filtered := []connection{}
+	for _, lconnection := range lconnections {
+		if matchLabels(lconnection.Labels, labels) {
+			filtered = append(filtered, lconnection)
+		}
+	}
+	return filtered
+}
+
+// matchLabels reports if every key of selector is set to the same value in labels
+func matchLabels(labels map[string]string, selector map[string]string) bool {
+	for key, value := range selector {
+		if v, ok := labels[key]; !ok || v != value {
+			return false
+		}
+	}
+	return true
+}
+
+// getExportPayload returns the file name and the content Export writes for the connection
+func getExportPayload(lconnection connection, launchStage bool, secretReferences bool,
+) (fileName string, connectionPayload []byte, err error) {
+	lconnection.ConnectorDetails = new(connectorDetails)
+	lconnection.ConnectorDetails.Name = getConnectorName(*lconnection.ConnectorVersion)
+	lconnection.ConnectorDetails.Provider = getConnectorProvider(*lconnection.ConnectorVersion)
+	if lconnection.ConnectorDetails.Provider != "customconnector" {
+		lconnection.ConnectorDetails.Version = new(int)
+		*lconnection.ConnectorDetails.Version = getConnectorVersion(*lconnection.ConnectorVersion)
+	} else {
+		lconnection.ConnectorDetails.VersionId = new(string)
+		*lconnection.ConnectorDetails.VersionId = getConnectorVersionId(*lconnection.ConnectorVersion)
+	}
+
+	if launchStage {
+		setConnectorLaunchStage(lconnection.ConnectorDetails, *lconnection.ConnectorVersion)
+	}
+	if secretReferences {
+		setSecretReferences(&lconnection)
+	}
+
+	lconnection.ConnectorVersion = nil
+	lconnection.Status = nil
+	fileName = getConnectionName(*lconnection.Name) + ".json"
+	lconnection.Name = nil
+	if connectionPayload, err = json.Marshal(lconnection); err != nil {
+		return "", nil, err
+	}
+	return fileName, connectionPayload, nil
+}
+
+// ListConnectionSecrets prints the Secret Manager secrets and versions referenced by
+// the connection's auth, ssl and config variables
+func ListConnectionSecrets(name string) (secretVersions []string, err error) {
+	c, err := GetConnection(name)
+	if err != nil {
+		return nil, err
+	}
+
+	secretVersions = getSecretVersions(c)
+	rows := [][]string{}
+	for _, secretVersion := range secretVersions {
+		parts := strings.Split(secretVersion, "/")
+		rows = append(rows, []string{strings.Join(parts[:4], "/"), parts[5]})
+	}
+	apiclient.PrintTable([]string{"SECRET", "VERSION"}, rows)
+	return secretVersions, nil
+}
+
+// CopySecrets creates the secrets referenced by the connection in targetProject with the
+// payload of the referenced versions and returns the new secret version of each old one.
+// Secrets that already exist in targetProject are not changed and their latest version is
+// returned. Secret versions that can't be read are skipped with a warning
+func CopySecrets(name string, targetProject string) (secretVersions map[string]string, err error) {
+	c, err := GetConnection(name)
+	if err != nil {
+		return nil, err
+	}
+
+	errs := []string{}
+	secretVersions = make(map[string]string)
+	rows := [][]string{}
+	for _, secretVersion := range getSecretVersions(c) {
+		if _, ok := secretVersions[secretVersion]; ok {
+			continue
+		}
+		payload, err := secmgr.Access(secretVersion)
+		if err != nil {
+			clilog.Warning.Printf("unable to read %s, check the secretmanager.versions.access permission: %v\n",
+				secretVersion, err)
+			continue
+		}
+		secretId := strings.Split(secretVersion, "/")[3]
+		newVersion, err := secmgr.Create(targetProject, secretId, payload)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("unable to create secret %s in %s: %v", secretId, targetProject, err))
+			continue
+		}
+		clilog.Info.Printf("Copied %s to %s\n", secretVersion, newVersion)
+		secretVersions[secretVersion] = newVersion
+		rows = append(rows, []string{secretVersion, newVersion})
+	}
+	apiclient.PrintTable([]string{"SOURCE", "TARGET"}, rows)
+
+	if len(errs) > 0 {
+		return secretVersions, errors.New(strings.Join(errs, "\n"))
+	}
+	return secretVersions, nil
+}
+
+// ExportSecrets writes the payload of every secret version referenced by the connections
+// to folder, one file per secret. The payloads are encrypted with the Cloud KMS
+// encryptionKey, which is required, so they can be imported with the same key. The
+// files are readable by the current user only
+func ExportSecrets(folder string, encryptionKey string, labels map[string]string) (err error) {
+	apiclient.ClientPrintHttpResponse.Set(false)
+	defer apiclient.ClientPrintHttpResponse.Set(apiclient.GetCmdPrintHttpResponseSetting())
+
+	if encryptionKey == "" {
+		return fmt.Errorf("an encryption key is required, secret payloads are not written in plaintext")
+	}
+
+	lconnections, err := listAllConnections("", "")
+	if err != nil {
+		return err
+	}
+	lconnections = filterConnectionsByLabels(lconnections, labels)
+
+	secretVersions := make(map[string]bool)
+	for _, lconnection := range lconnections {
+		for _, secretVersion := range getSecretVersions(lconnection) {
+			secretVersions[secretVersion] = true
+		}
+	}
+
+	if len(secretVersions) == 0 {
+		return nil
+	}
+
+	if err = os.MkdirAll(folder, 0o755); err != nil {
+		return err
+	}
+
+	for secretVersion := range secretVersions {
+		payload, err := secmgr.Access(secretVersion)
+		if err != nil {
+			return fmt.Errorf("unable to access secret version %s: %w", secretVersion, err)
+		}
+
+		b64CipherText, err := cloudkms.EncryptSymmetric(getKMSKeyName(encryptionKey), payload)
+		if err != nil {
+			return err
+		}
+		payload = []byte(b64CipherText)
+
+		fileName := getSecretFileName(secretVersion)
+		if err = os.WriteFile(path.Join(folder, fileName), payload, 0o600); err != nil {
+			return err
+		}
+		clilog.Info.Printf("Downloaded secret %s\n", fileName)
+	}
+	return nil
+}
+
+// getSecretVersions returns the secret versions referenced by a connection
+func getSecretVersions(c connection) (secretVersions []string) {
+	add := func(secretVersion string) {
+		if isSecretVersionPath(secretVersion) {
+			secretVersions = append(secretVersions, secretVersion)
+		}
+	}
+	if c.AuthConfig.UserPassword != nil && c.AuthConfig.UserPassword.Password != nil {
+		add(c.AuthConfig.UserPassword.Password.SecretVersion)
+	}
+	if c.AuthConfig.Oauth2JwtBearer != nil && c.AuthConfig.Oauth2JwtBearer.ClientKey != nil {
+		add(c.AuthConfig.Oauth2JwtBearer.ClientKey.SecretVersion)
+	}
+	if c.AuthConfig.Oauth2ClientCredentials != nil && c.AuthConfig.Oauth2ClientCredentials.ClientSecret != nil {
+		add(c.AuthConfig.Oauth2ClientCredentials.ClientSecret.SecretVersion)
+	}
+	if c.AuthConfig.SshPublicKey != nil {
+		if c.AuthConfig.SshPublicKey.Password != nil {
+			add(c.AuthConfig.SshPublicKey.Password.SecretVersion)
+		}
+		if c.AuthConfig.SshPublicKey.SshClientCert != nil {
+			add(c.AuthConfig.SshPublicKey.SshClientCert.SecretVersion)
+		}
+		if c.AuthConfig.SshPublicKey.SslClientCertPass != nil {
+			add(c.AuthConfig.SshPublicKey.SslClientCertPass.SecretVersion)
+		}
+	}
+	for _, cv := range c.ConfigVariables {
+		if cv.SecretValue != nil {
+			add(cv.SecretValue.SecretVersion)
+		}
+	}
+	if c.AuthConfig.AdditionalVariables != nil {
+		for _, cv := range *c.AuthConfig.AdditionalVariables {
+			if cv.SecretValue != nil {
+				add(cv.SecretValue.SecretVersion)
+			}
+		}
+	}
+	if c.SslConfig != nil {
+		if c.SslConfig.PrivateServerCertificate != nil && c.SslConfig.PrivateServerCertificate.SecretVersion != nil {
+			add(*c.SslConfig.PrivateServerCertificate.SecretVersion)
+		}
+		if c.SslConfig.ClientCertificate != nil && c.SslConfig.ClientCertificate.SecretVersion != nil {
+			add(*c.SslConfig.ClientCertificate.SecretVersion)
+		}
+		if c.SslConfig.ClientPrivateKey != nil && c.SslConfig.ClientPrivateKey.SecretVersion != nil {
+			add(*c.SslConfig.ClientPrivateKey.SecretVersion)
+		}
+		if c.SslConfig.ClientPrivateKeyPass != nil && c.SslConfig.ClientPrivateKeyPass.SecretVersion != nil {
+			add(*c.SslConfig.ClientPrivateKeyPass.SecretVersion)
+		}
 	}
+	if c.EventingConfig != nil {
+		for _, a := range []*authConfig{c.EventingConfig.AuthConfig, c.EventingConfig.ListenerAuthConfig} {
+			if a != nil && a.UserPassword != nil && a.UserPassword.Password != nil {
+				add(a.UserPassword.Password.SecretVersion)
+			}
+		}
+	}
+	return secretVersions
+}
 
+// validateDestinationConfigs checks each destination has either a host or a service
+// attachment, and that the port is in range when it is set
+func validateDestinationConfigs(c connectionRequest) error {
+	configs := []destinationConfig{}
+	if c.DestinationConfigs != nil {
+		configs = append(configs, *c.DestinationConfigs...)
+	}
+	if c.EventingConfig != nil {
+		if c.EventingConfig.RegistrationDestinationConfig != nil {
+			configs = append(configs, *c.EventingConfig.RegistrationDestinationConfig)
+		}
+		if c.EventingConfig.ProxyDestinationConfig != nil {
+			configs = append(configs, *c.EventingConfig.ProxyDestinationConfig)
+		}
+	}
+	for _, config := range configs {
+		for _, d := range config.Destinations {
+			if d.Host == "" && d.ServiceAttachment == "" {
+				return fmt.Errorf("destination config %s must set either host or serviceAttachment", config.Key)
+			}
+			if d.Port < 0 || d.Port > 65535 {
+				return fmt.Errorf("destination config %s has invalid port %d, must be between 1 and 65535",
+					config.Key, d.Port)
+			}
+		}
+	}
 	return nil
 }
 
+// getSecretVersion returns the secret version to reference when secrets are not
+// created by the toolkit. A fully specified version (either already set on the
+// connection or passed as the secret name) is used as is, otherwise version 1
+// of the named secret is assumed
+func getSecretVersion(existing string, secretName string) string {
+	if isSecretVersionPath(existing) {
+		return existing
+	}
+	if isSecretVersionPath(secretName) {
+		return secretName
+	}
+	return fmt.Sprintf("projects/%s/secrets/%s/versions/1", apiclient.GetProjectID(), secretName)
+}
+
+func isSecretVersionPath(name string) bool {
+	parts := strings.Split(name, "/")
+	return len(parts) == 6 && parts[0] == "projects" && parts[2] == "secrets" && parts[4] == "versions"
+}
+
+// getConnectorVersionName returns the connector version resource name. Connector
+// versions are global, irrespective of the region of the connection
+func getConnectorVersionName(provider string, connector string, version string) string {
+	return fmt.Sprintf("projects/%s/locations/global/providers/%s/connectors/%s/versions/%s",
+		apiclient.GetProjectID(), provider, connector, version)
+}
+
 func getConnectorName(version string) string {
 	return strings.Split(version, "/")[7]
 }
@@ -0,0 +1,798 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package connections
+
+import (
+	"bytes"
+	"encoding/json"
+	"log"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"internal/apiclient"
+	"internal/clilog"
+)
+
+func TestConnectionURLs(t *testing.T) {
+	newTestClient(t)
+
+	tests := []struct {
+		name     string
+		got      string
+		expected string
+	}{
+		{
+			"regional connection",
+			apiclient.GetBaseConnectorURL(),
+			"https://connectors.googleapis.com/v1/projects/my-project/locations/us-west1/connections",
+		},
+		{
+			"regional operations",
+			apiclient.GetBaseConnectorOperationsrURL(),
+			"https://connectors.googleapis.com/v1/projects/my-project/locations/us-west1/operations",
+		},
+		{
+			"global providers",
+			apiclient.GetBaseConnectorProvidersURL(),
+			"https://connectors.googleapis.com/v1/projects/my-project/locations/global/providers",
+		},
+		{
+			"global connector version",
+			getConnectorVersionName("gcp", "pubsub", "1"),
+			"projects/my-project/locations/global/providers/gcp/connectors/pubsub/versions/1",
+		},
+	}
+
+	for _, test := range tests {
+		if test.got != test.expected {
+			t.Errorf("%s: expected %s, got %s", test.name, test.expected, test.got)
+		}
+	}
+}
+
+func TestGetStringConfigVar(t *testing.T) {
+	projectID, timeout := "my-project", "42"
+	configVars := []configVar{
+		{Key: "project_id", StringValue: &projectID},
+		{Key: "nil_value"},
+		{Key: "timeout", IntValue: &timeout},
+	}
+
+	tests := []struct {
+		name       string
+		configVars []configVar
+		key        string
+		value      string
+		ok         bool
+	}{
+		{"string value", configVars, "project_id", projectID, true},
+		{"missing key", configVars, "missing", "", false},
+		{"nil value", configVars, "nil_value", "", false},
+		{"int value", configVars, "timeout", "", false},
+		{"nil config vars", nil, "project_id", "", false},
+	}
+
+	for _, test := range tests {
+		value, ok := getStringConfigVar(test.configVars, test.key)
+		if value != test.value || ok != test.ok {
+			t.Errorf("%s: expected %q (%t), got %q (%t)", test.name, test.value, test.ok, value, ok)
+		}
+	}
+}
+
+func TestSubstituteConfigVars(t *testing.T) {
+	newTestClient(t)
+
+	projectID, region, timeout, sa := "$PROJECT_ID$", "$REGION$", "42", "$SERVICE_ACCOUNT$"
+	configVars := []configVar{
+		{Key: "project_id", IntValue: &timeout}, // nil StringValue must not panic
+		{Key: "project_id", StringValue: &projectID},
+		{Key: "dataset_region", StringValue: &region},
+		{Key: "other_region"},
+		{Key: "impersonate_service_account", StringValue: &sa},
+	}
+
+	substituteConfigVars(configVars, "conn-sa@my-project.iam.gserviceaccount.com")
+
+	if projectID != "my-project" {
+		t.Errorf("project_id: expected my-project, got %s", projectID)
+	}
+	if region != "us-west1" {
+		t.Errorf("dataset_region: expected us-west1, got %s", region)
+	}
+	if configVars[0].StringValue != nil || configVars[3].StringValue != nil {
+		t.Errorf("config variables without a string value must not be changed")
+	}
+	if sa != "conn-sa@my-project.iam.gserviceaccount.com" {
+		t.Errorf("impersonate_service_account: expected the connection service account, got %s", sa)
+	}
+
+	// without a service account the placeholder is kept
+	sa = "$SERVICE_ACCOUNT$"
+	substituteConfigVars(configVars[4:], "")
+	if sa != "$SERVICE_ACCOUNT$" {
+		t.Errorf("expected the placeholder to be kept without a service account, got %s", sa)
+	}
+
+	if _, ok := getStringConfigVar(configVars[:1], "project_id"); ok {
+		t.Errorf("project_id without a string value must not be found")
+	}
+}
+
+func TestGetConnectionLogBody(t *testing.T) {
+	content := []byte(`{
+  "configVariables": [
+    {"key": "client_secret", "stringValue": "plain-config-secret"},
+    {"key": "token", "secretValue": {"secretVersion": "projects/p/secrets/config-secret/versions/1"}},
+    {"key": "api_key", "secretDetails": {"secretName": "api-key", "value": "inline-config-secret"}}
+  ],
+  "authConfig": {
+    "authType": "USER_PASSWORD",
+    "userPassword": {
+      "username": "admin",
+      "password": {"secretVersion": "projects/p/secrets/password-secret/versions/1"}
+    },
+    "oauth2ClientCredentials": {
+      "clientId": "client",
+      "clientSecretDetails": {"secretName": "client-secret", "value": "inline-client-secret"}
+    },
+    "additionalVariables": [
+      {"key": "extra", "secretValue": {"secretVersion": "projects/p/secrets/extra-secret/versions/1"}}
+    ]
+  },
+  "sslConfig": {
+    "clientPrivateKey": {"secretVersion": "projects/p/secrets/private-key-secret/versions/1"},
+    "clientCertificate": {"secretDetails": {"secretName": "cert", "value": "inline-cert-secret"}}
+  },
+  "eventingConfig": {
+    "listenerAuthConfig": {
+      "userPassword": {"password": {"secretVersion": "projects/p/secrets/listener-secret/versions/1"}}
+    },
+    "additionalVariables": [
+      {"key": "webhook", "secretValue": {"secretVersion": "projects/p/secrets/webhook-secret/versions/1"}}
+    ]
+  }
+}`)
+
+	logged := getConnectionLogBody(content)
+	for _, secret := range []string{
+		"plain-config-secret", "config-secret/", "inline-config-secret", "password-secret",
+		"inline-client-secret", "extra-secret", "private-key-secret", "inline-cert-secret",
+		"listener-secret", "webhook-secret",
+	} {
+		if strings.Contains(logged, secret) {
+			t.Errorf("logged connection contains %q: %s", secret, logged)
+		}
+	}
+	for _, kept := range []string{"admin", "client", "api_key"} {
+		if !strings.Contains(logged, kept) {
+			t.Errorf("logged connection is missing %q: %s", kept, logged)
+		}
+	}
+}
+
+func TestRedactConnectionCopies(t *testing.T) {
+	c := connectionRequest{}
+	if err := json.Unmarshal([]byte(`{"sslConfig": {"clientPrivateKey": `+
+		`{"secretVersion": "projects/p/secrets/key/versions/1"}}}`), &c); err != nil {
+		t.Fatal(err)
+	}
+	redactConnection(c)
+	if got := *c.SslConfig.ClientPrivateKey.SecretVersion; got != "projects/p/secrets/key/versions/1" {
+		t.Errorf("redactConnection changed the original connection, got %s", got)
+	}
+}
+
+func TestParseDestination(t *testing.T) {
+	tests := []struct {
+		destination string
+		key         string
+		host        string
+		port        int
+		valid       bool
+	}{
+		{"base_url=api.example.com:443", "base_url", "api.example.com", 443, true},
+		{"host=10.0.0.1:5432", "host", "10.0.0.1", 5432, true},
+		{"host=[::1]:8080", "host", "::1", 8080, true},
+		{"api.example.com:443", "", "", 0, false},
+		{"=api.example.com:443", "", "", 0, false},
+		{"base_url=api.example.com", "", "", 0, false},
+		{"base_url=:443", "", "", 0, false},
+		{"base_url=api.example.com:http", "", "", 0, false},
+		{"base_url=api.example.com:70000", "", "", 0, false},
+	}
+
+	for _, test := range tests {
+		key, dest, err := parseDestination(test.destination)
+		if (err == nil) != test.valid {
+			t.Errorf("parseDestination(%q) returned error %v, expected valid %t", test.destination, err, test.valid)
+			continue
+		}
+		if test.valid && (key != test.key || dest.Host != test.host || dest.Port != test.port) {
+			t.Errorf("parseDestination(%q) = %s, %s:%d, expected %s, %s:%d", test.destination,
+				key, dest.Host, dest.Port, test.key, test.host, test.port)
+		}
+	}
+}
+
+func TestSetCABundle(t *testing.T) {
+	caBundleFile := filepath.Join(t.TempDir(), "ca.pem")
+	if err := os.WriteFile(caBundleFile, []byte("-----BEGIN CERTIFICATE-----"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	content, err := SetCABundle([]byte(`{"sslConfig":{"type":"TLS","serverCertType":"PEM"}}`), "c1", caBundleFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := connectionRequest{}
+	if err = json.Unmarshal(content, &c); err != nil {
+		t.Fatal(err)
+	}
+	if !c.SslConfig.UseSSL || *c.SslConfig.TrustModel != "PRIVATE" || *c.SslConfig.Type != "TLS" {
+		t.Errorf("expected ssl with a private trust model, got %+v", c.SslConfig)
+	}
+	d := c.SslConfig.PrivateServerCertificate.SecretDetails
+	if d.SecretName != "c1-ca-bundle" || d.Reference != caBundleFile {
+		t.Errorf("expected the c1-ca-bundle secret from %s, got %+v", caBundleFile, d)
+	}
+
+	if _, err = SetCABundle([]byte(`{}`), "c1", filepath.Join(t.TempDir(), "missing.pem")); err == nil {
+		t.Errorf("expected an error for a missing CA bundle file")
+	}
+}
+
+func TestValidateOrderBy(t *testing.T) {
+	for _, orderBy := range []string{"", "name", "createTime desc", "updateTime asc,name"} {
+		if err := ValidateOrderBy(orderBy); err != nil {
+			t.Errorf("ValidateOrderBy(%q) returned %v", orderBy, err)
+		}
+	}
+	for _, orderBy := range []string{"state", "name descending", "name desc extra", "name,"} {
+		if err := ValidateOrderBy(orderBy); err == nil {
+			t.Errorf("ValidateOrderBy(%q) expected an error", orderBy)
+		}
+	}
+}
+
+func TestListAllConnectionsOrderBy(t *testing.T) {
+	newTestClient(t)
+
+	const orderBy = "createTime desc"
+	dir := newReplayDir(t)
+	writeListRecording(t, dir, orderBy, "",
+		`{"connections":[{"name":"c3"},{"name":"c2"}],"nextPageToken":"page2"}`)
+	writeListRecording(t, dir, orderBy, "page2",
+		`{"connections":[{"name":"c1"}]}`)
+
+	// a page requested without orderBy has no recording and fails the list
+	lconnections, err := listAllConnections("", orderBy)
+	if err != nil {
+		t.Fatalf("listAllConnections returned %v", err)
+	}
+	names := []string{}
+	for _, c := range lconnections {
+		names = append(names, *c.Name)
+	}
+	if strings.Join(names, ",") != "c3,c2,c1" {
+		t.Errorf("expected connections c3,c2,c1 in order, got %v", names)
+	}
+}
+
+func TestListAllConnectionsPageSize(t *testing.T) {
+	newTestClient(t)
+
+	for _, pageSize := range []int{0, 1001} {
+		if err := apiclient.SetListPageSize(pageSize); err == nil {
+			t.Errorf("SetListPageSize(%d) expected an error", pageSize)
+		}
+	}
+	if err := apiclient.SetListPageSize(1); err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = apiclient.SetListPageSize(maxPageSize) }()
+
+	const orderBy = "name"
+	dir := newReplayDir(t)
+	writeListRecording(t, dir, orderBy, "", `{"connections":[{"name":"c1"}],"nextPageToken":"page2"}`)
+	writeListRecording(t, dir, orderBy, "page2", `{"connections":[{"name":"c2"}]}`)
+
+	lconnections, err := listAllConnections("", orderBy)
+	if err != nil {
+		t.Fatalf("listAllConnections returned %v", err)
+	}
+	if len(lconnections) != 2 {
+		t.Errorf("expected both pages of one connection, got %d connections", len(lconnections))
+	}
+}
+
+func TestCountKeepsRegion(t *testing.T) {
+	newTestClient(t)
+
+	dir := newReplayDir(t)
+	writeListRecordingAt(t, dir, apiclient.GetBaseConnectorURLWithRegion("us-east1"), "", "",
+		`{"connections":[{"name":"c1"},{"name":"c2"}]}`)
+	writeListRecordingAt(t, dir, apiclient.GetBaseConnectorURLWithRegion("europe-west1"), "", "",
+		`{"connections":[{"name":"c3"}]}`)
+
+	count, err := Count([]string{"us-east1", "europe-west1"}, "")
+	if err != nil {
+		t.Fatalf("Count returned %v", err)
+	}
+	if count != 3 {
+		t.Errorf("expected 3 connections, got %d", count)
+	}
+	if region := apiclient.GetRegion(); region != "us-west1" {
+		t.Errorf("expected the region to stay us-west1, got %s", region)
+	}
+}
+
+func TestPrepareConfigVarSecretsExistingVersion(t *testing.T) {
+	newTestClient(t)
+
+	// the plan records the secret creates instead of calling Secret Manager
+	plan := &createPlan{}
+
+	const existing = "projects/other-project/secrets/api-key/versions/3"
+	configVars := []configVar{
+		{Key: "api_key", SecretValue: &secret{SecretVersion: existing}},
+		{Key: "password", SecretDetails: &secretDetails{SecretName: "db-password", Value: "p"}},
+	}
+	if err := prepareConfigVarSecrets(plan, configVars, nil, "", "", false, true, false, false); err != nil {
+		t.Fatal(err)
+	}
+
+	if configVars[0].SecretValue == nil || configVars[0].SecretValue.SecretVersion != existing ||
+		configVars[0].SecretDetails != nil {
+		t.Errorf("expected the existing secret version to be passed through, got %+v", configVars[0])
+	}
+	if configVars[1].SecretValue == nil || configVars[1].SecretValue.SecretVersion !=
+		"projects/my-project/secrets/db-password/versions/latest" || configVars[1].SecretDetails != nil {
+		t.Errorf("expected the secret details to be replaced by the created version, got %+v", configVars[1])
+	}
+	if len(plan.rows) != 1 {
+		t.Errorf("expected one secret to be created, got %v", plan.rows)
+	}
+}
+
+func TestPrepareConnectionConnectorVersion(t *testing.T) {
+	newTestClient(t)
+
+	const version = "projects/my-project/locations/global/providers/gcp/connectors/pubsub/versions/1"
+	dir := newReplayDir(t)
+	writeLaunchStageRecording(t, dir, "gcp", "pubsub", "1", gaLaunchStage)
+	payload, err := prepareConnection(nil, []byte(`{"connectorVersion":"`+version+`"}`),
+		"", "", "", false, false, false, false, CreateOptions{})
+	if err != nil {
+		t.Fatalf("expected connectorVersion without connectorDetails to be accepted, got %v", err)
+	}
+	c := connectionRequest{}
+	if err = json.Unmarshal(payload, &c); err != nil {
+		t.Fatal(err)
+	}
+	if c.ConnectorVersion == nil || *c.ConnectorVersion != version || c.ConnectorDetails != nil {
+		t.Errorf("expected the connectorVersion to be sent as is, got %s", payload)
+	}
+
+	for _, content := range []string{
+		`{"connectorVersion":"` + version + `","connectorDetails":{"name":"pubsub","provider":"gcp","version":1}}`,
+		`{"connectorVersion":"pubsub/versions/1"}`,
+		`{}`,
+	} {
+		if _, err = prepareConnection(nil, []byte(content), "", "", "", false, false, false, false,
+			CreateOptions{}); err == nil {
+			t.Errorf("expected an error for %s", content)
+		}
+	}
+}
+
+func TestSetConnectorVersion(t *testing.T) {
+	newTestClient(t)
+
+	// nothing is recorded, so looking up the latest GA version fails
+	newReplayDir(t)
+
+	c := connectionRequest{ConnectorDetails: &connectorDetails{Name: "pubsub", Provider: "gcp", VersionId: new(string)}}
+	*c.ConnectorDetails.VersionId = "2"
+	if err := setConnectorVersion(&c); err != nil {
+		t.Fatalf("expected versionId to be used without a lookup, got %v", err)
+	}
+	if *c.ConnectorVersion != "projects/my-project/locations/global/providers/gcp/connectors/pubsub/versions/2" {
+		t.Errorf("expected the versionId to be used, got %s", *c.ConnectorVersion)
+	}
+
+	c = connectionRequest{ConnectorDetails: &connectorDetails{Name: "pubsub", Provider: "gcp"}}
+	if err := setConnectorVersion(&c); err == nil {
+		t.Errorf("expected the latest GA version to be looked up without a version")
+	}
+}
+
+func TestPrepareConnectionLaunchStage(t *testing.T) {
+	newTestClient(t)
+
+	const version = "projects/my-project/locations/global/providers/gcp/connectors/pubsub/versions/2"
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{"version", `{"connectorDetails":{"name":"pubsub","provider":"gcp","version":2}}`},
+		{"versionId", `{"connectorDetails":{"name":"pubsub","provider":"gcp","versionId":"2"}}`},
+		{"connectorVersion", `{"connectorVersion":"` + version + `"}`},
+	}
+	for _, test := range tests {
+		// nothing is recorded, so the launch stage lookup fails
+		newReplayDir(t)
+		_, err := prepareConnection(nil, []byte(test.content), "", "", "", false, false, false, false, CreateOptions{})
+		if err == nil || !strings.Contains(err.Error(), "launch stage") {
+			t.Errorf("%s: expected the launch stage to be checked, got %v", test.name, err)
+		}
+
+		dir := newReplayDir(t)
+		writeLaunchStageRecording(t, dir, "gcp", "pubsub", "2", "PREVIEW")
+		_, err = prepareConnection(nil, []byte(test.content), "", "", "", false, false, false, false, CreateOptions{})
+		if err == nil || !strings.Contains(err.Error(), "launch stage PREVIEW") {
+			t.Errorf("%s: expected a preview version to be rejected, got %v", test.name, err)
+		}
+
+		_, err = prepareConnection(nil, []byte(test.content), "", "", "", false, false, false, false,
+			CreateOptions{AllowPreview: true})
+		if err != nil {
+			t.Errorf("%s: expected allow preview to accept a preview version, got %v", test.name, err)
+		}
+	}
+
+	// custom connectors have no launch stage
+	newReplayDir(t)
+	content := []byte(`{"connectorDetails":{"name":"my-connector","provider":"customconnector","versionId":"1"}}`)
+	if _, err := prepareConnection(nil, content, "", "", "", false, false, false, false, CreateOptions{}); err != nil {
+		t.Errorf("expected a custom connector to skip the launch stage check, got %v", err)
+	}
+}
+
+func TestGetTelemetryConnector(t *testing.T) {
+	for content, want := range map[string]string{
+		`{"connectorDetails":{"name":"pubsub"}}`:                                                     "pubsub",
+		`{"connectorVersion":"projects/p/locations/global/providers/gcp/connectors/gcs/versions/1"}`: "gcs",
+		`{"connectorVersion":"pubsub/versions/1"}`:                                                   "",
+		`{"connectorVersion":""}`:                                                                    "",
+		`{`:                                                                                          "",
+	} {
+		if got := getTelemetryConnector([]byte(content)); got != want {
+			t.Errorf("getTelemetryConnector(%s) = %q, want %q", content, got, want)
+		}
+	}
+}
+
+func TestPrintOperationConnection(t *testing.T) {
+	buf := &bytes.Buffer{}
+	info := clilog.Info
+	clilog.Info = log.New(buf, "", 0)
+	defer func() { clilog.Info = info }()
+
+	printOperationConnection([]byte(`{"name":"operation-1","done":true,"response":{` +
+		`"@type":"type.googleapis.com/google.cloud.connectors.v1.Connection",` +
+		`"name":"projects/p/locations/us-west1/connections/c1",` +
+		`"connectorVersion":"projects/p/locations/global/providers/gcp/connectors/pubsub/versions/1",` +
+		`"serviceAccount":"sa@p.iam.gserviceaccount.com","status":{"state":"ACTIVE"}}}`))
+
+	for _, want := range []string{
+		"connection projects/p/locations/us-west1/connections/c1",
+		"connectorVersion: projects/p/locations/global/providers/gcp/connectors/pubsub/versions/1",
+		"serviceAccount: sa@p.iam.gserviceaccount.com",
+		"state: ACTIVE",
+	} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("expected %q in %q", want, buf.String())
+		}
+	}
+
+	// an operation without a connection response prints nothing
+	buf.Reset()
+	printOperationConnection([]byte(`{"name":"operation-2","done":true,"response":{}}`))
+	if buf.Len() != 0 {
+		t.Errorf("expected no output, got %q", buf.String())
+	}
+}
+
+func TestFilterConnectionsByState(t *testing.T) {
+	lconnections := []connection{}
+	if err := json.Unmarshal([]byte(`[{"name":"c1","status":{"state":"ACTIVE"}},`+
+		`{"name":"c2","status":{"state":"ERROR"}},{"name":"c3"},`+
+		`{"name":"c4","status":{"state":"ERROR"}}]`), &lconnections); err != nil {
+		t.Fatal(err)
+	}
+
+	names := func(cs []connection) (n []string) {
+		for _, c := range cs {
+			n = append(n, *c.Name)
+		}
+		return n
+	}
+	if got := names(filterConnectionsByState(lconnections, "error")); strings.Join(got, ",") != "c2,c4" {
+		t.Errorf("expected c2 and c4 in ERROR, got %v", got)
+	}
+	if got := names(filterConnectionsByState(lconnections, "STATE_UNSPECIFIED")); strings.Join(got, ",") != "c3" {
+		t.Errorf("expected c3 without a status, got %v", got)
+	}
+
+	if err := ValidateState("Error"); err != nil {
+		t.Errorf("expected the state to be case insensitive, got %v", err)
+	}
+	if err := ValidateState("BROKEN"); err == nil {
+		t.Errorf("expected an error for an unknown state")
+	}
+}
+
+func TestApply(t *testing.T) {
+	newTestClient(t)
+
+	const connectorVersion = "projects/my-project/locations/global/providers/gcp/connectors/pubsub/versions/1"
+	base := apiclient.GetBaseConnectorURL()
+	content := []byte(`{"connectorVersion":"` + connectorVersion + `","description":"new",` +
+		`"configVariables":[{"key":"api_key","secretDetails":{"secretName":"api-key"}}]}`)
+
+	// the connection doesn't exist, so it is created
+	dir := newReplayDir(t)
+	writeRecording(t, dir, "GET", base+"/c1", 404, `{"error":{"code":404}}`)
+	writeRecording(t, dir, "POST", base+"?connectionId=c1", 200, `{"name":"operations/create"}`)
+	writeLaunchStageRecording(t, dir, "gcp", "pubsub", "1", gaLaunchStage)
+	respBody, err := Apply("c1", content, "", "", "", false, false, false, false, false, CreateOptions{})
+	if err != nil || !strings.Contains(string(respBody), "operations/create") {
+		t.Errorf("expected the connection to be created, got %s, %v", respBody, err)
+	}
+
+	// only the description differs, the live secret version is kept
+	dir = newReplayDir(t)
+	writeRecording(t, dir, "GET", base+"/c1", 200, `{"connectorVersion":"`+connectorVersion+`",`+
+		`"description":"old","configVariables":[{"key":"api_key",`+
+		`"secretValue":{"secretVersion":"projects/my-project/secrets/api-key/versions/3"}}]}`)
+	writeRecording(t, dir, "PATCH", base+"/c1?updateMask=description", 200, `{"name":"operations/patch"}`)
+	writeLaunchStageRecording(t, dir, "gcp", "pubsub", "1", gaLaunchStage)
+	respBody, err = Apply("c1", content, "", "", "", false, false, false, false, false, CreateOptions{})
+	if err != nil || !strings.Contains(string(respBody), "operations/patch") {
+		t.Errorf("expected the description to be patched, got %s, %v", respBody, err)
+	}
+
+	// any other error is returned instead of creating the connection
+	dir = newReplayDir(t)
+	writeRecording(t, dir, "GET", base+"/c1", 403, `{"error":{"code":403}}`)
+	if _, err = Apply("c1", content, "", "", "", false, false, false, false, false, CreateOptions{}); err == nil ||
+		!strings.HasPrefix(err.Error(), "Forbidden") {
+		t.Errorf("expected the get error to be returned, got %v", err)
+	}
+}
+
+func TestMergeDestinationConfigs(t *testing.T) {
+	current := []destinationConfig{
+		{Key: "url", Destinations: []destination{{Host: "old.example.com"}}},
+		{Key: "proxy", Destinations: []destination{{Host: "proxy.example.com", Port: 8080}}},
+	}
+	tests := []struct {
+		name     string
+		updates  []destinationConfig
+		expected []destinationConfig
+	}{
+		{"replace", []destinationConfig{{Key: "url", Destinations: []destination{{Host: "new.example.com"}}}},
+			[]destinationConfig{{Key: "url", Destinations: []destination{{Host: "new.example.com"}}}, current[1]}},
+		{"append", []destinationConfig{{Key: "auth", Destinations: []destination{{Host: "auth.example.com"}}}},
+			[]destinationConfig{current[0], current[1], {Key: "auth", Destinations: []destination{{Host: "auth.example.com"}}}}},
+		{"none", []destinationConfig{}, current},
+	}
+	for _, test := range tests {
+		merged := mergeDestinationConfigs(current, test.updates)
+		if !reflect.DeepEqual(merged, test.expected) {
+			t.Errorf("%s: expected %v, got %v", test.name, test.expected, merged)
+		}
+	}
+	if current[0].Destinations[0].Host != "old.example.com" {
+		t.Errorf("expected the current configs to be unchanged, got %v", current)
+	}
+}
+
+// byDestinationKey indexes destination configs by key
+func byDestinationKey(configs []destinationConfig) map[string][]destination {
+	m := map[string][]destination{}
+	for _, config := range configs {
+		m[config.Key] = config.Destinations
+	}
+	return m
+}
+
+func TestApplyMergesDestinationConfigs(t *testing.T) {
+	newTestClient(t)
+
+	const connectorVersion = "projects/my-project/locations/global/providers/gcp/connectors/http/versions/1"
+	base := apiclient.GetBaseConnectorURL()
+	live := `{"connectorVersion":"` + connectorVersion + `","destinationConfigs":[` +
+		`{"key":"url","destinations":[{"host":"api.example.com"}]},` +
+		`{"key":"proxy","destinations":[{"host":"proxy.example.com","port":8080}]}]}`
+
+	// the file only sets the url destination, which is unchanged, so the proxy is kept
+	dir := newReplayDir(t)
+	writeRecording(t, dir, "GET", base+"/c1", 200, live)
+	writeLaunchStageRecording(t, dir, "gcp", "http", "1", gaLaunchStage)
+	content := []byte(`{"connectorVersion":"` + connectorVersion + `",` +
+		`"destinationConfigs":[{"key":"url","destinations":[{"host":"api.example.com"}]}]}`)
+	if respBody, err := Apply("c1", content, "", "", "", false, false, false, false, false, CreateOptions{}); err != nil ||
+		string(respBody) != live {
+		t.Errorf("expected the connection to be up to date, got %s, %v", respBody, err)
+	}
+
+	// a changed url destination is patched merged with the live proxy destination
+	existing := []byte(live)
+	desired := []byte(`{"description":"d","destinationConfigs":[{"key":"url","destinations":[{"host":"new.example.com"}]}]}`)
+	merged, err := mergeExistingDestinationConfigs(existing, desired)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := connectionRequest{}
+	if err = json.Unmarshal(merged, &c); err != nil {
+		t.Fatal(err)
+	}
+	if c.Description == nil || *c.Description != "d" || c.DestinationConfigs == nil || len(*c.DestinationConfigs) != 2 ||
+		byDestinationKey(*c.DestinationConfigs)["url"][0].Host != "new.example.com" ||
+		byDestinationKey(*c.DestinationConfigs)["proxy"][0].Port != 8080 {
+		t.Errorf("expected the url destination merged with the proxy, got %s", merged)
+	}
+	if updateMask, _ := getUpdateMask(existing, merged); strings.Join(updateMask, ",") != "description,destinationConfigs" {
+		t.Errorf("expected description and destinationConfigs to be updated, got %v", updateMask)
+	}
+
+	// without destination configs the file is unchanged
+	if unchanged, _ := mergeExistingDestinationConfigs(existing, []byte(`{"description":"d"}`)); string(unchanged) != `{"description":"d"}` {
+		t.Errorf("expected the file to be unchanged, got %s", unchanged)
+	}
+}
+
+func TestPatchDestinations(t *testing.T) {
+	newTestClient(t)
+
+	base := apiclient.GetBaseConnectorURL()
+	dir := newReplayDir(t)
+	writeGetRecording(t, dir, "c1", `{"destinationConfigs":[{"key":"url","destinations":[{"host":"api.example.com"}]}]}`)
+	writeRecording(t, dir, "PATCH", base+"/c1?updateMask=destinationConfigs", 200, `{"name":"operations/patch"}`)
+	respBody, err := PatchDestinations("c1", []byte(`[{"key":"proxy","destinations":[{"host":"proxy.example.com"}]}]`))
+	if err != nil || !strings.Contains(string(respBody), "operations/patch") {
+		t.Errorf("expected the destinations to be patched, got %s, %v", respBody, err)
+	}
+
+	// the live connection is read before the patch
+	dir = newReplayDir(t)
+	writeRecording(t, dir, "GET", base+"/c1", 404, `{"error":{"code":404}}`)
+	if _, err = PatchDestinations("c1", []byte(`[{"key":"proxy","destinations":[{"host":"proxy.example.com"}]}]`)); !apiclient.IsNotFound(err) {
+		t.Errorf("expected the get error to be returned, got %v", err)
+	}
+}
+
+func TestGetUpdateMaskSecretVersions(t *testing.T) {
+	existing := []byte(`{"description":"d","authConfig":{"userPassword":{"username":"u",` +
+		`"password":{"secretVersion":"projects/p/secrets/s/versions/3"}}}}`)
+	desired := []byte(`{"description":"d","authConfig":{"userPassword":{"username":"u",` +
+		`"password":{"secretVersion":"projects/p/secrets/s/versions/1"}}}}`)
+	if updateMask, err := getUpdateMask(existing, desired); err != nil || len(updateMask) != 0 {
+		t.Errorf("expected no update for a different version of the same secret, got %v, %v", updateMask, err)
+	}
+
+	pinned, err := pinSecretVersions(existing, desired)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(pinned), "projects/p/secrets/s/versions/3") {
+		t.Errorf("expected the live secret version to be kept, got %s", pinned)
+	}
+
+	desired = []byte(`{"authConfig":{"userPassword":{"username":"u",` +
+		`"password":{"secretVersion":"projects/p/secrets/other/versions/1"}}}}`)
+	if updateMask, _ := getUpdateMask(existing, desired); strings.Join(updateMask, ",") != "authConfig" {
+		t.Errorf("expected a different secret to update authConfig, got %v", updateMask)
+	}
+}
+
+func TestReplaceInvalidFileKeepsConnection(t *testing.T) {
+	newTestClient(t)
+
+	// only the get is recorded, so a delete would fail with "unable to delete"
+	dir := newReplayDir(t)
+	writeGetRecording(t, dir, "c1", `{"name":"projects/my-project/locations/us-west1/connections/c1"}`)
+
+	for _, content := range []string{
+		`{"connectorDetails":{"name":"pubsub","version":1}}`,
+		`{"connectorDetails":{"name":"x","provider":"gcp","version":1},"destinationConfigs":[{"key":"url","destinations":[{"host":"h","port":70000}]}]}`,
+		`{"connectorDetails":{"name":"x","provider":"gcp","version":1},` +
+			`"authConfig":{"authType":"USER_PASSWORD","userPassword":{"username":"u","passwordDetails":{"secretName":"p"}}}}`,
+	} {
+		_, err := Replace("c1", []byte(content), "", "", "", false, true, false, false, CreateOptions{})
+		if err == nil || !strings.Contains(err.Error(), "is not replaced") {
+			t.Errorf("expected %s to be rejected before the delete, got %v", content, err)
+		}
+	}
+}
+
+// writeDefaultServiceAccountRecordings writes the replay recordings of the lookup of
+// the default compute service account of my-project and of its actAs permission test
+func writeDefaultServiceAccountRecordings(t *testing.T, dir string, permissions string) {
+	t.Helper()
+	writeRecording(t, dir, "GET", "https://cloudresourcemanager.googleapis.com/v3/projects/my-project", 200,
+		`{"name":"projects/123","projectId":"my-project"}`)
+	writeRecording(t, dir, "POST", "https://iam.googleapis.com/v1/projects/-/serviceAccounts/"+
+		"123-compute@developer.gserviceaccount.com:testIamPermissions", 200, `{"permissions":[`+permissions+`]}`)
+}
+
+func TestCheckServiceAccountsSkipsInvalidFiles(t *testing.T) {
+	newTestClient(t)
+	writeDefaultServiceAccountRecordings(t, newReplayDir(t), `"iam.serviceAccounts.actAs"`)
+
+	dir := t.TempDir()
+	invalid := filepath.Join(dir, "invalid.json")
+	valid := filepath.Join(dir, "valid.json")
+	if err := os.WriteFile(invalid, []byte(`{"description":`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(valid, []byte(`{"description":"d"}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := checkServiceAccounts([]string{invalid, valid}, nil); err != nil {
+		t.Errorf("expected an invalid file to be left to the import, got %v", err)
+	}
+	if err := checkServiceAccounts([]string{invalid, valid}, map[string]interface{}{"labels": map[string]interface{}{}}); err != nil {
+		t.Errorf("expected an invalid file with defaults to be left to the import, got %v", err)
+	}
+}
+
+func TestCheckServiceAccountsDefaultActAs(t *testing.T) {
+	newTestClient(t)
+	writeDefaultServiceAccountRecordings(t, newReplayDir(t), "")
+
+	file := filepath.Join(t.TempDir(), "c1.json")
+	if err := os.WriteFile(file, []byte(`{"description":"d"}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	err := checkServiceAccounts([]string{file}, nil)
+	if err == nil || !strings.Contains(err.Error(),
+		"123-compute@developer.gserviceaccount.com (missing iam.serviceAccounts.actAs)") {
+		t.Errorf("expected the default service account to be rejected without actAs, got %v", err)
+	}
+}
+
+func TestSelectFields(t *testing.T) {
+	tests := []struct {
+		name     string
+		respBody string
+		expected []string
+	}{
+		{"connection", `{"name":"c1","nodeConfig":{"minNodeCount":2}}`, []string{`{"name":"c1","nodeConfig.minNodeCount":2}`}},
+		{"list", `{"connections":[{"name":"c1"},{"name":"c2"}]}`, []string{`{"name":"c1"}`, `{"name":"c2"}`}},
+		{"empty list", `{}`, nil},
+		{"empty page", `{"nextPageToken":"t"}`, nil},
+	}
+	for _, test := range tests {
+		lines, err := selectFields([]byte(test.respBody), []string{"name", "nodeConfig.minNodeCount"})
+		if err != nil {
+			t.Fatalf("%s: unexpected error %v", test.name, err)
+		}
+		if len(lines) != len(test.expected) {
+			t.Errorf("%s: expected %v, got %q", test.name, test.expected, lines)
+			continue
+		}
+		for i := range lines {
+			if string(lines[i]) != test.expected[i] {
+				t.Errorf("%s: expected %s, got %s", test.name, test.expected[i], lines[i])
+			}
+		}
+	}
+}
@@ -16,14 +16,18 @@ package connections
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/url"
+	"os"
 	"path"
+	"path/filepath"
 	"strconv"
 	"strings"
 	"time"
 
 	"internal/apiclient"
+	"internal/clilog"
 )
 
 type customConnectorOverrides struct {
@@ -53,6 +57,7 @@ type configVariableTemplate struct {
 	Required        bool   `json:"required,omitempty"`
 	IsAdvanced      bool   `json:"isAdvanced,omitempty"`
 	LocationType    string `json:"locationType,omitempty"`
+	Immutable       bool   `json:"immutable,omitempty"`
 }
 
 const waitTime = 1 * time.Second
@@ -186,7 +191,7 @@ func GetCustomVersion(connName string, connVersion string, overrides bool) (resp
 			return nil, err
 		}
 		// remove the default p4s from the overrides
-		if cVerReq.ServiceAccount != nil && strings.Contains(*cVerReq.ServiceAccount, "-compute@developer.gserviceaccount.com") {
+		if cVerReq.ServiceAccount != nil && strings.Contains(*cVerReq.ServiceAccount, defaultComputeServiceAgent) {
 			cVerReq.ServiceAccount = nil
 		}
 		c.CustomConnectorVersion = cVerReq
@@ -254,19 +259,9 @@ func CreateCustomWithVersion(name string, version string, contents []byte,
 		return err
 	}
 
-	var createCustomMap map[string]interface{}
-	err = json.Unmarshal(createCustomBody, &createCustomMap)
-	if err != nil {
-		return err
-	}
-
 	// wait for custom connection to be created
-	if len(strings.Split(fmt.Sprintf("%s", createCustomMap["name"]), "/")) > 4 {
-		operationName := strings.Split(fmt.Sprintf("%s", createCustomMap["name"]), "/")[5]
-		err = waitForCustom(operationName)
-		if err != nil {
-			return err
-		}
+	if err = waitForCustomOperation(createCustomBody); err != nil {
+		return err
 	}
 
 	connectionVersionContents, err := json.Marshal(c.CustomConnectorVersion)
@@ -309,24 +304,247 @@ func waitForCustom(operationName string) error {
 	}
 }
 
+// customVersionTimeout is how long to wait for a custom connector version to be ACTIVE
+// when no wait timeout is set
+const customVersionTimeout = 30 * time.Minute
+
+// waitForCustomVersion polls the custom connector version until it is ACTIVE. It returns
+// an error if the version ends in ERROR or FAILED, or when the wait timeout passes
 func waitForCustomVersion(name string, version string) error {
 	var err error
 	var respBody []byte
-	var respMap map[string]string
+	v := struct {
+		State string `json:"state,omitempty"`
+	}{}
+
+	timeout := apiclient.GetWaitTimeout()
+	if timeout == 0 {
+		timeout = customVersionTimeout
+	}
+	start := time.Now()
 
 	for {
 		if respBody, err = GetCustomVersion(name, version, false); err != nil {
 			return err
 		}
 
-		if err = json.Unmarshal(respBody, &respMap); err != nil {
+		if err = json.Unmarshal(respBody, &v); err != nil {
 			return err
 		}
 
-		if respMap["state"] == "ACTIVE" {
+		switch v.State {
+		case "ACTIVE":
 			time.Sleep(waitTime)
 			return nil
+		case "ERROR", "FAILED":
+			return fmt.Errorf("custom connector %s version %s is in state %s", name, version, v.State)
+		}
+		if time.Since(start) >= timeout {
+			return fmt.Errorf("timed out after %s waiting for custom connector %s version %s to be ACTIVE, state is %s",
+				timeout, name, version, v.State)
 		}
 		time.Sleep(waitTime)
 	}
 }
+
+// customConnectorDefinition is the exported custom connector, without its versions
+type customConnectorDefinition struct {
+	DisplayName         string            `json:"displayName,omitempty"`
+	Description         string            `json:"description,omitempty"`
+	CustomConnectorType string            `json:"customConnectorType,omitempty"`
+	Labels              map[string]string `json:"labels,omitempty"`
+}
+
+const (
+	customConnectorFileName    = "customConnector.json"
+	customVersionsFolderName   = "versions"
+	defaultComputeServiceAgent = "-compute@developer.gserviceaccount.com"
+)
+
+// ExportCustomConnectors writes every custom connector of the project to its own sub
+// folder of folder, with the definition in customConnector.json and each version,
+// with its spec location, auth and destinations, in versions/<version>.json
+func ExportCustomConnectors(folder string) (err error) {
+	apiclient.ClientPrintHttpResponse.Set(false)
+	defer apiclient.ClientPrintHttpResponse.Set(apiclient.GetCmdPrintHttpResponseSetting())
+
+	customConnectors, err := listAllCustom()
+	if err != nil {
+		return err
+	}
+
+	for _, c := range customConnectors {
+		name := path.Base(c.Name)
+		definition, err := json.Marshal(c.customConnectorDefinition)
+		if err != nil {
+			return err
+		}
+		if err = os.MkdirAll(path.Join(folder, name, customVersionsFolderName), 0o755); err != nil {
+			return err
+		}
+		if err = apiclient.WriteByteArrayToFile(path.Join(folder, name, customConnectorFileName),
+			false, definition); err != nil {
+			return err
+		}
+
+		versions, err := listAllCustomVersions(name)
+		if err != nil {
+			return err
+		}
+		for _, v := range versions {
+			// the default compute service account is set by the API when none is passed
+			if v.ServiceAccount != nil && strings.HasSuffix(*v.ServiceAccount, defaultComputeServiceAgent) {
+				v.ServiceAccount = nil
+			}
+			version, err := json.Marshal(v.customConnectorVersionRequest)
+			if err != nil {
+				return err
+			}
+			if err = apiclient.WriteByteArrayToFile(path.Join(folder, name, customVersionsFolderName,
+				path.Base(v.Name)+".json"), false, version); err != nil {
+				return err
+			}
+		}
+		clilog.Info.Printf("Downloaded custom connector %s with %d versions\n", name, len(versions))
+	}
+	return nil
+}
+
+// ImportCustomConnectors creates the custom connectors exported to folder and the
+// versions they are missing. Versions can't be changed once created, existing ones
+// are skipped
+func ImportCustomConnectors(folder string) (err error) {
+	apiclient.ClientPrintHttpResponse.Set(false)
+	defer apiclient.ClientPrintHttpResponse.Set(apiclient.GetCmdPrintHttpResponseSetting())
+
+	definitionFiles, err := filepath.Glob(filepath.Join(folder, "*", customConnectorFileName))
+	if err != nil {
+		return err
+	}
+
+	errs := []string{}
+	for _, definitionFile := range definitionFiles {
+		name := filepath.Base(filepath.Dir(definitionFile))
+		if err = importCustomConnector(name, definitionFile); err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %v", name, err))
+		}
+	}
+
+	if len(errs) > 0 {
+		return errors.New(strings.Join(errs, "\n"))
+	}
+	return nil
+}
+
+// importCustomConnector creates the custom connector from its definition file when it
+// doesn't exist, followed by the versions in its versions folder it doesn't have
+func importCustomConnector(name string, definitionFile string) (err error) {
+	if _, err = GetCustom(name); err != nil {
+		content, err := os.ReadFile(definitionFile)
+		if err != nil {
+			return err
+		}
+		c := customConnectorDefinition{}
+		if err = json.Unmarshal(content, &c); err != nil {
+			return fmt.Errorf("unable to parse %s: %w", definitionFile, err)
+		}
+		clilog.Info.Printf("creating custom connector %s\n", name)
+		respBody, err := CreateCustom(name, c.Description, c.DisplayName, c.CustomConnectorType, c.Labels)
+		if err != nil {
+			return err
+		}
+		if err = waitForCustomOperation(respBody); err != nil {
+			return err
+		}
+	}
+
+	versionFiles, err := filepath.Glob(filepath.Join(filepath.Dir(definitionFile), customVersionsFolderName, "*.json"))
+	if err != nil {
+		return err
+	}
+	for _, versionFile := range versionFiles {
+		version := strings.TrimSuffix(filepath.Base(versionFile), filepath.Ext(versionFile))
+		if _, err = GetCustomVersion(name, version, false); err == nil {
+			clilog.Info.Printf("custom connector %s version %s already exists, skipping\n", name, version)
+			continue
+		}
+		content, err := os.ReadFile(versionFile)
+		if err != nil {
+			return err
+		}
+		clilog.Info.Printf("creating custom connector %s version %s\n", name, version)
+		if _, err = CreateCustomVersion(name, version, content, "", ""); err != nil {
+			return err
+		}
+		if err = waitForCustomVersion(name, version); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// listAllCustom returns all custom connectors of the project
+func listAllCustom() (customConnectors []customConnectorListItem, err error) {
+	pageToken := ""
+	for {
+		l := struct {
+			CustomConnectors []customConnectorListItem `json:"customConnectors,omitempty"`
+			NextPageToken    string                    `json:"nextPageToken,omitempty"`
+		}{}
+		respBody, err := ListCustom(getListPageSize(), pageToken, "")
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch custom connectors: %w", err)
+		}
+		if err = json.Unmarshal(respBody, &l); err != nil {
+			return nil, fmt.Errorf("failed to unmarshall: %w", err)
+		}
+		customConnectors = append(customConnectors, l.CustomConnectors...)
+		if pageToken = l.NextPageToken; pageToken == "" {
+			return customConnectors, nil
+		}
+	}
+}
+
+// listAllCustomVersions returns all versions of the custom connector
+func listAllCustomVersions(name string) (versions []customConnectorVersionListItem, err error) {
+	pageToken := ""
+	for {
+		l := struct {
+			CustomConnectorVersions []customConnectorVersionListItem `json:"customConnectorVersions,omitempty"`
+			NextPageToken           string                           `json:"nextPageToken,omitempty"`
+		}{}
+		respBody, err := ListCustomVersions(name, getListPageSize(), pageToken)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch versions of custom connector %s: %w", name, err)
+		}
+		if err = json.Unmarshal(respBody, &l); err != nil {
+			return nil, fmt.Errorf("failed to unmarshall: %w", err)
+		}
+		versions = append(versions, l.CustomConnectorVersions...)
+		if pageToken = l.NextPageToken; pageToken == "" {
+			return versions, nil
+		}
+	}
+}
+
+type customConnectorListItem struct {
+	Name string `json:"name,omitempty"`
+	customConnectorDefinition
+}
+
+type customConnectorVersionListItem struct {
+	Name string `json:"name,omitempty"`
+	customConnectorVersionRequest
+}
+
+// waitForCustomOperation waits for the operation returned by CreateCustom
+func waitForCustomOperation(createCustomBody []byte) error {
+	var createCustomMap map[string]interface{}
+	if err := json.Unmarshal(createCustomBody, &createCustomMap); err != nil {
+		return err
+	}
+	if parts := strings.Split(fmt.Sprintf("%s", createCustomMap["name"]), "/"); len(parts) > 5 {
+		return waitForCustom(parts[5])
+	}
+	return nil
+}
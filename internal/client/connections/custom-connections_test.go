@@ -0,0 +1,43 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package connections
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"internal/apiclient"
+)
+
+func TestWaitForCustomVersion(t *testing.T) {
+	newTestClient(t)
+	versionURL := apiclient.GetBaseCustomConnectorURL() + "/cc1/customConnectorVersions/1"
+
+	dir := newReplayDir(t)
+	writeRecording(t, dir, "GET", versionURL, 200, `{"state":"ERROR"}`)
+	if err := waitForCustomVersion("cc1", "1"); err == nil || !strings.Contains(err.Error(), "state ERROR") {
+		t.Errorf("expected the ERROR state to end the wait, got %v", err)
+	}
+
+	if err := apiclient.SetWaitTiming(time.Nanosecond, time.Nanosecond); err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = apiclient.SetWaitTiming(0, 0) }()
+	writeRecording(t, dir, "GET", versionURL, 200, `{"state":"CREATING"}`)
+	if err := waitForCustomVersion("cc1", "1"); err == nil || !strings.HasPrefix(err.Error(), "timed out") {
+		t.Errorf("expected the wait to time out, got %v", err)
+	}
+}
@@ -0,0 +1,245 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package connections
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"sort"
+	"strings"
+
+	"internal/apiclient"
+)
+
+// manifest records the connections written by Export
+type manifest struct {
+	Labels           map[string]string `json:"labels,omitempty"`           // label selector of the export
+	LaunchStage      bool              `json:"launchStage,omitempty"`      // the files include the launch stage
+	SecretReferences bool              `json:"secretReferences,omitempty"` // the files reference the exported secrets
+	Connections      []manifestEntry   `json:"connections,omitempty"`
+}
+
+type manifestEntry struct {
+	Name string `json:"name,omitempty"`
+	File string `json:"file,omitempty"`
+	Hash string `json:"hash,omitempty"` // sha256 of the exported file
+}
+
+// drift states reported by DetectDrift
+const (
+	driftChanged = "CHANGED"
+	driftAdded   = "ADDED"
+	driftDeleted = "DELETED"
+)
+
+// states reported by DiffFolder
+const (
+	diffUnchanged       = "UNCHANGED"
+	diffChanged         = "CHANGED"
+	diffMissingLocally  = "MISSING_LOCALLY"
+	diffMissingRemotely = "MISSING_REMOTELY"
+)
+
+// newManifestEntry returns the manifest entry of an exported connection file
+func newManifestEntry(fileName string, content []byte) manifestEntry {
+	hash := sha256.Sum256(content)
+	return manifestEntry{
+		Name: strings.TrimSuffix(fileName, path.Ext(fileName)),
+		File: fileName,
+		Hash: hex.EncodeToString(hash[:]),
+	}
+}
+
+// writeManifest writes the manifest to folder
+func writeManifest(folder string, m manifest) error {
+	content, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	return apiclient.WriteByteArrayToFile(path.Join(folder, manifestFileName), false, content)
+}
+
+// DetectDrift compares the connections in the region with the manifest written by the
+// last Export to folder and prints the connections that were changed, added or deleted
+// since. It returns the names of the drifted connections
+func DetectDrift(folder string) (drifted []string, err error) {
+	content, err := os.ReadFile(path.Join(folder, manifestFileName))
+	if err != nil {
+		return nil, fmt.Errorf("unable to read the export manifest: %w", err)
+	}
+	m := manifest{}
+	if err = json.Unmarshal(content, &m); err != nil {
+		return nil, fmt.Errorf("unable to parse %s: %w", manifestFileName, err)
+	}
+
+	apiclient.ClientPrintHttpResponse.Set(false)
+	defer apiclient.ClientPrintHttpResponse.Set(apiclient.GetCmdPrintHttpResponseSetting())
+
+	lconnections, err := listAllConnections("", "")
+	if err != nil {
+		return nil, err
+	}
+	lconnections = filterConnectionsByLabels(lconnections, m.Labels)
+
+	exported := make(map[string]manifestEntry)
+	for _, entry := range m.Connections {
+		exported[entry.Name] = entry
+	}
+
+	states := make(map[string]string)
+	for _, lconnection := range lconnections {
+		// compare with files of the same shape as the export
+		fileName, connectionPayload, err := getExportPayload(lconnection, m.LaunchStage, m.SecretReferences)
+		if err != nil {
+			return nil, err
+		}
+		live := newManifestEntry(fileName, connectionPayload)
+		entry, ok := exported[live.Name]
+		if !ok {
+			states[live.Name] = driftAdded
+		} else if entry.Hash != live.Hash {
+			states[live.Name] = driftChanged
+		}
+		delete(exported, live.Name)
+	}
+	for name := range exported {
+		states[name] = driftDeleted
+	}
+
+	for name := range states {
+		drifted = append(drifted, name)
+	}
+	sort.Strings(drifted)
+
+	rows := [][]string{}
+	for _, name := range drifted {
+		rows = append(rows, []string{name, states[name]})
+	}
+	apiclient.PrintTable([]string{"NAME", "DRIFT"}, rows)
+	return drifted, nil
+}
+
+// DiffFolder compares every connection file in folder, like the ones read by Import, with
+// the live connection of the same name and prints whether it is unchanged, changed, or
+// missing locally or remotely, with the changed fields. It returns the names of the
+// connections that differ, and an error if there are any so it can gate CI
+func DiffFolder(folder string) (differ []string, err error) {
+	files, err := getConnectionFiles(folder)
+	if err != nil {
+		return nil, err
+	}
+	defaults, err := readDefaultsFile(folder)
+	if err != nil {
+		return nil, err
+	}
+
+	apiclient.ClientPrintHttpResponse.Set(false)
+	defer apiclient.ClientPrintHttpResponse.Set(apiclient.GetCmdPrintHttpResponseSetting())
+
+	lconnections, err := listAllConnections("", "")
+	if err != nil {
+		return nil, err
+	}
+	live := make(map[string][]byte)
+	for _, lconnection := range lconnections {
+		_, connectionPayload, err := getExportPayload(lconnection, false, false)
+		if err != nil {
+			return nil, err
+		}
+		live[getConnectionName(*lconnection.Name)] = connectionPayload
+	}
+
+	rows := [][]string{}
+	local := make(map[string]bool)
+	for _, file := range files {
+		content, err := readConnectionFile(file, defaults)
+		if err != nil {
+			return nil, err
+		}
+		name, err := deriveConnectionName(file, content)
+		if err != nil {
+			return nil, err
+		}
+		local[name] = true
+
+		livePayload, ok := live[name]
+		if !ok {
+			rows = append(rows, []string{name, diffMissingRemotely, ""})
+			differ = append(differ, name)
+			continue
+		}
+		fields, err := diffConnectionFields(livePayload, content)
+		if err != nil {
+			return nil, fmt.Errorf("unable to compare %s: %w", file, err)
+		}
+		if len(fields) == 0 {
+			rows = append(rows, []string{name, diffUnchanged, ""})
+			continue
+		}
+		rows = append(rows, []string{name, diffChanged, strings.Join(fields, ",")})
+		differ = append(differ, name)
+	}
+	for name := range live {
+		if !local[name] {
+			rows = append(rows, []string{name, diffMissingLocally, ""})
+			differ = append(differ, name)
+		}
+	}
+
+	sort.Slice(rows, func(i, j int) bool { return rows[i][0] < rows[j][0] })
+	sort.Strings(differ)
+	apiclient.PrintTable([]string{"NAME", "STATE", "FIELDS"}, rows)
+
+	if len(differ) > 0 {
+		return differ, fmt.Errorf("%d connections in %s differ from the region", len(differ), folder)
+	}
+	return nil, nil
+}
+
+// diffConnectionFields returns the top level fields that differ between the live and
+// local connection, set on either side, ignoring the connectionName set only in files
+func diffConnectionFields(live []byte, local []byte) (fields []string, err error) {
+	f := map[string]interface{}{}
+	if err = json.Unmarshal(local, &f); err != nil {
+		return nil, err
+	}
+	delete(f, "connectionName")
+	if local, err = json.Marshal(f); err != nil {
+		return nil, err
+	}
+
+	changed, err := getUpdateMask(live, local)
+	if err != nil {
+		return nil, err
+	}
+	removed, err := getUpdateMask(local, live)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make(map[string]bool)
+	for _, key := range append(changed, removed...) {
+		if !keys[key] {
+			keys[key] = true
+			fields = append(fields, key)
+		}
+	}
+	sort.Strings(fields)
+	return fields, nil
+}
@@ -0,0 +1,86 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package connections
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDiffConnectionFields(t *testing.T) {
+	live := []byte(`{"description":"live","labels":{"env":"prod"},"suspended":true}`)
+	local := []byte(`{"connectionName":"c1","description":"local","labels":{"env":"prod"},"logConfig":{"enabled":true}}`)
+
+	fields, err := diffConnectionFields(live, local)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(fields, ","); got != "description,logConfig,suspended" {
+		t.Errorf("expected description,logConfig,suspended, got %s", got)
+	}
+
+	live = []byte(`{"configVariables":[{"key":"k","secretValue":{"secretVersion":"projects/p/secrets/s/versions/2"}}]}`)
+	local = []byte(`{"configVariables":[{"key":"k","secretValue":{"secretVersion":"projects/p/secrets/s/versions/1"}}]}`)
+	if fields, err = diffConnectionFields(live, local); err != nil || len(fields) != 0 {
+		t.Errorf("expected no difference for another version of the same secret, got %v, %v", fields, err)
+	}
+}
+
+func TestIsSameJSONFile(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "c1.json")
+	if err := os.WriteFile(file, []byte("{\n  \"labels\": {\"env\": \"prod\"},\n  \"description\": \"d\"\n}\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if !isSameJSONFile(file, []byte(`{"description":"d","labels":{"env":"prod"}}`)) {
+		t.Errorf("expected a file with other formatting and key order to be the same")
+	}
+	if isSameJSONFile(file, []byte(`{"description":"changed","labels":{"env":"prod"}}`)) {
+		t.Errorf("expected a file with another description to differ")
+	}
+	if isSameJSONFile(filepath.Join(t.TempDir(), "missing.json"), []byte(`{}`)) {
+		t.Errorf("expected a missing file to differ")
+	}
+}
+
+func TestGetExportPayloadDeterministic(t *testing.T) {
+	newTestClient(t)
+
+	name := "projects/p/locations/us-west1/connections/c1"
+	connectorVersion := "projects/p/locations/global/providers/gcp/connectors/pubsub/versions/1"
+	newConnection := func() connection {
+		c := connection{Name: &name, ConnectorVersion: &connectorVersion, Labels: map[string]string{}}
+		for _, key := range []string{"team", "env", "app", "cost-center", "owner", "tier", "region", "zone"} {
+			c.Labels[key] = key + "-value"
+		}
+		return c
+	}
+
+	_, first, err := getExportPayload(newConnection(), false, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 20; i++ {
+		_, payload, err := getExportPayload(newConnection(), false, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(payload) != string(first) {
+			t.Fatalf("expected identical exports, got %s and %s", first, payload)
+		}
+	}
+}
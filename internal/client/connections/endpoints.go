@@ -18,13 +18,15 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/url"
+	"os"
 	"path"
 	"path/filepath"
+	"slices"
 	"strconv"
 	"strings"
-	"time"
 
 	"internal/apiclient"
+
 	"internal/clilog"
 )
 
@@ -39,6 +41,7 @@ type endpoint struct {
 	UpdateTime        string `json:"updateTime,omitempty"`
 	ServiceAttachment string `json:"serviceAttachment,omitempty"`
 	EndpointIP        string `json:"endpointIp,omitempty"`
+	State             string `json:"state,omitempty"`
 }
 
 type endpointExternal struct {
@@ -67,47 +70,16 @@ func CreateEndpoint(name string, serviceAttachment string, description string, w
 	q.Set("endpointAttachmentId", name)
 	u.RawQuery = q.Encode()
 
-	respBody, err = apiclient.HttpClient(u.String(), payload)
+	if respBody, err = apiclient.HttpClient(u.String(), payload); err != nil {
+		return nil, err
+	}
 
 	if wait {
-		apiclient.ClientPrintHttpResponse.Set(false)
-		defer apiclient.ClientPrintHttpResponse.Set(apiclient.GetCmdPrintHttpResponseSetting())
-
-		o := operation{}
-		if err = json.Unmarshal(respBody, &o); err != nil {
+		if _, err = waitForOperation(respBody); err != nil {
 			return nil, err
 		}
-
-		operationId := filepath.Base(o.Name)
-		clilog.Info.Printf("Checking connection status for %s in %d seconds\n", operationId, interval)
-
-		stop := apiclient.Every(interval*time.Second, func(time.Time) bool {
-			var respBody []byte
-
-			if respBody, err = GetOperation(operationId); err != nil {
-				return false
-			}
-
-			if err = json.Unmarshal(respBody, &o); err != nil {
-				return false
-			}
-
-			if o.Done {
-				if o.Error != nil {
-					clilog.Error.Printf("Connection completed with error: %s\n", o.Error.Message)
-				} else {
-					clilog.Info.Println("Connection completed successfully!")
-				}
-				return false
-			} else {
-				clilog.Info.Printf("Connection status is: %t. Waiting %d seconds.\n", o.Done, interval)
-				return true
-			}
-		})
-
-		<-stop
 	}
-	return
+	return respBody, nil
 }
 
 // GetEndpoint
@@ -159,6 +131,58 @@ func ListEndpoints(pageSize int, pageToken string, filter string, orderBy string
 	return respBody, err
 }
 
+// ListAllEndpoints follows nextPageToken and returns all endpoint attachments
+func ListAllEndpoints(filter string, orderBy string) (respBody []byte, err error) {
+	apiclient.ClientPrintHttpResponse.Set(false)
+	l := endpoints{}
+	l.EndpointAttachments, err = listAllEndpoints(filter, orderBy)
+	apiclient.ClientPrintHttpResponse.Set(apiclient.GetCmdPrintHttpResponseSetting())
+	if err != nil {
+		return nil, err
+	}
+	if respBody, err = json.Marshal(l); err != nil {
+		return nil, err
+	}
+	return respBody, apiclient.PrettyPrint(respBody)
+}
+
+// PrintEndpointsTable prints the endpoint attachments in respBody as a table
+func PrintEndpointsTable(respBody []byte) error {
+	l := endpoints{}
+	if err := json.Unmarshal(respBody, &l); err != nil {
+		return fmt.Errorf("failed to unmarshall: %w", err)
+	}
+	apiclient.ClientPrintHttpResponse.Set(apiclient.GetCmdPrintHttpResponseSetting())
+	rows := [][]string{}
+	for _, e := range l.EndpointAttachments {
+		rows = append(rows, []string{filepath.Base(e.Name), e.ServiceAttachment, e.EndpointIP, e.State})
+	}
+	apiclient.PrintTable([]string{"NAME", "SERVICE ATTACHMENT", "ENDPOINT IP", "STATE"}, rows)
+	return nil
+}
+
+// listAllEndpoints
+func listAllEndpoints(filter string, orderBy string) (e []endpoint, err error) {
+	pageToken := ""
+
+	for {
+		l := endpoints{}
+		respBody, err := ListEndpoints(getListPageSize(), pageToken, filter, orderBy)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch endpoint attachments: %w", err)
+		}
+		if err = json.Unmarshal(respBody, &l); err != nil {
+			return nil, fmt.Errorf("failed to unmarshall: %w", err)
+		}
+		e = append(e, l.EndpointAttachments...)
+		pageToken = l.NextPageToken
+		if l.NextPageToken == "" {
+			break
+		}
+	}
+	return e, nil
+}
+
 // DeleteEndpoint
 func DeleteEndpoint(name string) (respBody []byte, err error) {
 	u, _ := url.Parse(apiclient.GetBaseConnectorEndpointAttachURL())
@@ -173,7 +197,7 @@ func FindEndpoint(name string) (found bool) {
 	var err error
 
 	for {
-		if respBody, err = ListEndpoints(maxPageSize, pageToken, "", ""); err != nil {
+		if respBody, err = ListEndpoints(getListPageSize(), pageToken, "", ""); err != nil {
 			return false
 		}
 		l := endpoints{}
@@ -194,6 +218,178 @@ func FindEndpoint(name string) (found bool) {
 	}
 }
 
+// EnsureEndpointAttachments verifies that an endpoint attachment exists for every
+// serviceAttachment referenced by the destinations of the connection. Missing endpoint
+// attachments are created from the definition files, named after the file, whose
+// serviceAttachment matches; otherwise an error is returned
+func EnsureEndpointAttachments(content []byte, endpointFiles []string) (err error) {
+	return ensureEndpointAttachments(nil, content, endpointFiles)
+}
+
+// ensureEndpointAttachments creates the missing endpoint attachments, or records them in plan
+func ensureEndpointAttachments(plan *createPlan, content []byte, endpointFiles []string) (err error) {
+	c := connection{}
+	if err = json.Unmarshal(content, &c); err != nil {
+		return fmt.Errorf("failed to unmarshall: %w", err)
+	}
+
+	serviceAttachments := []string{}
+	for _, config := range c.DestinationConfig {
+		for _, d := range config.Destinations {
+			if d.ServiceAttachment != "" && !slices.Contains(serviceAttachments, d.ServiceAttachment) {
+				serviceAttachments = append(serviceAttachments, d.ServiceAttachment)
+			}
+		}
+	}
+	if len(serviceAttachments) == 0 {
+		return nil
+	}
+
+	// map the service attachment of each definition file to the endpoint attachment name
+	definitions := map[string]string{}
+	for _, endpointFile := range endpointFiles {
+		endpointBytes, err := os.ReadFile(endpointFile)
+		if err != nil {
+			return err
+		}
+		e := endpointExternal{}
+		if err = json.Unmarshal(endpointBytes, &e); err != nil {
+			return fmt.Errorf("unable to parse %s: %w", endpointFile, err)
+		}
+		if e.ServiceAttachment == "" {
+			return fmt.Errorf("serviceAttachment not found in %s", endpointFile)
+		}
+		definitions[e.ServiceAttachment] = strings.TrimSuffix(filepath.Base(endpointFile), filepath.Ext(endpointFile))
+	}
+
+	apiclient.ClientPrintHttpResponse.Set(false)
+	defer apiclient.ClientPrintHttpResponse.Set(apiclient.GetCmdPrintHttpResponseSetting())
+
+	existing, err := listAllEndpoints("", "")
+	if err != nil {
+		return err
+	}
+
+	for _, serviceAttachment := range serviceAttachments {
+		if slices.ContainsFunc(existing, func(e endpoint) bool {
+			return e.ServiceAttachment == serviceAttachment
+		}) {
+			clilog.Info.Printf("Endpoint attachment for %s already exists\n", serviceAttachment)
+			continue
+		}
+		name, ok := definitions[serviceAttachment]
+		if !ok {
+			return fmt.Errorf("no endpoint attachment found for service attachment %s", serviceAttachment)
+		}
+		if plan == nil {
+			clilog.Info.Printf("Creating endpoint attachment %s for %s\n", name, serviceAttachment)
+		}
+		if err = plan.createEndpoint(name, serviceAttachment); err != nil {
+			return fmt.Errorf("unable to create endpoint attachment %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// endpointsFolderName is the sub folder of an export with the endpoint attachment definitions
+const endpointsFolderName = "endpoints"
+
+// ExportEndpoints writes the definition of every endpoint attachment referenced by the
+// destinations of the connections with labels to folder, one file per attachment in the
+// format of get --overrides. Import creates the missing ones before the connections
+func ExportEndpoints(folder string, labels map[string]string) (err error) {
+	apiclient.ClientPrintHttpResponse.Set(false)
+	defer apiclient.ClientPrintHttpResponse.Set(apiclient.GetCmdPrintHttpResponseSetting())
+
+	lconnections, err := listAllConnections("", "")
+	if err != nil {
+		return err
+	}
+	lconnections = filterConnectionsByLabels(lconnections, labels)
+
+	existing, err := listAllEndpoints("", "")
+	if err != nil {
+		return err
+	}
+
+	referenced := make(map[string]endpoint)
+	for _, lconnection := range lconnections {
+		for _, config := range lconnection.DestinationConfig {
+			for _, d := range config.Destinations {
+				for _, e := range existing {
+					if d.ServiceAttachment != "" && d.ServiceAttachment == e.ServiceAttachment {
+						referenced[e.Name] = e
+					} else if d.Host != "" && d.Host == e.EndpointIP {
+						clilog.Warning.Printf("connection %s uses the ip of endpoint attachment %s, "+
+							"update the host after import\n", getConnectionName(*lconnection.Name), filepath.Base(e.Name))
+						referenced[e.Name] = e
+					}
+				}
+			}
+		}
+	}
+
+	if len(referenced) == 0 {
+		return nil
+	}
+
+	if err = os.MkdirAll(folder, 0o755); err != nil {
+		return err
+	}
+	for name, e := range referenced {
+		content, err := json.Marshal(convertInternalToExternal(e))
+		if err != nil {
+			return err
+		}
+		fileName := filepath.Base(name) + ".json"
+		if err = apiclient.WriteByteArrayToFile(path.Join(folder, fileName), false, content); err != nil {
+			return err
+		}
+		clilog.Info.Printf("Downloaded endpoint attachment %s\n", fileName)
+	}
+	return nil
+}
+
+// importEndpoints creates the endpoint attachments defined in the endpoints sub folder
+// of an export that don't exist yet, and waits for them so the connections can use them
+func importEndpoints(folder string) (err error) {
+	endpointFiles, err := filepath.Glob(path.Join(folder, endpointsFolderName, "*.json"))
+	if err != nil || len(endpointFiles) == 0 {
+		return err
+	}
+
+	existing, err := listAllEndpoints("", "")
+	if err != nil {
+		return err
+	}
+
+	for _, endpointFile := range endpointFiles {
+		endpointBytes, err := os.ReadFile(endpointFile)
+		if err != nil {
+			return err
+		}
+		e := endpointExternal{}
+		if err = json.Unmarshal(endpointBytes, &e); err != nil {
+			return fmt.Errorf("unable to parse %s: %w", endpointFile, err)
+		}
+		if e.ServiceAttachment == "" {
+			return fmt.Errorf("serviceAttachment not found in %s", endpointFile)
+		}
+		if slices.ContainsFunc(existing, func(x endpoint) bool {
+			return x.ServiceAttachment == e.ServiceAttachment
+		}) {
+			clilog.Info.Printf("Endpoint attachment for %s already exists\n", e.ServiceAttachment)
+			continue
+		}
+		name := strings.TrimSuffix(filepath.Base(endpointFile), filepath.Ext(endpointFile))
+		clilog.Info.Printf("Creating endpoint attachment %s for %s\n", name, e.ServiceAttachment)
+		if _, err = CreateEndpoint(name, e.ServiceAttachment, "", true); err != nil {
+			return fmt.Errorf("unable to create endpoint attachment %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
 // convertInternalToExternal
 func convertInternalToExternal(internalVersion endpoint) (externalVersion endpointExternal) {
 	externalVersion = endpointExternal{}
@@ -0,0 +1,157 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package connections
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"internal/apiclient"
+	"internal/clilog"
+)
+
+// connectionStates are always reported by the health summary, even with a zero count
+var connectionStates = []string{"ACTIVE", "ERROR", "INACTIVE", "CREATING"}
+
+// HealthSummary lists all the connections in the region and prints the number of
+// connections by state and by auth type, followed by the connections in ERROR
+func HealthSummary() (err error) {
+	apiclient.ClientPrintHttpResponse.Set(false)
+	lconnections, err := listAllConnections("", "")
+	apiclient.ClientPrintHttpResponse.Set(apiclient.GetCmdPrintHttpResponseSetting())
+	if err != nil {
+		return err
+	}
+
+	byState := make(map[string]int)
+	byAuthType := make(map[string]int)
+	errorRows := [][]string{}
+
+	for _, c := range lconnections {
+		state := "STATE_UNSPECIFIED"
+		if c.Status != nil && c.Status.State != "" {
+			state = c.Status.State
+		}
+		byState[state]++
+
+		authType := c.AuthConfig.AuthType
+		if authType == "" {
+			authType = "NONE"
+		}
+		byAuthType[authType]++
+
+		if state == "ERROR" {
+			errorRows = append(errorRows, []string{
+				getConnectionName(*c.Name),
+				c.Status.Description, c.Status.Status,
+			})
+		}
+	}
+
+	if apiclient.GetNoOutput() {
+		return nil
+	}
+
+	clilog.HTTPResponse.Printf("%d connections in %s\n\n", len(lconnections), apiclient.GetRegion())
+	apiclient.PrintTable([]string{"STATE", "COUNT"}, countRows(byState, connectionStates))
+	clilog.HTTPResponse.Println()
+	apiclient.PrintTable([]string{"AUTH TYPE", "COUNT"}, countRows(byAuthType, nil))
+
+	if len(errorRows) > 0 {
+		clilog.HTTPResponse.Println()
+		apiclient.PrintTable([]string{"CONNECTION", "DESCRIPTION", "STATUS"}, errorRows)
+	}
+	return nil
+}
+
+// WaitForConnectionActive polls the connection until its state is ACTIVE, which can be
+// after its create operation is done. It returns an error with the status of the
+// connection if it ends in ERROR, or when the wait timeout passes
+func WaitForConnectionActive(name string) (err error) {
+	waitInterval := apiclient.GetWaitInterval()
+	if waitInterval == 0 {
+		waitInterval = interval * time.Second
+	}
+	start := time.Now()
+
+	c, err := GetConnection(name)
+	if err != nil {
+		return err
+	}
+	if done, err := checkConnectionActive(name, c, start); done {
+		return err
+	}
+
+	ticker := time.NewTicker(waitInterval)
+	defer ticker.Stop()
+	for {
+		<-ticker.C
+		if c, err = GetConnection(name); err != nil {
+			return err
+		}
+		if done, err := checkConnectionActive(name, c, start); done {
+			return err
+		}
+	}
+}
+
+// checkConnectionActive returns done when the connection is ACTIVE, in ERROR or the wait
+// timed out, with the error of the last two
+func checkConnectionActive(name string, c connection, start time.Time) (done bool, err error) {
+	state := ""
+	if c.Status != nil {
+		state = c.Status.State
+	}
+	switch state {
+	case "ACTIVE":
+		clilog.Info.Printf("Connection %s is ACTIVE\n", name)
+		return true, nil
+	case "ERROR":
+		return true, fmt.Errorf("connection %s is in state ERROR: %s %s", name, c.Status.Description, c.Status.Status)
+	}
+	if timeout := apiclient.GetWaitTimeout(); timeout > 0 && time.Since(start) >= timeout {
+		return true, fmt.Errorf("timed out after %s waiting for connection %s to be ACTIVE, state is %s",
+			timeout, name, state)
+	}
+	clilog.Info.Printf("Connection %s state is %s, waiting for ACTIVE\n", name, state)
+	return false, nil
+}
+
+// countRows returns the counts as sorted table rows, always including the keys in fixed
+func countRows(counts map[string]int, fixed []string) [][]string {
+	keys := append([]string{}, fixed...)
+	others := []string{}
+	for k := range counts {
+		found := false
+		for _, f := range fixed {
+			if k == f {
+				found = true
+				break
+			}
+		}
+		if !found {
+			others = append(others, k)
+		}
+	}
+	sort.Strings(others)
+	keys = append(keys, others...)
+
+	rows := [][]string{}
+	for _, k := range keys {
+		rows = append(rows, []string{k, fmt.Sprint(counts[k])})
+	}
+	return rows
+}
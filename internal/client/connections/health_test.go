@@ -0,0 +1,99 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package connections
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"internal/apiclient"
+)
+
+func TestCheckConnectionActive(t *testing.T) {
+	newTestClient(t)
+
+	tests := []struct {
+		state   string
+		done    bool
+		failure bool
+	}{
+		{"ACTIVE", true, false},
+		{"ERROR", true, true},
+		{"CREATING", false, false},
+		{"", false, false},
+	}
+	for _, test := range tests {
+		c := connection{Status: &connectionStatus{State: test.state, Description: "failed"}}
+		done, err := checkConnectionActive("c1", c, time.Now())
+		if done != test.done || (err != nil) != test.failure {
+			t.Errorf("state %q: expected done %t and error %t, got %t and %v", test.state, test.done, test.failure, done, err)
+		}
+	}
+
+	if err := apiclient.SetWaitTiming(time.Second, time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = apiclient.SetWaitTiming(0, 0) }()
+	c := connection{Status: &connectionStatus{State: "CREATING"}}
+	if done, err := checkConnectionActive("c1", c, time.Now().Add(-2*time.Minute)); !done || err == nil {
+		t.Errorf("expected a timeout error, got done %t and %v", done, err)
+	}
+}
+
+func TestWaitForConnectionActive(t *testing.T) {
+	newTestClient(t)
+	if err := apiclient.SetWaitTiming(time.Millisecond, time.Second); err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = apiclient.SetWaitTiming(0, 0) }()
+
+	// the connection becomes ACTIVE while it is polled
+	dir := newReplayDir(t)
+	writeGetRecording(t, dir, "c1", `{"status":{"state":"CREATING"}}`)
+	active := t.TempDir()
+	writeGetRecording(t, active, "c1", `{"status":{"state":"ACTIVE"}}`)
+	fileName := apiclient.GetRecordingFileName("GET", apiclient.GetBaseConnectorURL()+"/c1")
+	go func(from string, to string) {
+		time.Sleep(5 * time.Millisecond)
+		_ = os.Rename(from, to)
+	}(filepath.Join(active, fileName), filepath.Join(dir, fileName))
+	if err := WaitForConnectionActive("c1"); err != nil {
+		t.Errorf("expected the connection to become ACTIVE, got %v", err)
+	}
+
+	dir = newReplayDir(t)
+	writeGetRecording(t, dir, "c1", `{"status":{"state":"ERROR","description":"failed"}}`)
+	if err := WaitForConnectionActive("c1"); err == nil || !strings.Contains(err.Error(), "state ERROR") {
+		t.Errorf("expected the ERROR state to be returned, got %v", err)
+	}
+
+	if err := apiclient.SetWaitTiming(time.Millisecond, 5*time.Millisecond); err != nil {
+		t.Fatal(err)
+	}
+	dir = newReplayDir(t)
+	writeGetRecording(t, dir, "c1", `{"status":{"state":"CREATING"}}`)
+	if err := WaitForConnectionActive("c1"); err == nil || !strings.Contains(err.Error(), "timed out") {
+		t.Errorf("expected a timeout, got %v", err)
+	}
+
+	// a failed get ends the wait with its error
+	newReplayDir(t)
+	if err := WaitForConnectionActive("c1"); err == nil || !strings.Contains(err.Error(), "no recorded response") {
+		t.Errorf("expected the get error, got %v", err)
+	}
+}
@@ -0,0 +1,98 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package connections
+
+import (
+	"encoding/json"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"internal/apiclient"
+)
+
+// newTestClient sets up a client for my-project in us-west1 that prints nothing
+func newTestClient(t *testing.T) {
+	t.Helper()
+	apiclient.NewIntegrationClient(apiclient.IntegrationClientOptions{
+		ProjectID: "my-project",
+		Region:    "us-west1",
+		Token:     "token",
+		NoOutput:  true,
+	})
+	apiclient.SetAPI(apiclient.PROD)
+}
+
+// newReplayDir serves the responses of the test from the recordings in a new folder
+func newReplayDir(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	apiclient.SetReplayDir(dir)
+	t.Cleanup(func() { apiclient.SetReplayDir("") })
+	return dir
+}
+
+// writeRecording writes the replay recording of a request to rawURL
+func writeRecording(t *testing.T, dir string, method string, rawURL string, statusCode int, body string) {
+	t.Helper()
+	recording, _ := json.Marshal(map[string]interface{}{"statusCode": statusCode, "body": body})
+	if err := os.WriteFile(filepath.Join(dir, apiclient.GetRecordingFileName(method, rawURL)),
+		recording, 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// writeGetRecording writes the replay recording of a get of the connection
+func writeGetRecording(t *testing.T, dir string, name string, body string) {
+	t.Helper()
+	writeRecording(t, dir, "GET", apiclient.GetBaseConnectorURL()+"/"+name, 200, body)
+}
+
+// writeListRecording writes the replay recording of a connections list page
+func writeListRecording(t *testing.T, dir string, orderBy string, pageToken string, body string) {
+	t.Helper()
+	writeListRecordingAt(t, dir, apiclient.GetBaseConnectorURL(), orderBy, pageToken, body)
+}
+
+// writeListRecordingAt writes the replay recording of a list page of the connections at
+// connectionsURL
+func writeListRecordingAt(t *testing.T, dir string, connectionsURL string, orderBy string, pageToken string,
+	body string,
+) {
+	t.Helper()
+	q := url.Values{}
+	q.Set("pageSize", strconv.Itoa(getListPageSize()))
+	if orderBy != "" {
+		q.Set("orderBy", orderBy)
+	}
+	if pageToken != "" {
+		q.Set("pageToken", pageToken)
+	}
+	writeRecording(t, dir, "GET", connectionsURL+"?"+q.Encode(), 200, body)
+}
+
+// writeLaunchStageRecording writes the replay recording of a get of the connector version
+// in launchStage
+func writeLaunchStageRecording(t *testing.T, dir string, provider string, connector string, version string,
+	launchStage string,
+) {
+	t.Helper()
+	name := "projects/my-project/locations/global/providers/" + provider + "/connectors/" + connector +
+		"/versions/" + version
+	writeRecording(t, dir, "GET", apiclient.GetBaseConnectorProvidersURL()+"/"+provider+"/connectors/"+
+		connector+"/versions/"+version, 200, `{"name":"`+name+`","launchStage":"`+launchStage+`"}`)
+}
@@ -15,15 +15,32 @@
 package connections
 
 import (
+	"encoding/json"
 	"fmt"
 	"net/url"
+	"os"
 	"path"
+	"sort"
+	"strings"
 
 	"internal/apiclient"
+	"internal/clilog"
 )
 
 var validMemberTypes = []string{"serviceAccount", "group", "user", "domain"}
 
+type iamPolicy struct {
+	Version  int          `json:"version,omitempty"`
+	Etag     string       `json:"etag,omitempty"`
+	Bindings []iamBinding `json:"bindings,omitempty"`
+}
+
+type iamBinding struct {
+	Role      string                 `json:"role,omitempty"`
+	Members   []string               `json:"members,omitempty"`
+	Condition map[string]interface{} `json:"condition,omitempty"`
+}
+
 // GetIAM
 func GetIAM(name string) (respBody []byte, err error) {
 	u, _ := url.Parse(apiclient.GetBaseConnectorURL())
@@ -32,14 +49,168 @@ func GetIAM(name string) (respBody []byte, err error) {
 	return respBody, err
 }
 
-// SetIAM
-func SetIAM(name string, memberName string, permission string, memberType string) (err error) {
+// PrintIAM prints the IAM policy in respBody as a table of roles and members, or as
+// JSON when asJSON is set. If member is set, only the roles granted to it are printed
+func PrintIAM(respBody []byte, member string, asJSON bool) (err error) {
+	p := iamPolicy{}
+	if err = json.Unmarshal(respBody, &p); err != nil {
+		return fmt.Errorf("failed to unmarshall: %w", err)
+	}
+
+	if member != "" {
+		bindings := []iamBinding{}
+		for _, b := range p.Bindings {
+			for _, m := range b.Members {
+				if m == member {
+					bindings = append(bindings, iamBinding{Role: b.Role, Members: []string{m}, Condition: b.Condition})
+					break
+				}
+			}
+		}
+		p.Bindings = bindings
+	}
+
+	if asJSON {
+		if respBody, err = json.Marshal(p); err != nil {
+			return err
+		}
+		return apiclient.PrettyPrint(respBody)
+	}
+
+	rows := [][]string{}
+	for _, b := range p.Bindings {
+		rows = append(rows, []string{b.Role, strings.Join(b.Members, ",")})
+	}
+	apiclient.PrintTable([]string{"ROLE", "MEMBERS"}, rows)
+	return nil
+}
+
+// SetIAM grants permission to the member on the connection. With dryRun, the new policy
+// and the bindings that change are printed and the policy is not set
+func SetIAM(name string, memberName string, permission string, memberType string, dryRun bool) (err error) {
 	if !isValidMemberType(memberType) {
 		return fmt.Errorf("invalid memberType. Valid types are %v", validMemberTypes)
 	}
+	if dryRun {
+		current, updated, err := apiclient.GetConnectorIAMPermissionUpdate(name, memberName, permission, memberType)
+		if err != nil {
+			return err
+		}
+		return printIAMChange(current, updated)
+	}
 	return apiclient.SetConnectorIAMPermission(name, memberName, permission, memberType)
 }
 
+// SetIAMFromFile replaces the IAM policy of the connection with the policy in policyFile,
+// in the format printed by iam get --json. The policy must carry the etag it was read
+// with, so changes made to the connection policy since are not overwritten. With dryRun,
+// the policy and the bindings that change are printed and the policy is not set
+func SetIAMFromFile(name string, policyFile string, dryRun bool) (respBody []byte, err error) {
+	content, err := os.ReadFile(policyFile)
+	if err != nil {
+		return nil, fmt.Errorf("unable to open file %w", err)
+	}
+	p := iamPolicy{}
+	if err = json.Unmarshal(content, &p); err != nil {
+		return nil, fmt.Errorf("unable to parse policy file %s: %w", policyFile, err)
+	}
+	if p.Etag == "" {
+		return nil, fmt.Errorf("policy file %s has no etag; get the current policy with "+
+			"iam get --json and edit it", policyFile)
+	}
+
+	if dryRun {
+		apiclient.ClientPrintHttpResponse.Set(false)
+		current, err := GetIAM(name)
+		apiclient.ClientPrintHttpResponse.Set(apiclient.GetCmdPrintHttpResponseSetting())
+		if err != nil {
+			return nil, err
+		}
+		return nil, printIAMChange(current, content)
+	}
+
+	// send the policy as read, so fields we don't model like auditConfigs are kept
+	payload, err := json.Marshal(map[string]json.RawMessage{"policy": content})
+	if err != nil {
+		return nil, err
+	}
+
+	u, _ := url.Parse(apiclient.GetBaseConnectorURL())
+	u.Path = path.Join(u.Path, name+":setIamPolicy")
+	respBody, err = apiclient.HttpClient(u.String(), string(payload))
+	if err != nil && isEtagMismatch(err) {
+		return nil, fmt.Errorf("the IAM policy of connection %s changed since etag %s was read; "+
+			"get it again with iam get --json and reapply the changes: %w", name, p.Etag, err)
+	}
+	return respBody, err
+}
+
+// isEtagMismatch returns true if the setIamPolicy error is caused by a stale etag
+func isEtagMismatch(err error) bool {
+	return strings.HasPrefix(err.Error(), "Conflict") || strings.Contains(err.Error(), "ABORTED") ||
+		strings.Contains(strings.ToLower(err.Error()), "etag")
+}
+
+// printIAMChange prints the updated policy and the members added to or removed from
+// each role of the current policy
+func printIAMChange(current []byte, updated []byte) (err error) {
+	c, u := iamPolicy{}, iamPolicy{}
+	if err = json.Unmarshal(current, &c); err != nil {
+		return fmt.Errorf("failed to unmarshall: %w", err)
+	}
+	if err = json.Unmarshal(updated, &u); err != nil {
+		return fmt.Errorf("failed to unmarshall: %w", err)
+	}
+	if c.Etag != "" && u.Etag != "" && c.Etag != u.Etag {
+		clilog.Warning.Printf("the policy etag %s does not match the current etag %s; "+
+			"setting it would fail\n", u.Etag, c.Etag)
+	}
+
+	if err = apiclient.PrettyPrint(updated); err != nil {
+		return err
+	}
+	rows := diffIAMPolicy(c, u)
+	if len(rows) == 0 {
+		clilog.Info.Println("dry run: no bindings change")
+		return nil
+	}
+	apiclient.PrintTable([]string{"CHANGE", "ROLE", "MEMBER"}, rows)
+	return nil
+}
+
+// diffIAMPolicy returns a row for each member added (+) to or removed (-) from a role,
+// sorted by role and member
+func diffIAMPolicy(current iamPolicy, updated iamPolicy) (rows [][]string) {
+	members := func(p iamPolicy) map[[2]string]bool {
+		m := map[[2]string]bool{}
+		for _, b := range p.Bindings {
+			for _, member := range b.Members {
+				m[[2]string{b.Role, member}] = true
+			}
+		}
+		return m
+	}
+	c, u := members(current), members(updated)
+
+	for k := range u {
+		if !c[k] {
+			rows = append(rows, []string{"+", k[0], k[1]})
+		}
+	}
+	for k := range c {
+		if !u[k] {
+			rows = append(rows, []string{"-", k[0], k[1]})
+		}
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		if rows[i][1] != rows[j][1] {
+			return rows[i][1] < rows[j][1]
+		}
+		return rows[i][2] < rows[j][2]
+	})
+	return rows
+}
+
 // TestIAM
 func TestIAM(name string, resource string) (respBody []byte, err error) {
 	u, _ := url.Parse(apiclient.GetBaseConnectorURL())
@@ -0,0 +1,43 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package connections
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDiffIAMPolicy(t *testing.T) {
+	current := iamPolicy{Bindings: []iamBinding{
+		{Role: "roles/connectors.admin", Members: []string{"user:a@example.com", "user:b@example.com"}},
+		{Role: "roles/connectors.viewer", Members: []string{"group:g@example.com"}},
+	}}
+	updated := iamPolicy{Bindings: []iamBinding{
+		{Role: "roles/connectors.admin", Members: []string{"user:a@example.com"}},
+		{Role: "roles/connectors.invoker", Members: []string{"serviceAccount:sa@p.iam.gserviceaccount.com"}},
+		{Role: "roles/connectors.viewer", Members: []string{"group:g@example.com"}},
+	}}
+
+	want := [][]string{
+		{"-", "roles/connectors.admin", "user:b@example.com"},
+		{"+", "roles/connectors.invoker", "serviceAccount:sa@p.iam.gserviceaccount.com"},
+	}
+	if got := diffIAMPolicy(current, updated); !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+	if got := diffIAMPolicy(current, current); len(got) != 0 {
+		t.Errorf("expected no changes, got %v", got)
+	}
+}
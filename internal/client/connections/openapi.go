@@ -0,0 +1,201 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package connections
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"internal/apiclient"
+	"internal/clilog"
+
+	"gopkg.in/yaml.v3"
+)
+
+// openAPIConnector is the generic REST connector the skeleton is generated for
+const openAPIConnector = "rest"
+
+// openAPISpec holds the parts of an OpenAPI 3 or Swagger 2 spec used for the skeleton
+type openAPISpec struct {
+	OpenAPI string `yaml:"openapi"`
+	Swagger string `yaml:"swagger"`
+	Info    struct {
+		Title string `yaml:"title"`
+	} `yaml:"info"`
+	Servers []struct {
+		URL       string `yaml:"url"`
+		Variables map[string]struct {
+			Default string `yaml:"default"`
+		} `yaml:"variables"`
+	} `yaml:"servers"`
+	Host       string   `yaml:"host"`
+	BasePath   string   `yaml:"basePath"`
+	Schemes    []string `yaml:"schemes"`
+	Components struct {
+		SecuritySchemes map[string]openAPISecurityScheme `yaml:"securitySchemes"`
+	} `yaml:"components"`
+	SecurityDefinitions map[string]openAPISecurityScheme `yaml:"securityDefinitions"`
+}
+
+type openAPISecurityScheme struct {
+	Type   string `yaml:"type"`
+	Scheme string `yaml:"scheme"`
+	Name   string `yaml:"name"`
+	In     string `yaml:"in"`
+	Flow   string `yaml:"flow"` // swagger 2 oauth2 flow
+	Flows  map[string]struct {
+		TokenURL string `yaml:"tokenUrl"`
+	} `yaml:"flows"`
+}
+
+// GenerateFromOpenAPI generates a skeleton connection JSON for the generic REST connector
+// from an OpenAPI 3 or Swagger 2 spec, in JSON or YAML. The base URL of the API becomes
+// the destination and the security schemes become suggested auth and config variables.
+// The skeleton is printed for editing, it is not created
+func GenerateFromOpenAPI(specFile string, provider string) (respBody []byte, err error) {
+	content, err := os.ReadFile(specFile)
+	if err != nil {
+		return nil, fmt.Errorf("unable to open file %w", err)
+	}
+	spec := openAPISpec{}
+	if err = yaml.Unmarshal(content, &spec); err != nil {
+		return nil, fmt.Errorf("unable to parse OpenAPI spec %s: %w", specFile, err)
+	}
+	if spec.OpenAPI == "" && spec.Swagger == "" {
+		return nil, fmt.Errorf("%s is not an OpenAPI spec, openapi or swagger must be set", specFile)
+	}
+
+	version := 1
+	c := connectionRequest{}
+	c.ConnectorDetails = &connectorDetails{Name: openAPIConnector, Provider: provider, Version: &version}
+	if spec.Info.Title != "" {
+		c.Description = &spec.Info.Title
+	}
+
+	baseURL := getOpenAPIBaseURL(spec)
+	if baseURL == "" {
+		clilog.Warning.Printf("%s has no absolute server url, set the base_url destination\n", specFile)
+		baseURL = "<base url>"
+	}
+	c.DestinationConfigs = &[]destinationConfig{{
+		Key:          "base_url",
+		Destinations: []destination{getOpenAPIDestination(baseURL)},
+	}}
+
+	securitySchemes := spec.Components.SecuritySchemes
+	if len(securitySchemes) == 0 {
+		securitySchemes = spec.SecurityDefinitions
+	}
+	var configVars []configVar
+	c.AuthConfig, configVars = getOpenAPIAuth(securitySchemes)
+	if len(configVars) > 0 {
+		c.ConfigVariables = &configVars
+	}
+
+	if respBody, err = json.Marshal(c); err != nil {
+		return nil, err
+	}
+	return respBody, apiclient.PrettyPrint(respBody)
+}
+
+// getOpenAPIBaseURL returns the absolute url of the first server of the spec, with
+// the server variables replaced by their defaults, or an empty string
+func getOpenAPIBaseURL(spec openAPISpec) string {
+	if spec.Swagger != "" {
+		if spec.Host == "" {
+			return ""
+		}
+		scheme := "https"
+		if len(spec.Schemes) > 0 {
+			scheme = spec.Schemes[0]
+		}
+		return scheme + "://" + spec.Host + spec.BasePath
+	}
+	if len(spec.Servers) == 0 {
+		return ""
+	}
+	baseURL := spec.Servers[0].URL
+	for name, variable := range spec.Servers[0].Variables {
+		baseURL = strings.ReplaceAll(baseURL, "{"+name+"}", variable.Default)
+	}
+	if u, err := url.Parse(baseURL); err != nil || u.Scheme == "" || u.Host == "" {
+		return ""
+	}
+	return baseURL
+}
+
+// getOpenAPIDestination returns the destination of the base url. The REST connector
+// takes the whole url as the host, the port is only set when the url has an explicit one
+func getOpenAPIDestination(baseURL string) destination {
+	d := destination{Host: baseURL}
+	if u, err := url.Parse(baseURL); err == nil && u.Port() != "" {
+		d.Port, _ = strconv.Atoi(u.Port())
+	}
+	return d
+}
+
+// getOpenAPIAuth returns a placeholder auth config and config variables for the first
+// security scheme, in name order, the connector can use
+func getOpenAPIAuth(securitySchemes map[string]openAPISecurityScheme) (a *authConfig, configVars []configVar) {
+	names := make([]string, 0, len(securitySchemes))
+	for name := range securitySchemes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		s := securitySchemes[name]
+		switch {
+		case s.Type == "basic" || (s.Type == "http" && strings.EqualFold(s.Scheme, "basic")):
+			return &authConfig{
+				AuthType: "USER_PASSWORD",
+				UserPassword: &userPassword{
+					Username:        "<username>",
+					PasswordDetails: &secretDetails{SecretName: "<secret name>", Reference: "<path to password file>"},
+				},
+			}, nil
+		case s.Type == "oauth2" && (s.Flow == "application" || hasClientCredentialsFlow(s)):
+			return &authConfig{
+				AuthType: "OAUTH2_CLIENT_CREDENTIALS",
+				Oauth2ClientCredentials: &oauth2ClientCredentials{
+					ClientId:            "<client id>",
+					ClientSecretDetails: &secretDetails{SecretName: "<secret name>", Reference: "<path to client secret file>"},
+				},
+			}, nil
+		case s.Type == "apiKey":
+			keyName, keyLocation := s.Name, s.In
+			return nil, []configVar{
+				{Key: "api_key", SecretDetails: &secretDetails{SecretName: "<secret name>", Reference: "<path to api key file>"}},
+				{Key: "api_key_name", StringValue: &keyName},
+				{Key: "api_key_location", StringValue: &keyLocation},
+			}
+		case s.Type == "http" && strings.EqualFold(s.Scheme, "bearer"):
+			return nil, []configVar{
+				{Key: "bearer_token", SecretDetails: &secretDetails{SecretName: "<secret name>", Reference: "<path to token file>"}},
+			}
+		}
+	}
+	return nil, nil
+}
+
+func hasClientCredentialsFlow(s openAPISecurityScheme) bool {
+	_, ok := s.Flows["clientCredentials"]
+	return ok
+}
@@ -0,0 +1,82 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package connections
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestGetOpenAPIBaseURL(t *testing.T) {
+	tests := []struct {
+		name     string
+		spec     string
+		expected string
+	}{
+		{
+			"openapi 3 server",
+			"openapi: 3.0.0\nservers:\n  - url: https://api.example.com/v1\n",
+			"https://api.example.com/v1",
+		},
+		{
+			"openapi 3 server variables",
+			"openapi: 3.0.0\nservers:\n  - url: https://{region}.example.com:8443\n" +
+				"    variables:\n      region:\n        default: eu\n",
+			"https://eu.example.com:8443",
+		},
+		{
+			"openapi 3 relative server",
+			`{"openapi": "3.0.0", "servers": [{"url": "/v1"}]}`,
+			"",
+		},
+		{
+			"swagger 2",
+			`{"swagger": "2.0", "host": "api.example.com", "basePath": "/v2", "schemes": ["http"]}`,
+			"http://api.example.com/v2",
+		},
+	}
+
+	for _, test := range tests {
+		spec := openAPISpec{}
+		if err := yaml.Unmarshal([]byte(test.spec), &spec); err != nil {
+			t.Fatalf("%s: %v", test.name, err)
+		}
+		if got := getOpenAPIBaseURL(spec); got != test.expected {
+			t.Errorf("%s: expected %q, got %q", test.name, test.expected, got)
+		}
+	}
+}
+
+func TestGenerateFromOpenAPIValidates(t *testing.T) {
+	newTestClient(t)
+
+	specFile := filepath.Join(t.TempDir(), "spec.yaml")
+	if err := os.WriteFile(specFile, []byte("openapi: 3.0.0\ninfo:\n  title: Example\n"+
+		"servers:\n  - url: https://api.example.com/v1\n"+
+		"components:\n  securitySchemes:\n    basic:\n      type: http\n      scheme: basic\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	content, err := GenerateFromOpenAPI(specFile, "gcp")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if problems := Validate("example.json", content); len(problems) != 0 {
+		t.Errorf("expected the generated connection to be valid, got %v", problems)
+	}
+}
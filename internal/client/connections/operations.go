@@ -15,11 +15,15 @@
 package connections
 
 import (
+	"encoding/json"
+	"fmt"
 	"net/url"
 	"path"
 	"strconv"
+	"time"
 
 	"internal/apiclient"
+	"internal/clilog"
 )
 
 // GetOperation
@@ -59,3 +63,77 @@ func CancelOperation(name string) (respBody []byte, err error) {
 	respBody, err = apiclient.HttpClient(u.String(), "")
 	return respBody, err
 }
+
+// waitForOperation waits until the operation in respBody is done and returns the final operation
+func waitForOperation(respBody []byte) ([]byte, error) {
+	waitInterval := apiclient.GetWaitInterval()
+	if waitInterval == 0 {
+		waitInterval = interval * time.Second
+	}
+	return apiclient.WaitForOperation(respBody, waitInterval, GetOperation)
+}
+
+// operationConnection holds the identity of the connection in the response of an operation
+type operationConnection struct {
+	Name             string            `json:"name,omitempty"`
+	ConnectorVersion string            `json:"connectorVersion,omitempty"`
+	ServiceAccount   string            `json:"serviceAccount,omitempty"`
+	Status           *connectionStatus `json:"status,omitempty"`
+}
+
+// printOperationConnection logs the name, connector version, service account and state of
+// the connection returned by a completed operation, so no follow up get is needed
+func printOperationConnection(operationBody []byte) {
+	o := operation{}
+	if err := json.Unmarshal(operationBody, &o); err != nil || o.Response == nil {
+		return
+	}
+	response, err := json.Marshal(*o.Response)
+	if err != nil {
+		return
+	}
+	c := operationConnection{}
+	if err = json.Unmarshal(response, &c); err != nil || c.Name == "" {
+		return
+	}
+
+	clilog.Info.Printf("connection %s\n", c.Name)
+	if c.ConnectorVersion != "" {
+		clilog.Info.Printf("  connectorVersion: %s\n", c.ConnectorVersion)
+	}
+	if c.ServiceAccount != "" {
+		clilog.Info.Printf("  serviceAccount: %s\n", c.ServiceAccount)
+	}
+	if c.Status != nil && c.Status.State != "" {
+		clilog.Info.Printf("  state: %s\n", c.Status.State)
+	}
+}
+
+// WaitForOperationID waits until the operation id is done and prints the final operation.
+// The id can be the operation name or its full resource path
+func WaitForOperationID(id string) (respBody []byte, err error) {
+	apiclient.ClientPrintHttpResponse.Set(false)
+	respBody, err = GetOperation(path.Base(id))
+	apiclient.ClientPrintHttpResponse.Set(apiclient.GetCmdPrintHttpResponseSetting())
+	if err != nil {
+		return nil, fmt.Errorf("unable to get operation %s: %w", id, err)
+	}
+
+	o := operation{}
+	if err = json.Unmarshal(respBody, &o); err != nil {
+		return nil, fmt.Errorf("failed to unmarshall: %w", err)
+	}
+	if !o.Done {
+		operationBody, err := waitForOperation(respBody)
+		if operationBody == nil {
+			return nil, err
+		}
+		apiclient.PrettyPrint(operationBody)
+		return operationBody, err
+	}
+	apiclient.PrettyPrint(respBody)
+	if o.Error != nil {
+		return respBody, fmt.Errorf("operation %s completed with error: %s", path.Base(id), o.Error.Message)
+	}
+	return respBody, nil
+}
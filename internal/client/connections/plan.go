@@ -0,0 +1,122 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package connections
+
+import (
+	"fmt"
+
+	"internal/apiclient"
+)
+
+// createPlan records the side effects of a create instead of executing them. The
+// methods execute the side effect when the plan is nil
+type createPlan struct {
+	rows [][]string
+}
+
+func (p *createPlan) add(kind string, detail string) {
+	p.rows = append(p.rows, []string{kind, detail})
+}
+
+// addSecret records the secret and returns the version the connection would reference
+func (p *createPlan) addSecret(secretName string) string {
+	p.add("SECRET", fmt.Sprintf("create or add a version to projects/%s/secrets/%s",
+		apiclient.GetProjectID(), secretName))
+	return fmt.Sprintf("projects/%s/secrets/%s/versions/latest", apiclient.GetProjectID(), secretName)
+}
+
+// createServiceAccount creates the service account if it does not exist
+func (p *createPlan) createServiceAccount(serviceAccount string) error {
+	if p != nil {
+		p.add("SERVICE ACCOUNT", fmt.Sprintf("create %s if it does not exist", serviceAccount))
+		return nil
+	}
+	return apiclient.CreateServiceAccount(serviceAccount)
+}
+
+// setSecretManagerIAMPermission grants the service account access to the secret
+func (p *createPlan) setSecretManagerIAMPermission(project string, secretName string, serviceAccount string) error {
+	if p != nil {
+		p.add("IAM", fmt.Sprintf("%s on secret projects/%s/secrets/%s", serviceAccount, project, secretName))
+		return nil
+	}
+	return apiclient.SetSecretManagerIAMPermission(project, secretName, serviceAccount)
+}
+
+// setIAMPermission calls set to apply the IAM binding described by binding
+func (p *createPlan) setIAMPermission(binding string, set func() error) error {
+	if p != nil {
+		p.add("IAM", binding)
+		return nil
+	}
+	return set()
+}
+
+// createEndpoint creates the endpoint attachment and waits for it to be ready
+func (p *createPlan) createEndpoint(name string, serviceAttachment string) error {
+	if p != nil {
+		p.add("ENDPOINT ATTACHMENT", fmt.Sprintf("create %s for %s", name, serviceAttachment))
+		return nil
+	}
+	// wait for the endpoint attachment, the connection cannot use it before it is ready
+	_, err := CreateEndpoint(name, serviceAttachment, "", true)
+	return err
+}
+
+// PlanCreate runs the create logic for the connection without executing it and prints
+// the endpoint attachments, service accounts, secrets and IAM bindings the create would
+// apply. The endpoint attachments are checked when checkEndpoints is set or endpoint
+// files are passed
+func PlanCreate(name string, content []byte, serviceAccountName string, serviceAccountProject string,
+	encryptionKey string, grantPermission bool, createSecret bool, checkEndpoints bool,
+	endpointFiles []string, opts CreateOptions,
+) (err error) {
+	plan := &createPlan{}
+	if err = planCreate(plan, name, content, serviceAccountName, serviceAccountProject,
+		encryptionKey, grantPermission, createSecret, checkEndpoints, endpointFiles, opts); err != nil {
+		return err
+	}
+	apiclient.PrintTable([]string{"KIND", "DETAIL"}, plan.rows)
+	return nil
+}
+
+// planCreate records the side effects of the create of the connection in plan
+func planCreate(plan *createPlan, name string, content []byte, serviceAccountName string,
+	serviceAccountProject string, encryptionKey string, grantPermission bool, createSecret bool,
+	checkEndpoints bool, endpointFiles []string, opts CreateOptions,
+) (err error) {
+	if err = ValidateConnectionName(name); err != nil {
+		return err
+	}
+
+	serviceAccountName, serviceAccountProject = splitServiceAccount(serviceAccountName, serviceAccountProject)
+
+	if checkEndpoints || len(endpointFiles) > 0 {
+		if err = ensureEndpointAttachments(plan, content, endpointFiles); err != nil {
+			return err
+		}
+	}
+
+	apiclient.ClientPrintHttpResponse.Set(false)
+	defer apiclient.ClientPrintHttpResponse.Set(apiclient.GetCmdPrintHttpResponseSetting())
+
+	if _, err = prepareConnection(plan, content, serviceAccountName, serviceAccountProject,
+		encryptionKey, grantPermission, createSecret, false, false, opts); err != nil {
+		return err
+	}
+	plan.add("CONNECTION", fmt.Sprintf("create projects/%s/locations/%s/connections/%s",
+		apiclient.GetProjectID(), apiclient.GetRegion(), name))
+	return nil
+}
@@ -0,0 +1,64 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package connections
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"internal/apiclient"
+)
+
+func TestPlanCreate(t *testing.T) {
+	newTestClient(t)
+
+	// no endpoint attachment exists yet
+	dir := newReplayDir(t)
+	writeRecording(t, dir, "GET", apiclient.GetBaseConnectorEndpointAttachURL()+
+		"?pageSize="+strconv.Itoa(getListPageSize()), 200, `{}`)
+	writeLaunchStageRecording(t, dir, "gcp", "pubsub", "1", gaLaunchStage)
+
+	const serviceAttachment = "projects/sp/regions/us-west1/serviceAttachments/sa1"
+	endpointFile := filepath.Join(t.TempDir(), "ep1.json")
+	if err := os.WriteFile(endpointFile, []byte(`{"serviceAttachment":"`+serviceAttachment+`"}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	content := []byte(`{"connectorVersion":"projects/my-project/locations/global/providers/gcp/connectors/pubsub/versions/1",` +
+		`"destinationConfigs":[{"key":"url","destinations":[{"serviceAttachment":"` + serviceAttachment + `"}]}],` +
+		`"configVariables":[{"key":"project_id","stringValue":"my-project"},{"key":"topic_id","stringValue":"t1"},` +
+		`{"key":"api_key","secretDetails":{"secretName":"api-key","value":"v"}}]}`)
+
+	plan := &createPlan{}
+	if err := planCreate(plan, "c1", content, "conn-sa", "", "", true, true, false,
+		[]string{endpointFile}, CreateOptions{}); err != nil {
+		t.Fatalf("planCreate returned %v", err)
+	}
+
+	kinds := []string{}
+	for _, row := range plan.rows {
+		kinds = append(kinds, row[0])
+	}
+	want := []string{"ENDPOINT ATTACHMENT", "SERVICE ACCOUNT", "IAM", "SECRET", "IAM", "CONNECTION"}
+	if strings.Join(kinds, ",") != strings.Join(want, ",") {
+		t.Errorf("expected %v, got %v", want, plan.rows)
+	}
+	if !strings.Contains(plan.rows[0][1], "create ep1 for "+serviceAttachment) {
+		t.Errorf("expected the endpoint attachment to be planned, got %v", plan.rows[0])
+	}
+}
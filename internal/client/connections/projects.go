@@ -0,0 +1,70 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package connections
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"internal/apiclient"
+)
+
+// ListAcrossProjects lists the connections in the current region of each project that
+// match the filter, in orderBy order, and prints them as a single table with the project
+// of each connection
+func ListAcrossProjects(projectIDs []string, filter string, orderBy string) (err error) {
+	rows, errs := listProjectConnections(projectIDs, filter, orderBy)
+
+	apiclient.PrintTable([]string{"PROJECT", "NAME", "CONNECTOR", "VERSION", "STATE", "SUSPENDED"}, rows)
+
+	if len(errs) > 0 {
+		return errors.New(strings.Join(errs, "\n"))
+	}
+	return nil
+}
+
+// listProjectConnections returns a table row for each connection of the projects and
+// an error for each project that could not be listed. The project of the client is
+// not changed
+func listProjectConnections(projectIDs []string, filter string, orderBy string) (rows [][]string, errs []string) {
+	apiclient.ClientPrintHttpResponse.Set(false)
+	defer apiclient.ClientPrintHttpResponse.Set(apiclient.GetCmdPrintHttpResponseSetting())
+
+	for _, projectID := range projectIDs {
+		lconnections, err := listAllConnectionsAt(
+			apiclient.GetBaseConnectorURLWithProject(projectID, apiclient.GetRegion()), filter, orderBy)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %v", projectID, err))
+			continue
+		}
+		for _, c := range lconnections {
+			connector, version, state := "", "", ""
+			if c.ConnectorVersion != nil {
+				connector = getConnectorName(*c.ConnectorVersion)
+				version = getConnectorVersionId(*c.ConnectorVersion)
+			}
+			if c.Status != nil {
+				state = c.Status.State
+			}
+			rows = append(rows, []string{
+				projectID, getConnectionName(*c.Name),
+				connector, version, state, strconv.FormatBool(c.Suspended),
+			})
+		}
+	}
+	return rows, errs
+}
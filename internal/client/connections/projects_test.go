@@ -0,0 +1,49 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package connections
+
+import (
+	"reflect"
+	"testing"
+
+	"internal/apiclient"
+)
+
+func TestListProjectConnections(t *testing.T) {
+	newTestClient(t)
+	dir := newReplayDir(t)
+	orderBy := "name"
+	writeListRecordingAt(t, dir, apiclient.GetBaseConnectorURLWithProject("p1", "us-west1"), orderBy, "",
+		`{"connections":[{"name":"projects/p1/locations/us-west1/connections/c1",`+
+			`"connectorVersion":"projects/p1/locations/global/providers/gcp/connectors/pubsub/versions/1",`+
+			`"status":{"state":"ACTIVE"}}]}`)
+
+	apiclient.ClientPrintHttpResponse.Set(true)
+	rows, errs := listProjectConnections([]string{"p1", "p2"}, "", orderBy)
+
+	expected := [][]string{{"p1", "c1", "pubsub", "1", "ACTIVE", "false"}}
+	if !reflect.DeepEqual(rows, expected) {
+		t.Errorf("expected rows %v, got %v", expected, rows)
+	}
+	if len(errs) != 1 {
+		t.Errorf("expected an error for p2, got %v", errs)
+	}
+	if projectID := apiclient.GetProjectID(); projectID != "my-project" {
+		t.Errorf("expected the project to stay my-project, got %s", projectID)
+	}
+	if !apiclient.ClientPrintHttpResponse.Get() {
+		t.Error("expected the print setting to be restored")
+	}
+}
@@ -0,0 +1,122 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package connections
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"internal/clilog"
+)
+
+// getFolderConnectionNames returns the names of the connections imported from files. Any
+// file whose name can't be derived fails it, since pruning against an incomplete set
+// would delete connections the folder defines
+func getFolderConnectionNames(files []string, defaults map[string]interface{},
+	sanitize bool,
+) (names map[string]bool, err error) {
+	names = make(map[string]bool)
+	for _, file := range files {
+		content, err := readConnectionFile(file, defaults)
+		if err != nil {
+			return nil, err
+		}
+		name, err := deriveConnectionName(file, content)
+		if err != nil {
+			return nil, err
+		}
+		if sanitize {
+			name = SanitizeConnectionName(name)
+		}
+		names[name] = true
+	}
+	return names, nil
+}
+
+// getPruneCandidates returns the live connections, sorted by name, that are not in local
+func getPruneCandidates(local map[string]bool) (candidates []string, err error) {
+	lconnections, err := listAllConnections("", "name")
+	if err != nil {
+		return nil, err
+	}
+	for _, lconnection := range lconnections {
+		if lconnection.Name == nil {
+			continue
+		}
+		if name := getConnectionName(*lconnection.Name); !local[name] {
+			candidates = append(candidates, name)
+		}
+	}
+	return candidates, nil
+}
+
+// pruneConnections deletes the live connections that are not in local. With dryRun they
+// are only listed. Unless force is set, the deletes are confirmed on the terminal
+func pruneConnections(local map[string]bool, force bool, dryRun bool, wait bool) (err error) {
+	candidates, err := getPruneCandidates(local)
+	if err != nil {
+		return err
+	}
+	if len(candidates) == 0 {
+		clilog.Info.Println("no connections to prune")
+		return nil
+	}
+
+	if dryRun {
+		for _, name := range candidates {
+			clilog.Info.Printf("dry run: connection %s would be pruned\n", name)
+		}
+		return nil
+	}
+
+	if !force {
+		ok, err := confirmPrune(candidates)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			clilog.Info.Println("prune cancelled")
+			return nil
+		}
+	}
+
+	errs := []string{}
+	for _, name := range candidates {
+		clilog.Info.Printf("pruning connection %s\n", name)
+		if _, err = Delete(name, wait); err != nil {
+			errs = append(errs, fmt.Sprintf("unable to prune connection %s: %v", name, err))
+		}
+	}
+	if len(errs) > 0 {
+		return errors.New(strings.Join(errs, "\n"))
+	}
+	return nil
+}
+
+// confirmPrune asks on the terminal whether to delete the connections
+func confirmPrune(names []string) (bool, error) {
+	if fi, err := os.Stdin.Stat(); err != nil || fi.Mode()&os.ModeCharDevice == 0 {
+		return false, fmt.Errorf("pruning %d connections must be confirmed on a terminal; "+
+			"use --force to prune without confirmation", len(names))
+	}
+	fmt.Fprintf(os.Stderr, "delete %d connections that are not in the folder: %s? [y/N] ",
+		len(names), strings.Join(names, ", "))
+	answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes", nil
+}
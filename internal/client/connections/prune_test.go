@@ -0,0 +1,67 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package connections
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestGetPruneCandidates(t *testing.T) {
+	newTestClient(t)
+
+	folder := t.TempDir()
+	for file, content := range map[string]string{
+		"c1.json":    `{"description":"from the file name"}`,
+		"other.json": `{"connectionName":"c3"}`,
+	} {
+		if err := os.WriteFile(filepath.Join(folder, file), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	files, err := getConnectionFiles(folder)
+	if err != nil {
+		t.Fatal(err)
+	}
+	local, err := getFolderConnectionNames(files, nil, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(local, map[string]bool{"c1": true, "c3": true}) {
+		t.Fatalf("unexpected connection names %v", local)
+	}
+
+	dir := newReplayDir(t)
+	writeListRecording(t, dir, "name", "", `{"connections":[`+
+		`{"name":"projects/my-project/locations/us-west1/connections/c1"},`+
+		`{"name":"projects/my-project/locations/us-west1/connections/c2"},`+
+		`{"name":"projects/my-project/locations/us-west1/connections/c3"},`+
+		`{"name":"projects/my-project/locations/us-west1/connections/c4"}]}`)
+
+	candidates, err := getPruneCandidates(local)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(candidates, []string{"c2", "c4"}) {
+		t.Errorf("expected c2 and c4 to be pruned, got %v", candidates)
+	}
+
+	// a dry run must not send the deletes, which have no recording
+	if err = pruneConnections(local, false, true, false); err != nil {
+		t.Errorf("dry run returned %v", err)
+	}
+}
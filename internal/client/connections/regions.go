@@ -0,0 +1,191 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package connections
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	"internal/apiclient"
+	"internal/clilog"
+)
+
+// allRegions is the region value that selects every region of the connectors API
+const allRegions = "all"
+
+type locations struct {
+	Locations     []location `json:"locations,omitempty"`
+	NextPageToken string     `json:"nextPageToken,omitempty"`
+}
+
+type location struct {
+	LocationId string `json:"locationId,omitempty"`
+}
+
+// regionConnection is a connection listed across regions
+type regionConnection struct {
+	Region string `json:"region,omitempty"`
+	connection
+}
+
+type listRegionConnections struct {
+	Connections []regionConnection `json:"connections,omitempty"`
+}
+
+// IsMultiRegion returns true if region is all or a comma separated list of regions
+func IsMultiRegion(region string) bool {
+	return region == allRegions || strings.Contains(region, ",")
+}
+
+// GetRegions returns the regions selected by region, which is either all, a comma
+// separated list or a single region
+func GetRegions(region string) (regions []string, err error) {
+	if region != allRegions {
+		for _, r := range strings.Split(region, ",") {
+			if r = strings.TrimSpace(r); r != "" {
+				regions = append(regions, r)
+			}
+		}
+		return regions, nil
+	}
+	return listRegions()
+}
+
+// listRegions returns the sorted regions supported by the connectors API
+func listRegions() (regions []string, err error) {
+	apiclient.ClientPrintHttpResponse.Set(false)
+	defer apiclient.ClientPrintHttpResponse.Set(apiclient.GetCmdPrintHttpResponseSetting())
+
+	pageToken := ""
+	for {
+		u, _ := url.Parse(apiclient.GetBaseConnectorLocationsURL())
+		q := u.Query()
+		q.Set("pageSize", strconv.Itoa(maxPageSize))
+		if pageToken != "" {
+			q.Set("pageToken", pageToken)
+		}
+		u.RawQuery = q.Encode()
+
+		respBody, err := apiclient.HttpClient(u.String())
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch regions: %w", err)
+		}
+		l := locations{}
+		if err = json.Unmarshal(respBody, &l); err != nil {
+			return nil, fmt.Errorf("failed to unmarshall: %w", err)
+		}
+		for _, loc := range l.Locations {
+			if loc.LocationId != "" && loc.LocationId != "global" {
+				regions = append(regions, loc.LocationId)
+			}
+		}
+		pageToken = l.NextPageToken
+		if l.NextPageToken == "" {
+			break
+		}
+	}
+	sort.Strings(regions)
+	return regions, nil
+}
+
+// ListRegions lists the connections in each of the regions and prints them as a
+// single list, with the region recorded on each connection. When state is set, only the
+// connections in that state are listed
+func ListRegions(regions []string, filter string, orderBy string, state string) (respBody []byte, err error) {
+	l, errs := listRegionsConnections(regions, filter, orderBy, state)
+
+	if respBody, err = json.Marshal(l); err != nil {
+		return nil, err
+	}
+	if err = apiclient.PrettyPrint(respBody); err != nil {
+		return nil, err
+	}
+	if len(errs) > 0 {
+		return respBody, errors.New(strings.Join(errs, "\n"))
+	}
+	return respBody, nil
+}
+
+// listRegionsConnections returns the connections of the regions in state, when set, and
+// an error for each region that could not be listed. The region of the client is not changed
+func listRegionsConnections(regions []string, filter string, orderBy string, state string,
+) (l listRegionConnections, errs []string) {
+	apiclient.ClientPrintHttpResponse.Set(false)
+	defer apiclient.ClientPrintHttpResponse.Set(apiclient.GetCmdPrintHttpResponseSetting())
+
+	for _, region := range regions {
+		lconnections, err := listAllConnectionsAt(apiclient.GetBaseConnectorURLWithRegion(region), filter, orderBy)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %v", region, err))
+			continue
+		}
+		if state != "" {
+			lconnections = filterConnectionsByState(lconnections, state)
+		}
+		for _, c := range lconnections {
+			l.Connections = append(l.Connections, regionConnection{Region: region, connection: c})
+		}
+	}
+	return l, errs
+}
+
+// ExportRegions exports the connections of each region that have all the labels to
+// folder/<region>. When exportSecrets is set, the secrets are exported to folder/<region>/secrets.
+// launchStage and secretReferences shape the connection files as in Export
+func ExportRegions(folder string, regions []string, exportSecrets bool, encryptionKey string,
+	labels map[string]string, bundleEndpoints bool, onlyChanged bool, launchStage bool, secretReferences bool,
+) (err error) {
+	errs := []string{}
+
+	currentRegion := apiclient.GetRegion()
+	defer apiclient.SetRegion(currentRegion)
+
+	for _, region := range regions {
+		if err = apiclient.SetRegion(region); err != nil {
+			return err
+		}
+		regionFolder := path.Join(folder, region)
+		if err = os.MkdirAll(regionFolder, 0o755); err != nil {
+			return err
+		}
+		clilog.Info.Printf("Exporting connections in %s\n", region)
+		if err = Export(regionFolder, labels, onlyChanged, launchStage, secretReferences); err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %v", region, err))
+			continue
+		}
+		if exportSecrets {
+			if err = ExportSecrets(path.Join(regionFolder, secretsFolderName), encryptionKey, labels); err != nil {
+				errs = append(errs, fmt.Sprintf("%s: %v", region, err))
+			}
+		}
+		if bundleEndpoints {
+			if err = ExportEndpoints(path.Join(regionFolder, endpointsFolderName), labels); err != nil {
+				errs = append(errs, fmt.Sprintf("%s: %v", region, err))
+			}
+		}
+	}
+
+	if len(errs) > 0 {
+		return errors.New(strings.Join(errs, "\n"))
+	}
+	return nil
+}
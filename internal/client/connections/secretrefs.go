@@ -0,0 +1,173 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package connections
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// secretsFolderName is the sub folder of an export the secret payloads are written to
+const secretsFolderName = "secrets"
+
+// getSecretFileName returns the name of the exported file for the secret version. The
+// version is part of the name, so connections using different versions of a secret are
+// restored with their own payload
+func getSecretFileName(secretVersion string) string {
+	parts := strings.Split(secretVersion, "/")
+	return parts[3] + "-" + parts[5] + ".txt"
+}
+
+// getSecretFileDetails returns secret details that recreate the secret of secretVersion
+// from its exported file
+func getSecretFileDetails(secretVersion string) *secretDetails {
+	return &secretDetails{
+		SecretName: strings.Split(secretVersion, "/")[3],
+		Reference:  path.Join(secretsFolderName, getSecretFileName(secretVersion)),
+	}
+}
+
+// setSecretReferences replaces the secret versions the connection create can recreate
+// secrets for with secret details referencing the exported secret files, so import
+// --create-secret restores the secrets along with the connection
+func setSecretReferences(c *connection) {
+	setUserPasswordReference(c.AuthConfig.UserPassword)
+	if j := c.AuthConfig.Oauth2JwtBearer; j != nil && j.ClientKey != nil && isSecretVersionPath(j.ClientKey.SecretVersion) {
+		j.ClientKeyDetails = getSecretFileDetails(j.ClientKey.SecretVersion)
+		j.ClientKey = nil
+	}
+
+	setConfigVarReferences(c.ConfigVariables)
+	if c.AuthConfig.AdditionalVariables != nil {
+		setConfigVarReferences(*c.AuthConfig.AdditionalVariables)
+	}
+
+	if ssl := c.SslConfig; ssl != nil {
+		if ssl.PrivateServerCertificate != nil {
+			setSslReference(&ssl.PrivateServerCertificate.SecretVersion, &ssl.PrivateServerCertificate.SecretDetails)
+		}
+		if ssl.ClientCertificate != nil {
+			setSslReference(&ssl.ClientCertificate.SecretVersion, &ssl.ClientCertificate.SecretDetails)
+		}
+		if ssl.ClientPrivateKey != nil {
+			setSslReference(&ssl.ClientPrivateKey.SecretVersion, &ssl.ClientPrivateKey.SecretDetails)
+		}
+		if ssl.ClientPrivateKeyPass != nil {
+			setSslReference(&ssl.ClientPrivateKeyPass.SecretVersion, &ssl.ClientPrivateKeyPass.SecretDetails)
+		}
+	}
+
+	if c.EventingConfig != nil {
+		for _, a := range []*authConfig{c.EventingConfig.AuthConfig, c.EventingConfig.ListenerAuthConfig} {
+			if a != nil {
+				setUserPasswordReference(a.UserPassword)
+			}
+		}
+	}
+}
+
+// checkSecretReferences returns an error listing the connections with secrets that
+// setSecretReferences can't replace, the OAUTH2_CLIENT_CREDENTIALS client secret and
+// the SSH_PUBLIC_KEY secrets, since the connection create doesn't recreate them and an
+// import would keep referencing the secret versions of the exported project
+func checkSecretReferences(lconnections []connection) error {
+	unsupported := []string{}
+	for _, c := range lconnections {
+		a := c.AuthConfig
+		clientSecret := a.Oauth2ClientCredentials != nil && a.Oauth2ClientCredentials.ClientSecret != nil &&
+			isSecretVersionPath(a.Oauth2ClientCredentials.ClientSecret.SecretVersion)
+		sshSecret := a.SshPublicKey != nil && ((a.SshPublicKey.Password != nil &&
+			isSecretVersionPath(a.SshPublicKey.Password.SecretVersion)) ||
+			(a.SshPublicKey.SshClientCert != nil && isSecretVersionPath(a.SshPublicKey.SshClientCert.SecretVersion)) ||
+			(a.SshPublicKey.SslClientCertPass != nil && isSecretVersionPath(a.SshPublicKey.SslClientCertPass.SecretVersion)))
+		if clientSecret || sshSecret {
+			unsupported = append(unsupported, fmt.Sprintf("%s (%s)", getConnectionName(*c.Name), a.AuthType))
+		}
+	}
+	if len(unsupported) == 0 {
+		return nil
+	}
+	return fmt.Errorf("secret values can't be included for the secrets of the auth types "+
+		"OAUTH2_CLIENT_CREDENTIALS and SSH_PUBLIC_KEY, used by the connections:\n%s",
+		strings.Join(unsupported, "\n"))
+}
+
+func setUserPasswordReference(up *userPassword) {
+	if up == nil || up.Password == nil || !isSecretVersionPath(up.Password.SecretVersion) {
+		return
+	}
+	up.PasswordDetails = getSecretFileDetails(up.Password.SecretVersion)
+	up.Password = nil
+}
+
+func setSslReference(secretVersion **string, details **secretDetails) {
+	if *secretVersion == nil || !isSecretVersionPath(**secretVersion) {
+		return
+	}
+	*details = getSecretFileDetails(**secretVersion)
+	*secretVersion = nil
+}
+
+func setConfigVarReferences(configVars []configVar) {
+	for index := range configVars {
+		if sv := configVars[index].SecretValue; sv != nil && isSecretVersionPath(sv.SecretVersion) {
+			configVars[index].SecretDetails = getSecretFileDetails(sv.SecretVersion)
+			configVars[index].SecretValue = nil
+		}
+	}
+}
+
+// resolveSecretReferences makes the relative secret references in the connection that
+// are not found in the working directory relative to the import folder, where an
+// export with secret values writes them
+func resolveSecretReferences(content []byte, folder string) ([]byte, error) {
+	var c interface{}
+	if err := json.Unmarshal(content, &c); err != nil {
+		return nil, err
+	}
+	if !resolveReferences(c, folder) {
+		return content, nil
+	}
+	return json.Marshal(c)
+}
+
+// resolveReferences rewrites the reference of every secret details object in v and
+// reports if any was changed
+func resolveReferences(v interface{}, folder string) (changed bool) {
+	switch value := v.(type) {
+	case map[string]interface{}:
+		if reference, ok := value["reference"].(string); ok && value["secretName"] != nil &&
+			reference != "" && !filepath.IsAbs(reference) {
+			if _, err := os.Stat(reference); os.IsNotExist(err) {
+				if _, err := os.Stat(filepath.Join(folder, reference)); err == nil {
+					value["reference"] = filepath.Join(folder, reference)
+					changed = true
+				}
+			}
+		}
+		for _, item := range value {
+			changed = resolveReferences(item, folder) || changed
+		}
+	case []interface{}:
+		for _, item := range value {
+			changed = resolveReferences(item, folder) || changed
+		}
+	}
+	return changed
+}
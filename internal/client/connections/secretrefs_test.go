@@ -0,0 +1,192 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package connections
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSetSecretReferences(t *testing.T) {
+	const secretVersion = "projects/p1/secrets/db-password/versions/2"
+	c := connection{
+		AuthConfig: authConfig{
+			AuthType:     "USER_PASSWORD",
+			UserPassword: &userPassword{Username: "u", Password: &secret{SecretVersion: secretVersion}},
+		},
+		ConfigVariables: []configVar{{Key: "api_key", SecretValue: &secret{SecretVersion: secretVersion}}},
+		SslConfig:       &sslConfig{ClientCertificate: &clientCertificate{SecretVersion: new(string)}},
+	}
+	*c.SslConfig.ClientCertificate.SecretVersion = secretVersion
+
+	setSecretReferences(&c)
+
+	want := secretDetails{SecretName: "db-password", Reference: "secrets/db-password-2.txt"}
+	for field, got := range map[string]*secretDetails{
+		"passwordDetails":   c.AuthConfig.UserPassword.PasswordDetails,
+		"configVariables":   c.ConfigVariables[0].SecretDetails,
+		"clientCertificate": c.SslConfig.ClientCertificate.SecretDetails,
+	} {
+		if got == nil || *got != want {
+			t.Errorf("%s: expected %v, got %v", field, want, got)
+		}
+	}
+	if c.AuthConfig.UserPassword.Password != nil || c.ConfigVariables[0].SecretValue != nil ||
+		c.SslConfig.ClientCertificate.SecretVersion != nil {
+		t.Errorf("expected the secret versions to be replaced")
+	}
+}
+
+func TestGetSecretFileNameVersions(t *testing.T) {
+	v2 := getSecretFileName("projects/p1/secrets/db-password/versions/2")
+	v3 := getSecretFileName("projects/p1/secrets/db-password/versions/3")
+	if v2 == v3 {
+		t.Errorf("expected different files for different versions of a secret, got %s", v2)
+	}
+	if d := getSecretFileDetails("projects/p1/secrets/db-password/versions/3"); d.SecretName != "db-password" ||
+		d.Reference != "secrets/"+v3 {
+		t.Errorf("unexpected secret details %v", d)
+	}
+}
+
+func TestExportImportRoundTrip(t *testing.T) {
+	newTestClient(t)
+
+	const (
+		connectorVersion = "projects/my-project/locations/global/providers/gcp/connectors/cloudsql-mysql/versions/1"
+		secretVersion    = "projects/my-project/secrets/db-password/versions/2"
+		eventingVersion  = "projects/my-project/secrets/listener-password/versions/1"
+	)
+
+	for name, body := range map[string]string{
+		"auth": `"authConfig":{"authType":"USER_PASSWORD","userPassword":{"username":"u",` +
+			`"password":{"secretVersion":"` + secretVersion + `"}}}`,
+		"eventing": `"authConfig":{"authType":"USER_PASSWORD","userPassword":{"username":"u",` +
+			`"password":{"secretVersion":"` + secretVersion + `"}}},"eventingEnablementType":"EVENTING_AND_CONNECTION",` +
+			`"eventingConfig":{"listenerAuthConfig":{"authType":"USER_PASSWORD","userPassword":{"username":"l",` +
+			`"password":{"secretVersion":"` + eventingVersion + `"}}}}`,
+	} {
+		live := connection{}
+		if err := json.Unmarshal([]byte(`{"name":"projects/my-project/locations/us-west1/connections/c1",`+
+			`"connectorVersion":"`+connectorVersion+`","status":{"state":"ACTIVE"},`+body+`}`), &live); err != nil {
+			t.Fatal(err)
+		}
+
+		// export writes the connection and, with secret values, the secret payloads
+		secretVersions := getSecretVersions(live)
+		fileName, content, err := getExportPayload(live, false, true)
+		if err != nil {
+			t.Fatal(err)
+		}
+		folder := t.TempDir()
+		if err = os.WriteFile(filepath.Join(folder, fileName), content, 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if err = os.MkdirAll(filepath.Join(folder, secretsFolderName), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		for _, v := range secretVersions {
+			if err = os.WriteFile(filepath.Join(folder, secretsFolderName, getSecretFileName(v)),
+				[]byte("s3cret"), 0o644); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		// import --create-secret, with the secret creates recorded by a plan
+		plan := &createPlan{}
+
+		if content, err = resolveSecretReferences(content, folder); err != nil {
+			t.Fatal(err)
+		}
+		payload, err := prepareConnection(plan, content, "", "", "", false, true, false, false,
+			CreateOptions{AllowPreview: true})
+		if err != nil {
+			t.Fatalf("%s: expected the exported file to be imported, got %v", name, err)
+		}
+
+		c := connectionRequest{}
+		if err = json.Unmarshal(payload, &c); err != nil {
+			t.Fatal(err)
+		}
+		if c.ConnectorVersion == nil || *c.ConnectorVersion != connectorVersion {
+			t.Errorf("%s: expected connector version %s, got %v", name, connectorVersion, c.ConnectorVersion)
+		}
+		if c.AuthConfig == nil || c.AuthConfig.UserPassword == nil || c.AuthConfig.UserPassword.Password == nil ||
+			c.AuthConfig.UserPassword.Password.SecretVersion != "projects/my-project/secrets/db-password/versions/latest" {
+			t.Errorf("%s: expected the password secret to be restored, got %s", name, payload)
+		}
+		if len(plan.rows) != len(secretVersions) {
+			t.Errorf("%s: expected %d secrets to be created, got %v", name, len(secretVersions), plan.rows)
+		}
+	}
+}
+
+func TestResolveSecretReferences(t *testing.T) {
+	folder := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(folder, secretsFolderName), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(folder, secretsFolderName, "s1.txt"), []byte("x"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	content := []byte(`{"configVariables":[` +
+		`{"key":"a","secretDetails":{"secretName":"s1","reference":"secrets/s1.txt"}},` +
+		`{"key":"b","secretDetails":{"secretName":"s2","reference":"secrets/s2.txt"}}]}`)
+	resolved, err := resolveSecretReferences(content, folder)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c := connectionRequest{}
+	if err = json.Unmarshal(resolved, &c); err != nil {
+		t.Fatal(err)
+	}
+	if got := (*c.ConfigVariables)[0].SecretDetails.Reference; got != filepath.Join(folder, "secrets/s1.txt") {
+		t.Errorf("expected the reference to be resolved in the folder, got %s", got)
+	}
+	if got := (*c.ConfigVariables)[1].SecretDetails.Reference; !strings.HasPrefix(got, "secrets/") {
+		t.Errorf("expected a missing file to be left as is, got %s", got)
+	}
+}
+
+func TestCheckSecretReferences(t *testing.T) {
+	const secretVersion = "projects/my-project/secrets/s1/versions/1"
+	tests := []struct {
+		name        string
+		auth        string
+		unsupported bool
+	}{
+		{"user password", `{"authType":"USER_PASSWORD","userPassword":{"password":{"secretVersion":"` + secretVersion + `"}}}`, false},
+		{"client credentials", `{"authType":"OAUTH2_CLIENT_CREDENTIALS","oauth2ClientCredentials":` +
+			`{"clientId":"id","clientSecret":{"secretVersion":"` + secretVersion + `"}}}`, true},
+		{"ssh public key", `{"authType":"SSH_PUBLIC_KEY","sshPublicKey":{"username":"u",` +
+			`"sshClientCert":{"secretVersion":"` + secretVersion + `"}}}`, true},
+		{"none", `{}`, false},
+	}
+	for _, test := range tests {
+		c := connection{}
+		if err := json.Unmarshal([]byte(`{"name":"projects/my-project/locations/us-west1/connections/c1",`+
+			`"authConfig":`+test.auth+`}`), &c); err != nil {
+			t.Fatal(err)
+		}
+		if err := checkSecretReferences([]connection{c}); (err != nil) != test.unsupported {
+			t.Errorf("%s: expected unsupported %t, got %v", test.name, test.unsupported, err)
+		}
+	}
+}
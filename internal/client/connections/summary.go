@@ -0,0 +1,65 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package connections
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"internal/clilog"
+)
+
+// runSummary tracks the outcome of a bulk import or export
+type runSummary struct {
+	operation   string
+	total       int
+	done        int
+	created     int
+	skipped     int
+	patched     int
+	failed      int
+	start       time.Time
+	interactive bool
+}
+
+// newRunSummary returns a summary that shows its progress when the warnings are
+// written as text to a terminal, so --no-output and --suppress-warnings hide it
+func newRunSummary(operation string, total int) *runSummary {
+	s := &runSummary{operation: operation, total: total, start: time.Now()}
+	if clilog.Warning.Writer() == os.Stderr {
+		if fi, err := os.Stderr.Stat(); err == nil {
+			s.interactive = fi.Mode()&os.ModeCharDevice != 0
+		}
+	}
+	return s
+}
+
+// progress marks one more item as processed and, on a terminal, shows N/M
+func (s *runSummary) progress() {
+	s.done++
+	if s.interactive {
+		fmt.Fprintf(clilog.Warning.Writer(), "\r%s %d/%d", s.operation, s.done, s.total)
+		if s.done == s.total {
+			fmt.Fprintln(clilog.Warning.Writer())
+		}
+	}
+}
+
+func (s *runSummary) print() {
+	clilog.Info.Printf("%s summary: total %d, created %d, skipped %d, patched %d, failed %d, elapsed %s\n",
+		s.operation, s.total, s.created, s.skipped, s.patched, s.failed,
+		time.Since(s.start).Round(time.Millisecond))
+}
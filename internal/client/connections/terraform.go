@@ -0,0 +1,114 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package connections
+
+import (
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+
+	"internal/apiclient"
+	"internal/clilog"
+)
+
+const terraformResourceType = "google_integration_connectors_connection"
+
+// ExportTerraform writes a minimal Terraform resource and import block for every
+// connection in the region to folder. With importScript, the import blocks are
+// replaced by an import.sh listing the terraform import commands, for Terraform
+// versions without import blocks
+func ExportTerraform(folder string, labels map[string]string, importScript bool) (err error) {
+	apiclient.ClientPrintHttpResponse.Set(false)
+	defer apiclient.ClientPrintHttpResponse.Set(apiclient.GetCmdPrintHttpResponseSetting())
+
+	lconnections, err := listAllConnections("", "")
+	if err != nil {
+		return err
+	}
+	lconnections = filterConnectionsByLabels(lconnections, labels)
+
+	if len(lconnections) == 0 {
+		return nil
+	}
+
+	importCommands := []string{"#!/bin/sh"}
+
+	for _, lconnection := range lconnections {
+		name := getConnectionName(*lconnection.Name)
+		resourceName := strings.ReplaceAll(name, "-", "_")
+
+		importCommands = append(importCommands, fmt.Sprintf("terraform import %s.%s %s",
+			terraformResourceType, resourceName, *lconnection.Name))
+
+		fileName := name + ".tf"
+		if err = apiclient.WriteByteArrayToFile(
+			path.Join(folder, fileName),
+			false,
+			[]byte(getTerraformResource(resourceName, lconnection, !importScript))); err != nil {
+			clilog.Error.Println(err)
+			return err
+		}
+		clilog.Info.Printf("Downloaded %s\n", fileName)
+	}
+
+	if !importScript {
+		return nil
+	}
+	return apiclient.WriteByteArrayToFile(path.Join(folder, "import.sh"), false,
+		[]byte(strings.Join(importCommands, "\n")+"\n"))
+}
+
+// getTerraformResource returns the resource block of a connection, preceded by its
+// import block when importBlock is set
+func getTerraformResource(resourceName string, c connection, importBlock bool) string {
+	b := &strings.Builder{}
+
+	if importBlock {
+		fmt.Fprintf(b, "import {\n  to = %s.%s\n  id = %s\n}\n\n", terraformResourceType, resourceName, strconv.Quote(*c.Name))
+	}
+
+	fmt.Fprintf(b, "resource %q %q {\n", terraformResourceType, resourceName)
+	fmt.Fprintf(b, "  name              = %q\n", getConnectionName(*c.Name))
+	fmt.Fprintf(b, "  location          = %q\n", apiclient.GetRegion())
+	if c.ConnectorVersion != nil {
+		fmt.Fprintf(b, "  connector_version = %q\n", *c.ConnectorVersion)
+	}
+	if c.Description != "" {
+		fmt.Fprintf(b, "  description       = %q\n", c.Description)
+	}
+
+	for _, cv := range c.ConfigVariables {
+		fmt.Fprintf(b, "\n  config_variable {\n    key = %q\n", cv.Key)
+		switch {
+		case cv.StringValue != nil:
+			fmt.Fprintf(b, "    string_value = %q\n", *cv.StringValue)
+		case cv.IntValue != nil:
+			fmt.Fprintf(b, "    integer_value = %s\n", *cv.IntValue)
+		case cv.BoolValue != nil:
+			fmt.Fprintf(b, "    boolean_value = %t\n", *cv.BoolValue)
+		case cv.SecretValue != nil:
+			fmt.Fprintf(b, "    secret_value {\n      secret_version = %q\n    }\n", cv.SecretValue.SecretVersion)
+		}
+		b.WriteString("  }\n")
+	}
+
+	if c.AuthConfig.AuthType != "" {
+		fmt.Fprintf(b, "\n  auth_config {\n    auth_type = %q\n  }\n", c.AuthConfig.AuthType)
+	}
+
+	b.WriteString("}\n")
+	return b.String()
+}
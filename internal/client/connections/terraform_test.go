@@ -0,0 +1,34 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package connections
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGetTerraformResourceImportBlock(t *testing.T) {
+	newTestClient(t)
+
+	name := "projects/my-project/locations/us-west1/connections/c-1"
+	c := connection{Name: &name}
+	if resource := getTerraformResource("c_1", c, true); !strings.HasPrefix(resource,
+		"import {\n  to = google_integration_connectors_connection.c_1\n  id = \""+name+"\"\n}") {
+		t.Errorf("expected an import block, got %s", resource)
+	}
+	if resource := getTerraformResource("c_1", c, false); strings.Contains(resource, "import {") {
+		t.Errorf("expected no import block with an import script, got %s", resource)
+	}
+}
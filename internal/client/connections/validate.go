@@ -0,0 +1,117 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package connections
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"internal/apiclient"
+	"internal/clilog"
+)
+
+// Validate runs the offline checks on a connection file and returns every problem
+// found. It needs no project access, so checks that depend on the connector version,
+// like required config variables, are left to create
+func Validate(file string, content []byte) (problems []string) {
+	c := connectionRequest{}
+	if err := json.Unmarshal(content, &c); err != nil {
+		return []string{fmt.Sprintf("unable to parse: %v", err)}
+	}
+
+	if _, err := getImportConnectionName(file, content, false); err != nil {
+		problems = append(problems, err.Error())
+	}
+
+	if c.ConnectorVersion != nil {
+		if c.ConnectorDetails != nil {
+			problems = append(problems, "connectorVersion and connectorDetails cannot both be set")
+		}
+		if err := validateConnectorVersion(*c.ConnectorVersion); err != nil {
+			problems = append(problems, err.Error())
+		}
+	} else if c.ConnectorDetails == nil {
+		problems = append(problems, "connectorDetails or connectorVersion must be set")
+	} else {
+		if c.ConnectorDetails.Name == "" || c.ConnectorDetails.Provider == "" {
+			problems = append(problems, "connectorDetails name and provider must be set")
+		}
+		if c.ConnectorDetails.Version != nil && c.ConnectorDetails.VersionId != nil {
+			problems = append(problems, "connectorDetails version and versionId cannot both be set")
+		}
+		if c.ConnectorDetails.Provider == "customconnector" && c.ConnectorDetails.VersionId == nil {
+			problems = append(problems, "connectorDetails versionId must be set for customconnectors")
+		}
+	}
+
+	if err := validateDestinationConfigs(c); err != nil {
+		problems = append(problems, err.Error())
+	}
+
+	if c.NodeConfig != nil && c.NodeConfig.MaxNodeCount != 0 &&
+		c.NodeConfig.MinNodeCount > c.NodeConfig.MaxNodeCount {
+		problems = append(problems, fmt.Sprintf("nodeConfig minNodeCount %d cannot be greater than maxNodeCount %d",
+			c.NodeConfig.MinNodeCount, c.NodeConfig.MaxNodeCount))
+	}
+
+	if c.AuthConfig != nil && c.AuthConfig.AuthType != "" {
+		if _, ok := authTypeFields[c.AuthConfig.AuthType]; !ok {
+			problems = append(problems, fmt.Sprintf("auth type %s is not supported", c.AuthConfig.AuthType))
+		}
+	}
+	return problems
+}
+
+// ValidateFolder validates every connection file in folder, with the folder defaults
+// applied as on import, and prints the problems grouped by file. It returns an error
+// if any file is invalid
+func ValidateFolder(folder string) (err error) {
+	files, err := getConnectionFiles(folder)
+	if err != nil {
+		return err
+	}
+	defaults, err := readDefaultsFile(folder)
+	if err != nil {
+		return err
+	}
+
+	rows := [][]string{}
+	invalid := 0
+	for _, file := range files {
+		content, err := readConnectionFile(file, defaults)
+		problems := []string{}
+		if err != nil {
+			problems = append(problems, err.Error())
+		} else {
+			problems = Validate(file, content)
+		}
+		if len(problems) > 0 {
+			invalid++
+		}
+		for _, problem := range problems {
+			rows = append(rows, []string{file, problem})
+		}
+	}
+
+	duplicates := checkDuplicateConnectionNames(files, defaults, false)
+	if invalid == 0 {
+		return duplicates
+	}
+	apiclient.PrintTable([]string{"FILE", "PROBLEM"}, rows)
+	if duplicates != nil {
+		clilog.Error.Println(duplicates)
+	}
+	return fmt.Errorf("%d of %d connection files in %s are invalid", invalid, len(files), folder)
+}
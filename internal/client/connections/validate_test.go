@@ -0,0 +1,74 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package connections
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	valid := []byte(`{"connectorDetails":{"name":"pubsub","provider":"gcp","version":1}}`)
+	if problems := Validate("c1.json", valid); len(problems) != 0 {
+		t.Errorf("expected no problems, got %v", problems)
+	}
+
+	invalid := []byte(`{"connectorDetails":{"name":"pubsub","version":1,"versionId":"1"},` +
+		`"destinationConfigs":[{"key":"url","destinations":[{"host":"h","port":70000}]}],` +
+		`"nodeConfig":{"minNodeCount":3,"maxNodeCount":2}}`)
+	if problems := Validate("Bad_Name.json", invalid); len(problems) != 5 {
+		t.Errorf("expected 5 problems, got %d: %v", len(problems), problems)
+	}
+
+	host := []byte(`{"connectorDetails":{"name":"http","provider":"gcp","version":1},` +
+		`"destinationConfigs":[{"key":"url","destinations":[{"host":"https://example.com"}]}]}`)
+	if problems := Validate("c1.json", host); len(problems) != 0 {
+		t.Errorf("expected a host without a port to be valid, got %v", problems)
+	}
+
+	version := []byte(`{"connectorVersion":"projects/p/locations/global/providers/gcp/connectors/pubsub/versions/1"}`)
+	if problems := Validate("c1.json", version); len(problems) != 0 {
+		t.Errorf("expected a connectorVersion to replace connectorDetails, got %v", problems)
+	}
+
+	if problems := Validate("c1.json", []byte(`{`)); len(problems) != 1 ||
+		!strings.HasPrefix(problems[0], "unable to parse") {
+		t.Errorf("expected a parse problem, got %v", problems)
+	}
+}
+
+func TestValidateFolder(t *testing.T) {
+	newTestClient(t)
+
+	folder := t.TempDir()
+	write := func(name string, content string) {
+		if err := os.WriteFile(filepath.Join(folder, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write(defaultsFileName, `{"connectorDetails":{"provider":"gcp"}}`)
+	write("c1.json", `{"connectorDetails":{"name":"pubsub","version":1}}`)
+
+	if err := ValidateFolder(folder); err != nil {
+		t.Errorf("expected the defaults to complete the connection, got %v", err)
+	}
+
+	write("c2.json", `{"connectorDetails":{"provider":""}}`)
+	if err := ValidateFolder(folder); err == nil || !strings.HasPrefix(err.Error(), "1 of 2") {
+		t.Errorf("expected 1 of 2 files to be invalid, got %v", err)
+	}
+}
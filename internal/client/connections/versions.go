@@ -0,0 +1,523 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package connections
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"path"
+	"reflect"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"internal/apiclient"
+	"internal/clilog"
+)
+
+type connectorVersions struct {
+	ConnectorVersions []connectorVersion `json:"connectorVersions,omitempty"`
+	NextPageToken     string             `json:"nextPageToken,omitempty"`
+}
+
+type connectorVersion struct {
+	Name                    string                   `json:"name,omitempty"`
+	LaunchStage             string                   `json:"launchStage,omitempty"`
+	ReleaseVersion          string                   `json:"releaseVersion,omitempty"`
+	ConfigVariableTemplates []configVariableTemplate `json:"configVariableTemplates,omitempty"`
+	AuthConfigTemplates     []authConfigTemplate     `json:"authConfigTemplates,omitempty"`
+}
+
+type authConfigTemplate struct {
+	AuthType                string                   `json:"authType,omitempty"`
+	DisplayName             string                   `json:"displayName,omitempty"`
+	Description             string                   `json:"description,omitempty"`
+	ConfigVariableTemplates []configVariableTemplate `json:"configVariableTemplates,omitempty"`
+}
+
+const (
+	deprecatedLaunchStage = "DEPRECATED"
+	gaLaunchStage         = "GA"
+)
+
+// launchStages caches the launch stage of each connector version path, so exports
+// of many connections on the same version look it up once
+var launchStages = map[string]string{}
+
+// GetConnectorVersion
+func GetConnectorVersion(provider string, connector string, version string, full bool) (respBody []byte, err error) {
+	u, _ := url.Parse(apiclient.GetBaseConnectorProvidersURL())
+	u.Path = path.Join(u.Path, provider, "connectors", connector, "versions", version)
+	if full {
+		q := u.Query()
+		q.Set("view", "CONNECTOR_VERSION_VIEW_FULL")
+		u.RawQuery = q.Encode()
+	}
+	respBody, err = apiclient.HttpClient(u.String())
+	return respBody, err
+}
+
+// ListConnectorVersions
+func ListConnectorVersions(provider string, connector string, pageSize int, pageToken string) (respBody []byte, err error) {
+	u, _ := url.Parse(apiclient.GetBaseConnectorProvidersURL())
+	u.Path = path.Join(u.Path, provider, "connectors", connector, "versions")
+	q := u.Query()
+	if pageSize != -1 {
+		q.Set("pageSize", strconv.Itoa(pageSize))
+	}
+	if pageToken != "" {
+		q.Set("pageToken", pageToken)
+	}
+	u.RawQuery = q.Encode()
+	respBody, err = apiclient.HttpClient(u.String())
+	return respBody, err
+}
+
+// LintVersions reports connections using deprecated connector versions
+func LintVersions() (err error) {
+	apiclient.ClientPrintHttpResponse.Set(false)
+	defer apiclient.ClientPrintHttpResponse.Set(apiclient.GetCmdPrintHttpResponseSetting())
+
+	lconnections, err := listAllConnections("", "")
+	if err != nil {
+		return err
+	}
+
+	versionsCache := make(map[string][]connectorVersion)
+	rows := [][]string{}
+
+	for _, lconnection := range lconnections {
+		if lconnection.ConnectorVersion == nil {
+			continue
+		}
+		provider := getConnectorProvider(*lconnection.ConnectorVersion)
+		if provider == "customconnector" {
+			continue
+		}
+		connector := getConnectorName(*lconnection.ConnectorVersion)
+
+		key := path.Join(provider, connector)
+		versions, ok := versionsCache[key]
+		if !ok {
+			if versions, err = listAllConnectorVersions(provider, connector); err != nil {
+				return err
+			}
+			versionsCache[key] = versions
+		}
+
+		stage := ""
+		for _, v := range versions {
+			if v.Name == *lconnection.ConnectorVersion {
+				stage = v.LaunchStage
+			}
+		}
+		if stage != deprecatedLaunchStage {
+			continue
+		}
+
+		latest := ""
+		if l, found := getLatestConnectorVersion(versions, ""); found {
+			latest = getConnectorVersionId(l.Name)
+		}
+		rows = append(rows, []string{
+			getConnectionName(*lconnection.Name),
+			getConnectorVersionId(*lconnection.ConnectorVersion),
+			latest,
+			stage,
+		})
+	}
+
+	if len(rows) == 0 {
+		clilog.Info.Println("No connections using deprecated connector versions were found")
+		return nil
+	}
+
+	apiclient.ClientPrintHttpResponse.Set(apiclient.GetCmdPrintHttpResponseSetting())
+	apiclient.PrintTable([]string{"CONNECTION", "CURRENT VERSION", "LATEST VERSION", "STAGE"}, rows)
+	return nil
+}
+
+// listAllConnectorVersions
+func listAllConnectorVersions(provider string, connector string) (versions []connectorVersion, err error) {
+	pageToken := ""
+
+	for {
+		l := connectorVersions{}
+		respBody, err := ListConnectorVersions(provider, connector, maxPageSize, pageToken)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch connector versions: %w", err)
+		}
+		if err = json.Unmarshal(respBody, &l); err != nil {
+			return nil, fmt.Errorf("failed to unmarshall: %w", err)
+		}
+		versions = append(versions, l.ConnectorVersions...)
+		pageToken = l.NextPageToken
+		if l.NextPageToken == "" {
+			break
+		}
+	}
+	return versions, nil
+}
+
+// getLatestConnectorVersion returns the highest numbered version that is not deprecated.
+// When launchStage is set, only versions in that launch stage are considered
+func getLatestConnectorVersion(versions []connectorVersion, launchStage string) (latest connectorVersion, found bool) {
+	candidates := []connectorVersion{}
+	for _, v := range versions {
+		if v.LaunchStage != deprecatedLaunchStage && (launchStage == "" || v.LaunchStage == launchStage) {
+			candidates = append(candidates, v)
+		}
+	}
+	if len(candidates) == 0 {
+		return latest, false
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		return getConnectorVersion(candidates[i].Name) < getConnectorVersion(candidates[j].Name)
+	})
+	return candidates[len(candidates)-1], true
+}
+
+// getLatestGAVersion returns the highest GA version of the connector
+func getLatestGAVersion(provider string, connector string) (version *int, err error) {
+	apiclient.ClientPrintHttpResponse.Set(false)
+	versions, err := listAllConnectorVersions(provider, connector)
+	apiclient.ClientPrintHttpResponse.Set(apiclient.GetCmdPrintHttpResponseSetting())
+	if err != nil {
+		return nil, err
+	}
+
+	latest, found := getLatestConnectorVersion(versions, gaLaunchStage)
+	if !found {
+		return nil, fmt.Errorf("no GA version found for connector %s/%s", provider, connector)
+	}
+	version = new(int)
+	*version = getConnectorVersion(latest.Name)
+	clilog.Info.Printf("Using version %d of connector %s\n", *version, connector)
+	return version, nil
+}
+
+// GenerateTemplate prints a skeleton connection for the connector version with the
+// required config variables and the auth block for authType filled with placeholders
+func GenerateTemplate(provider string, connector string, version int, authType string) (respBody []byte, err error) {
+	apiclient.ClientPrintHttpResponse.Set(false)
+	respBody, err = GetConnectorVersion(provider, connector, strconv.Itoa(version), true)
+	apiclient.ClientPrintHttpResponse.Set(apiclient.GetCmdPrintHttpResponseSetting())
+	if err != nil {
+		return nil, err
+	}
+
+	v := connectorVersion{}
+	if err = json.Unmarshal(respBody, &v); err != nil {
+		return nil, fmt.Errorf("failed to unmarshall: %w", err)
+	}
+
+	c := connectionRequest{}
+	c.ConnectorDetails = &connectorDetails{Name: connector, Provider: provider, Version: &version}
+
+	configVars := getTemplateConfigVars(v.ConfigVariableTemplates)
+	if len(configVars) > 0 {
+		c.ConfigVariables = &configVars
+	}
+
+	if authType != "" {
+		if c.AuthConfig, err = getTemplateAuthConfig(v.AuthConfigTemplates, authType); err != nil {
+			return nil, err
+		}
+	}
+
+	if respBody, err = json.Marshal(c); err != nil {
+		return nil, err
+	}
+	return respBody, apiclient.PrettyPrint(respBody)
+}
+
+// getTemplateConfigVars returns placeholder config variables for the required templates
+func getTemplateConfigVars(templates []configVariableTemplate) (configVars []configVar) {
+	for _, t := range templates {
+		if !t.Required {
+			continue
+		}
+		cv := configVar{Key: t.Key}
+		switch t.ValueType {
+		case "BOOL":
+			cv.BoolValue = new(bool)
+		case "INT":
+			cv.IntValue = new(string)
+			*cv.IntValue = "0"
+		case "SECRET":
+			cv.SecretDetails = &secretDetails{SecretName: "<secret name>", Reference: "<path to secret file>"}
+		default:
+			cv.StringValue = new(string)
+			*cv.StringValue = "<" + t.DisplayName + ">"
+		}
+		configVars = append(configVars, cv)
+	}
+	return configVars
+}
+
+// getTemplateAuthConfig returns a placeholder auth config for authType
+func getTemplateAuthConfig(templates []authConfigTemplate, authType string) (a *authConfig, err error) {
+	supported := []string{}
+	for _, t := range templates {
+		supported = append(supported, t.AuthType)
+		if t.AuthType != authType {
+			continue
+		}
+
+		a = &authConfig{AuthType: authType}
+		switch authType {
+		case "USER_PASSWORD":
+			a.UserPassword = &userPassword{
+				Username:        "<username>",
+				PasswordDetails: &secretDetails{SecretName: "<secret name>", Reference: "<path to password file>"},
+			}
+		case "OAUTH2_JWT_BEARER":
+			a.Oauth2JwtBearer = &oauth2JwtBearer{
+				ClientKeyDetails: &secretDetails{SecretName: "<secret name>", Reference: "<path to client key file>"},
+				JwtClaims:        jwtClaims{Issuer: "<issuer>", Subject: "<subject>", Audience: "<audience>"},
+			}
+		case "OAUTH2_CLIENT_CREDENTIALS":
+			a.Oauth2ClientCredentials = &oauth2ClientCredentials{
+				ClientId:            "<client id>",
+				ClientSecretDetails: &secretDetails{SecretName: "<secret name>", Reference: "<path to client secret file>"},
+			}
+		case "SSH_PUBLIC_KEY":
+			a.SshPublicKey = &sshPublicKey{
+				Username:             "<username>",
+				SshClientCertDetails: &secretDetails{SecretName: "<secret name>", Reference: "<path to client cert file>"},
+			}
+		}
+
+		additionalVariables := getTemplateConfigVars(t.ConfigVariableTemplates)
+		if len(additionalVariables) > 0 {
+			a.AdditionalVariables = &additionalVariables
+		}
+		return a, nil
+	}
+	return nil, fmt.Errorf("auth type %s is not supported by the connector, supported types are: %s",
+		authType, strings.Join(supported, ", "))
+}
+
+// CheckImmutableConfigVars compares the config variables in the patch content with the
+// existing connection and reports the ones the connector version marks as immutable.
+// The changes are logged as warnings, or returned as an error when strict is set
+func CheckImmutableConfigVars(name string, content []byte, updateMask []string, strict bool) (err error) {
+	if len(updateMask) != 0 && !slices.Contains(updateMask, "configVariables") {
+		return nil
+	}
+
+	desired := connectionRequest{}
+	if err = json.Unmarshal(content, &desired); err != nil {
+		return err
+	}
+	if desired.ConfigVariables == nil {
+		return nil
+	}
+
+	existing, err := GetConnection(name)
+	if err != nil {
+		return err
+	}
+
+	apiclient.ClientPrintHttpResponse.Set(false)
+	defer apiclient.ClientPrintHttpResponse.Set(apiclient.GetCmdPrintHttpResponseSetting())
+	if existing.ConnectorVersion == nil {
+		return nil
+	}
+
+	respBody, err := GetConnectorVersion(getConnectorProvider(*existing.ConnectorVersion),
+		getConnectorName(*existing.ConnectorVersion), getConnectorVersionId(*existing.ConnectorVersion), true)
+	if err != nil {
+		// custom connectors and unknown versions have no schema to check against
+		clilog.Warning.Printf("unable to check for immutable config variables: %v\n", err)
+		return nil
+	}
+	v := connectorVersion{}
+	if err = json.Unmarshal(respBody, &v); err != nil {
+		return fmt.Errorf("failed to unmarshall: %w", err)
+	}
+
+	changed := getChangedImmutableConfigVars(v.ConfigVariableTemplates, existing.ConfigVariables, *desired.ConfigVariables)
+	if len(changed) == 0 {
+		return nil
+	}
+
+	msg := fmt.Sprintf("connection %s: config variables %s cannot be changed after creation",
+		name, strings.Join(changed, ", "))
+	if strict {
+		return errors.New(msg)
+	}
+	clilog.Warning.Println(msg)
+	return nil
+}
+
+// getChangedImmutableConfigVars returns the sorted keys of the immutable config
+// variables whose desired value differs from the existing one
+func getChangedImmutableConfigVars(templates []configVariableTemplate,
+	existing []configVar, desired []configVar,
+) (changed []string) {
+	immutable := make(map[string]bool)
+	for _, t := range templates {
+		if t.Immutable {
+			immutable[t.Key] = true
+		}
+	}
+
+	current := make(map[string]configVar)
+	for _, cv := range existing {
+		current[cv.Key] = cv
+	}
+
+	for _, cv := range desired {
+		if !immutable[cv.Key] {
+			continue
+		}
+		if e, ok := current[cv.Key]; !ok || !reflect.DeepEqual(e, cv) {
+			changed = append(changed, cv.Key)
+		}
+	}
+	sort.Strings(changed)
+	return changed
+}
+
+// ValidateRequiredConfigVars checks the connection sets every config variable the
+// connector version marks as required. Missing variables are logged as warnings, or
+// returned as an error when strict is set
+func ValidateRequiredConfigVars(content []byte, strict bool) (err error) {
+	c := connectionRequest{}
+	if err = json.Unmarshal(content, &c); err != nil {
+		return err
+	}
+	if c.ConnectorDetails == nil || c.ConnectorDetails.Provider == "customconnector" {
+		return nil
+	}
+
+	version := c.ConnectorDetails.Version
+	if version == nil {
+		if version, err = getLatestGAVersion(c.ConnectorDetails.Provider, c.ConnectorDetails.Name); err != nil {
+			return err
+		}
+	}
+
+	apiclient.ClientPrintHttpResponse.Set(false)
+	respBody, err := GetConnectorVersion(c.ConnectorDetails.Provider, c.ConnectorDetails.Name,
+		strconv.Itoa(*version), true)
+	apiclient.ClientPrintHttpResponse.Set(apiclient.GetCmdPrintHttpResponseSetting())
+	if err != nil {
+		clilog.Warning.Printf("unable to check for required config variables: %v\n", err)
+		return nil
+	}
+	v := connectorVersion{}
+	if err = json.Unmarshal(respBody, &v); err != nil {
+		return fmt.Errorf("failed to unmarshall: %w", err)
+	}
+
+	configVars := []configVar{}
+	if c.ConfigVariables != nil {
+		configVars = *c.ConfigVariables
+	}
+	missing := getMissingConfigVars(v.ConfigVariableTemplates, configVars)
+	if len(missing) == 0 {
+		return nil
+	}
+
+	msg := fmt.Sprintf("required config variables are missing or empty: %s", strings.Join(missing, ", "))
+	if strict {
+		return errors.New(msg)
+	}
+	clilog.Warning.Println(msg)
+	return nil
+}
+
+// checkLaunchStage returns an error if the connector version resource name is not
+// GA, unless allowPreview is set. Custom connectors have no launch stage
+func checkLaunchStage(connectorVersion string, allowPreview bool) (err error) {
+	if allowPreview {
+		return nil
+	}
+	provider, connector := getConnectorProvider(connectorVersion), getConnectorName(connectorVersion)
+	if provider == "customconnector" {
+		return nil
+	}
+	version := getConnectorVersionId(connectorVersion)
+
+	stage, err := getLaunchStage(provider, connector, version)
+	if err != nil {
+		return fmt.Errorf("unable to check the launch stage of connector %s version %s: %w",
+			connector, version, err)
+	}
+
+	if stage != gaLaunchStage {
+		return fmt.Errorf("connector %s version %s is in launch stage %s, not %s; "+
+			"use --allow-preview to create it anyway",
+			connector, version, stage, gaLaunchStage)
+	}
+	return nil
+}
+
+// getLaunchStage returns the launch stage of the connector version
+func getLaunchStage(provider string, connector string, version string) (stage string, err error) {
+	apiclient.ClientPrintHttpResponse.Set(false)
+	respBody, err := GetConnectorVersion(provider, connector, version, false)
+	apiclient.ClientPrintHttpResponse.Set(apiclient.GetCmdPrintHttpResponseSetting())
+	if err != nil {
+		return "", err
+	}
+	v := connectorVersion{}
+	if err = json.Unmarshal(respBody, &v); err != nil {
+		return "", fmt.Errorf("failed to unmarshall: %w", err)
+	}
+	return v.LaunchStage, nil
+}
+
+// setConnectorLaunchStage sets the launch stage of connectorVersion on the connector details.
+// A failed lookup only logs a warning, the stage is informational.
+func setConnectorLaunchStage(d *connectorDetails, connectorVersion string) {
+	if getConnectorProvider(connectorVersion) == "customconnector" {
+		return
+	}
+	if stage, ok := launchStages[connectorVersion]; ok {
+		d.LaunchStage = stage
+		return
+	}
+	stage, err := getLaunchStage(getConnectorProvider(connectorVersion), getConnectorName(connectorVersion),
+		getConnectorVersionId(connectorVersion))
+	if err != nil {
+		clilog.Warning.Printf("unable to get the launch stage of %s: %v\n", connectorVersion, err)
+		return
+	}
+	launchStages[connectorVersion] = stage
+	d.LaunchStage = stage
+}
+
+// getMissingConfigVars returns the keys of the required templates that have no value
+func getMissingConfigVars(templates []configVariableTemplate, configVars []configVar) (missing []string) {
+	set := make(map[string]bool)
+	for _, cv := range configVars {
+		if (cv.StringValue != nil && *cv.StringValue != "") || cv.IntValue != nil || cv.BoolValue != nil ||
+			cv.SecretValue != nil || cv.SecretDetails != nil {
+			set[cv.Key] = true
+		}
+	}
+	for _, t := range templates {
+		if t.Required && !set[t.Key] {
+			missing = append(missing, t.Key)
+		}
+	}
+	return missing
+}
@@ -0,0 +1,43 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package connections
+
+import "testing"
+
+func TestGetLatestConnectorVersion(t *testing.T) {
+	const prefix = "projects/my-project/locations/global/providers/gcp/connectors/pubsub/versions/"
+	versions := []connectorVersion{
+		{Name: prefix + "1", LaunchStage: gaLaunchStage},
+		{Name: prefix + "2", LaunchStage: gaLaunchStage},
+		{Name: prefix + "3", LaunchStage: "PREVIEW"},
+		{Name: prefix + "4", LaunchStage: deprecatedLaunchStage},
+	}
+	tests := []struct {
+		launchStage string
+		expected    string
+		found       bool
+	}{
+		{"", prefix + "3", true},
+		{gaLaunchStage, prefix + "2", true},
+		{deprecatedLaunchStage, "", false},
+	}
+	for _, test := range tests {
+		latest, found := getLatestConnectorVersion(versions, test.launchStage)
+		if found != test.found || latest.Name != test.expected {
+			t.Errorf("%q: expected %s (found %t), got %s (found %t)",
+				test.launchStage, test.expected, test.found, latest.Name, found)
+		}
+	}
+}
@@ -537,7 +537,7 @@ func getNewConnectionParams(connectionName string, connectionLocation string) (c
 			return cp, err
 		}
 	}
-	connResp, err := connections.Get(connectionName, "BASIC", false, false) // get connector details
+	connResp, err := connections.Get(connectionName, "BASIC", false, false, false) // get connector details
 	if connectionLocation != "" {
 		err = apiclient.SetRegion(integrationRegion) // set the integration region back
 		if err != nil {
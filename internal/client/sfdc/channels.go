@@ -55,8 +55,6 @@ func CreateChannelFromContent(instanceVersion string, content []byte) (respBody
 	}
 
 	u, _ := url.Parse(apiclient.GetBaseIntegrationURL())
-	u.Path = path.Join(u.Path, "sfdcInstances")
-
 	u.Path = path.Join(u.Path, "sfdcInstances", instanceVersion, "sfdcChannels")
 	respBody, err = apiclient.HttpClient(u.String(), string(content))
 	return respBody, err
@@ -114,6 +112,14 @@ func ListChannels(instance string) (respBody []byte, err error) {
 	return respBody, err
 }
 
+// DeleteChannel
+func DeleteChannel(name string, instance string) (respBody []byte, err error) {
+	u, _ := url.Parse(apiclient.GetBaseIntegrationURL())
+	u.Path = path.Join(u.Path, "sfdcInstances", instance, "sfdcChannels", name)
+	respBody, err = apiclient.HttpClient(u.String(), "", "DELETE")
+	return respBody, err
+}
+
 // FindChannel
 func FindChannel(name string, instance string) (version string, respBody []byte, err error) {
 	clist := channels{}
@@ -16,14 +16,21 @@ package sfdc
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/url"
+	"os"
 	"path"
+	"path/filepath"
+	"strconv"
 	"strings"
 
 	"internal/apiclient"
+	"internal/clilog"
 )
 
+const maxPageSize = 1000
+
 type instance struct {
 	Name             string   `json:"name,omitempty"`
 	DisplayName      string   `json:"displayName,omitempty"`
@@ -117,8 +124,21 @@ func GetInstance(name string, minimal bool) (respBody []byte, err error) {
 
 // ListInstances
 func ListInstances() (respBody []byte, err error) {
+	return listInstances(-1, "")
+}
+
+// listInstances
+func listInstances(pageSize int, pageToken string) (respBody []byte, err error) {
 	u, _ := url.Parse(apiclient.GetBaseIntegrationURL())
 	u.Path = path.Join(u.Path, "sfdcInstances")
+	q := u.Query()
+	if pageSize != -1 {
+		q.Set("pageSize", strconv.Itoa(pageSize))
+	}
+	if pageToken != "" {
+		q.Set("pageToken", pageToken)
+	}
+	u.RawQuery = q.Encode()
 	respBody, err = apiclient.HttpClient(u.String())
 	return respBody, err
 }
@@ -145,6 +165,134 @@ func FindInstance(name string) (version string, respBody []byte, err error) {
 	return "", nil, fmt.Errorf("instance not found")
 }
 
+// listAllInstances returns the sfdc instances of every page
+func listAllInstances() (linstances []instance, err error) {
+	pageToken := ""
+
+	for {
+		l := instances{}
+		respBody, err := listInstances(maxPageSize, pageToken)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch sfdc instances: %w", err)
+		}
+		if err = json.Unmarshal(respBody, &l); err != nil {
+			return nil, fmt.Errorf("failed to unmarshall: %w", err)
+		}
+		linstances = append(linstances, l.SfdcInstances...)
+		pageToken = l.NextPageToken
+		if l.NextPageToken == "" {
+			break
+		}
+	}
+	return linstances, nil
+}
+
+// ExportInstances
+func ExportInstances(folder string) (err error) {
+	apiclient.SetExportToFile(folder)
+	apiclient.ClientPrintHttpResponse.Set(false)
+	defer apiclient.ClientPrintHttpResponse.Set(apiclient.GetCmdPrintHttpResponseSetting())
+
+	linstances, err := listAllInstances()
+	if err != nil {
+		return err
+	}
+
+	// no instances were found
+	if len(linstances) == 0 {
+		return nil
+	}
+
+	for _, linstance := range linstances {
+		fileName := linstance.DisplayName + ".json"
+		// the display name, not the resource name, identifies the instance on import
+		einstance := convertInternalInstanceToExternal(linstance)
+		einstance.DisplayName = linstance.DisplayName
+		instancePayload, err := json.Marshal(einstance)
+		if err != nil {
+			return err
+		}
+		if err = apiclient.WriteByteArrayToFile(
+			path.Join(apiclient.GetExportToFile(), fileName),
+			false,
+			instancePayload); err != nil {
+			clilog.Error.Println(err)
+			return err
+		}
+		clilog.Info.Printf("Downloaded %s\n", fileName)
+	}
+
+	return nil
+}
+
+// ImportInstances
+func ImportInstances(folder string) (err error) {
+	apiclient.ClientPrintHttpResponse.Set(false)
+	defer apiclient.ClientPrintHttpResponse.Set(apiclient.GetCmdPrintHttpResponseSetting())
+	errs := []string{}
+
+	// display names are not unique keys, so every page is checked once before the
+	// creates and a failed list stops the import instead of creating duplicates
+	linstances, err := listAllInstances()
+	if err != nil {
+		return err
+	}
+	displayNames := make(map[string]bool)
+	for _, linstance := range linstances {
+		displayNames[linstance.DisplayName] = true
+	}
+
+	err = filepath.Walk(folder, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			clilog.Warning.Println("sfdc instances folder not found")
+			return nil
+		}
+		if info.IsDir() {
+			return nil
+		}
+		if filepath.Ext(path) != ".json" {
+			return nil
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+
+		i := instanceExternal{}
+		if err = json.Unmarshal(content, &i); err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %v", path, err))
+			return nil
+		}
+		if i.DisplayName == "" {
+			i.DisplayName = strings.TrimSuffix(filepath.Base(path), filepath.Ext(filepath.Base(path)))
+			if content, err = json.Marshal(i); err != nil {
+				return err
+			}
+		}
+
+		if !displayNames[i.DisplayName] { // create only if instance doesn't exist
+			clilog.Info.Printf("creating sfdc instance %s\n", i.DisplayName)
+			if _, err = CreateInstanceFromContent(content); err != nil {
+				errs = append(errs, err.Error())
+			} else {
+				displayNames[i.DisplayName] = true
+			}
+		} else {
+			clilog.Info.Printf("sfdc instance %s already exists, skipping creations\n", i.DisplayName)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if len(errs) > 0 {
+		return errors.New(strings.Join(errs, "\n"))
+	}
+	return nil
+}
+
 // convertInternalInstanceToExternal
 func convertInternalInstanceToExternal(internalVersion instance) (externalVersion instanceExternal) {
 	externalVersion = instanceExternal{}
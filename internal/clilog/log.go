@@ -15,9 +15,13 @@
 package clilog
 
 import (
+	"encoding/json"
+	"fmt"
 	"io"
 	"log"
 	"os"
+	"strings"
+	"time"
 )
 
 // log levels, default is error
@@ -30,6 +34,69 @@ var (
 	HTTPError    *log.Logger
 )
 
+// jsonFormat emits the log statements as JSON lines when set
+var jsonFormat bool
+
+// SetFormat sets the log format, text or json. It applies to the loggers created by Init
+func SetFormat(format string) error {
+	switch format {
+	case "", "text":
+		jsonFormat = false
+	case "json":
+		jsonFormat = true
+	default:
+		return fmt.Errorf("invalid log format %s, must be text or json", format)
+	}
+	return nil
+}
+
+// jsonEntry is a structured log statement
+type jsonEntry struct {
+	Severity string            `json:"severity"`
+	Message  string            `json:"message"`
+	Time     string            `json:"time"`
+	Fields   map[string]string `json:"fields,omitempty"`
+}
+
+// jsonWriter writes every log statement as a JSON line with its severity. When
+// withFile is set, the file:line prefix added by the logger is moved to the fields
+type jsonWriter struct {
+	out      io.Writer
+	severity string
+	withFile bool
+}
+
+func (w jsonWriter) Write(p []byte) (int, error) {
+	entry := jsonEntry{
+		Severity: w.severity,
+		Message:  strings.TrimSuffix(string(p), "\n"),
+		Time:     time.Now().UTC().Format(time.RFC3339Nano),
+	}
+	if w.withFile {
+		if file, message, found := strings.Cut(entry.Message, ": "); found {
+			entry.Message = message
+			entry.Fields = map[string]string{"file": file}
+		}
+	}
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return 0, err
+	}
+	if _, err = w.out.Write(append(line, '\n')); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// newLogger returns a text logger, or a JSON logger when the json format is set
+func newLogger(out io.Writer, prefix string, flag int, severity string) *log.Logger {
+	if jsonFormat && out != io.Discard {
+		withFile := flag&log.Lshortfile != 0
+		return log.New(jsonWriter{out: out, severity: severity, withFile: withFile}, "", flag&log.Lshortfile)
+	}
+	return log.New(out, prefix, flag)
+}
+
 // Init function initializes the logger objects
 func Init(debug bool, print bool, noOutput bool, suppressWarnings bool) {
 	debugHandle := io.Discard
@@ -60,24 +127,25 @@ func Init(debug bool, print bool, noOutput bool, suppressWarnings bool) {
 		warningHandle = io.Discard
 	}
 
-	Debug = log.New(debugHandle,
+	Debug = newLogger(debugHandle,
 		"DEBUG: ",
-		log.Ldate|log.Ltime|log.Lshortfile)
+		log.Ldate|log.Ltime|log.Lshortfile, "DEBUG")
 
-	Info = log.New(infoHandle,
-		"", 0)
+	Info = newLogger(infoHandle,
+		"", 0, "INFO")
 
-	Warning = log.New(warningHandle,
+	Warning = newLogger(warningHandle,
 		"WARNING: ",
-		log.Ldate|log.Ltime|log.Lshortfile)
+		log.Ldate|log.Ltime|log.Lshortfile, "WARNING")
 
-	Error = log.New(errorHandle,
+	Error = newLogger(errorHandle,
 		"ERROR: ",
-		log.Ldate|log.Ltime|log.Lshortfile)
+		log.Ldate|log.Ltime|log.Lshortfile, "ERROR")
 
+	// responses are the command output and are never wrapped in log statements
 	HTTPResponse = log.New(responseHandle,
 		"", 0)
 
-	HTTPError = log.New(errorHandle,
-		"", 0)
+	HTTPError = newLogger(errorHandle,
+		"", 0, "ERROR")
 }
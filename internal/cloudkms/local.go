@@ -0,0 +1,98 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cloudkms
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"os"
+)
+
+// EncryptLocal encrypts the plaintext with the AES key in keyFile and returns the base64
+// encoded AES-GCM nonce followed by the sealed data, the format DecryptLocal reads
+func EncryptLocal(keyFile string, plaintext []byte) (b64CipherText string, err error) {
+	gcm, err := newLocalGCM(keyFile)
+	if err != nil {
+		return "", err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err = rand.Read(nonce); err != nil {
+		return "", err
+	}
+
+	return base64.StdEncoding.EncodeToString(gcm.Seal(nonce, nonce, plaintext, nil)), nil
+}
+
+// DecryptLocal decrypts the base64 encoded ciphertext with the AES key in keyFile. The
+// ciphertext is the AES-GCM nonce followed by the sealed data. keyFile contains the
+// base64 encoded 16, 24 or 32 byte key
+func DecryptLocal(keyFile string, b64CipherText []byte) ([]byte, error) {
+	gcm, err := newLocalGCM(keyFile)
+	if err != nil {
+		return nil, err
+	}
+
+	cipherText, err := base64.StdEncoding.DecodeString(string(bytes.TrimSpace(b64CipherText)))
+	if err != nil {
+		return nil, fmt.Errorf("decode: %v", err)
+	}
+
+	if len(cipherText) < gcm.NonceSize() {
+		return nil, fmt.Errorf("decrypt: ciphertext is too short")
+	}
+
+	nonce, sealed := cipherText[:gcm.NonceSize()], cipherText[gcm.NonceSize():]
+	plaintext, err := gcm.Open(nil, nonce, sealed, nil)
+	if err != nil {
+		return nil, fmt.Errorf("decrypt: %v", err)
+	}
+
+	return bytes.TrimSpace(plaintext), nil
+}
+
+// newLocalGCM returns the AES-GCM cipher of the key in keyFile
+func newLocalGCM(keyFile string) (cipher.AEAD, error) {
+	key, err := readLocalKey(keyFile)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// readLocalKey reads the base64 encoded AES key from keyFile, ignoring surrounding whitespace
+func readLocalKey(keyFile string) ([]byte, error) {
+	content, err := os.ReadFile(keyFile)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read key file %s: %w", keyFile, err)
+	}
+	key, err := base64.StdEncoding.DecodeString(string(bytes.TrimSpace(content)))
+	if err != nil || !validKeySize(key) {
+		return nil, fmt.Errorf("key file %s must contain a base64 encoded 16, 24 or 32 byte AES key", keyFile)
+	}
+	return key, nil
+}
+
+func validKeySize(key []byte) bool {
+	return len(key) == 16 || len(key) == 24 || len(key) == 32
+}
@@ -0,0 +1,54 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cloudkms
+
+import (
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestEncryptDecryptLocal(t *testing.T) {
+	key := make([]byte, 32)
+	for i := range key {
+		key[i] = byte(i)
+	}
+	keyFile := filepath.Join(t.TempDir(), "key")
+	// a trailing newline, as written by openssl rand -base64 32 > key
+	if err := os.WriteFile(keyFile, []byte(base64.StdEncoding.EncodeToString(key)+"\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	b64CipherText, err := EncryptLocal(keyFile, []byte("s3cret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	plaintext, err := DecryptLocal(keyFile, []byte(b64CipherText+"\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(plaintext) != "s3cret" {
+		t.Errorf("expected s3cret, got %s", plaintext)
+	}
+
+	// a raw key is rejected instead of being read as a different key
+	if err = os.WriteFile(keyFile, key, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = DecryptLocal(keyFile, []byte(b64CipherText)); err == nil {
+		t.Errorf("expected a raw key to be rejected")
+	}
+}
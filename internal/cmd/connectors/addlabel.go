@@ -0,0 +1,59 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package connectors
+
+import (
+	"internal/apiclient"
+
+	"internal/client/connections"
+
+	"github.com/spf13/cobra"
+)
+
+// AddLabelCmd to set a label on a connection
+var AddLabelCmd = &cobra.Command{
+	Use:   "add-label",
+	Short: "Set a label on an existing connection",
+	Long: "Set a label on an existing connection, replacing its value if the key is already " +
+		"set. Other labels on the connection are preserved",
+	Args: func(cmd *cobra.Command, args []string) (err error) {
+		cmdProject := cmd.Flag("proj")
+		cmdRegion := cmd.Flag("reg")
+
+		if err = apiclient.SetRegion(cmdRegion.Value.String()); err != nil {
+			return err
+		}
+		return apiclient.SetProjectID(cmdProject.Value.String())
+	},
+	RunE: func(cmd *cobra.Command, args []string) (err error) {
+		_, err = connections.AddLabel(cmd.Flag("name").Value.String(),
+			cmd.Flag("key").Value.String(), cmd.Flag("value").Value.String())
+		return err
+	},
+}
+
+func init() {
+	var name, key, value string
+
+	AddLabelCmd.Flags().StringVarP(&name, "name", "n",
+		"", "Connection name")
+	AddLabelCmd.Flags().StringVarP(&key, "key", "k",
+		"", "Label key")
+	AddLabelCmd.Flags().StringVarP(&value, "value", "",
+		"", "Label value")
+
+	_ = AddLabelCmd.MarkFlagRequired("name")
+	_ = AddLabelCmd.MarkFlagRequired("key")
+}
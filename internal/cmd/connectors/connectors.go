@@ -15,6 +15,11 @@
 package connectors
 
 import (
+	"fmt"
+	"time"
+
+	"internal/apiclient"
+
 	"github.com/spf13/cobra"
 )
 
@@ -47,4 +52,47 @@ func init() {
 	Cmd.AddCommand(ManagedZonesCmd)
 	Cmd.AddCommand(CustomCmd)
 	Cmd.AddCommand(EventSubCmd)
+	Cmd.AddCommand(PatchConfigVarsCmd)
+	Cmd.AddCommand(LintCmd)
+	Cmd.AddCommand(SetLogLevelCmd)
+	Cmd.AddCommand(RotateSecretCmd)
+	Cmd.AddCommand(FindCmd)
+	Cmd.AddCommand(TemplateCmd)
+	Cmd.AddCommand(PatchDestinationsCmd)
+	Cmd.AddCommand(HealthCmd)
+	Cmd.AddCommand(ListSecretsCmd)
+	Cmd.AddCommand(SetServiceAccountCmd)
+	Cmd.AddCommand(CopySecretsCmd)
+	Cmd.AddCommand(DriftCmd)
+	Cmd.AddCommand(ReplaceCmd)
+	Cmd.AddCommand(GrantSecretAccessCmd)
+	Cmd.AddCommand(WaitCmd)
+	Cmd.AddCommand(DiffFolderCmd)
+	Cmd.AddCommand(SetScalingCmd)
+	Cmd.AddCommand(DescribeAuthCmd)
+	Cmd.AddCommand(AddLabelCmd)
+	Cmd.AddCommand(RemoveLabelCmd)
+	Cmd.AddCommand(ValidateFolderCmd)
+	Cmd.AddCommand(RepairEventingCmd)
+	Cmd.AddCommand(RefreshTokenCmd)
+}
+
+// addWaitFlags adds the flags that tune how a command waits on operations
+func addWaitFlags(cmd *cobra.Command) {
+	var waitInterval, waitTimeout time.Duration
+
+	cmd.Flags().DurationVarP(&waitInterval, "wait-interval", "",
+		10*time.Second, "Interval between checks of the operation status when waiting")
+	cmd.Flags().DurationVarP(&waitTimeout, "wait-timeout", "",
+		0, "Stop waiting for the operation after this long, like 10m; default is to wait until it is done")
+}
+
+// setWaitTiming validates the wait flags of the command and applies them
+func setWaitTiming(cmd *cobra.Command) error {
+	waitInterval, _ := time.ParseDuration(cmd.Flag("wait-interval").Value.String())
+	waitTimeout, _ := time.ParseDuration(cmd.Flag("wait-timeout").Value.String())
+	if waitInterval <= 0 {
+		return fmt.Errorf("wait-interval must be greater than 0")
+	}
+	return apiclient.SetWaitTiming(waitInterval, waitTimeout)
 }
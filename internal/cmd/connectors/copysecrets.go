@@ -0,0 +1,58 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package connectors
+
+import (
+	"internal/apiclient"
+
+	"internal/client/connections"
+
+	"github.com/spf13/cobra"
+)
+
+// CopySecretsCmd to copy the secrets referenced by a connection to another project
+var CopySecretsCmd = &cobra.Command{
+	Use:   "copy-secrets",
+	Short: "Copy the secrets referenced by a connection to another project",
+	Long: "Create the Secret Manager secrets referenced by a connection in the target project " +
+		"with the payload of the referenced versions. Secrets that already exist in the target " +
+		"project are not changed",
+	Args: func(cmd *cobra.Command, args []string) (err error) {
+		cmdProject := cmd.Flag("proj")
+		cmdRegion := cmd.Flag("reg")
+
+		if err = apiclient.SetRegion(cmdRegion.Value.String()); err != nil {
+			return err
+		}
+		return apiclient.SetProjectID(cmdProject.Value.String())
+	},
+	RunE: func(cmd *cobra.Command, args []string) (err error) {
+		_, err = connections.CopySecrets(cmd.Flag("name").Value.String(),
+			cmd.Flag("target-proj").Value.String())
+		return err
+	},
+}
+
+func init() {
+	var name, targetProject string
+
+	CopySecretsCmd.Flags().StringVarP(&name, "name", "n",
+		"", "Connection name")
+	CopySecretsCmd.Flags().StringVarP(&targetProject, "target-proj", "",
+		"", "Project to create the secrets in")
+
+	_ = CopySecretsCmd.MarkFlagRequired("name")
+	_ = CopySecretsCmd.MarkFlagRequired("target-proj")
+}
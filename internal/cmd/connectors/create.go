@@ -42,9 +42,16 @@ var CreateCmd = &cobra.Command{
 		return apiclient.SetProjectID(cmdProject.Value.String())
 	},
 	RunE: func(cmd *cobra.Command, args []string) (err error) {
+		if err = setWaitTiming(cmd); err != nil {
+			return err
+		}
+		allowPreview, _ := strconv.ParseBool(cmd.Flag("allow-preview").Value.String())
 		createSecret, _ := strconv.ParseBool(cmd.Flag("create-secret").Value.String())
 		grantPermission, _ := strconv.ParseBool(cmd.Flag("grant-permission").Value.String())
 		wait, _ := strconv.ParseBool(cmd.Flag("wait").Value.String())
+		strictIAM, _ := strconv.ParseBool(cmd.Flag("strict-iam").Value.String())
+		apply, _ := strconv.ParseBool(cmd.Flag("apply").Value.String())
+		updateSecrets, _ := strconv.ParseBool(cmd.Flag("update-secrets").Value.String())
 		name := cmd.Flag("name").Value.String()
 
 		if _, err = os.Stat(connectionFile); err != nil {
@@ -65,18 +72,90 @@ var CreateCmd = &cobra.Command{
 			}
 		}
 
-		_, err = connections.Create(name, content, serviceAccountName,
-			serviceAccountProject, encryptionKey, grantPermission, createSecret, wait)
+		if content, err = connections.SetAuthAdditionalVariables(content, authVars); err != nil {
+			return err
+		}
+
+		if content, err = connections.SetDestinations(content, destinations); err != nil {
+			return err
+		}
+
+		// the bundle is only usable once its secret is created
+		caBundleFile := cmd.Flag("ca-bundle").Value.String()
+		if caBundleFile != "" && !createSecret {
+			return fmt.Errorf("ca-bundle requires create-secret")
+		}
+		if content, err = connections.SetCABundle(content, name, caBundleFile); err != nil {
+			return err
+		}
+
+		minNodes, _ := strconv.Atoi(cmd.Flag("min-nodes").Value.String())
+		maxNodes, _ := strconv.Atoi(cmd.Flag("max-nodes").Value.String())
+		if content, err = connections.SetNodeCounts(content, minNodes, maxNodes); err != nil {
+			return err
+		}
+
+		strict, _ := strconv.ParseBool(cmd.Flag("strict").Value.String())
+		if err = connections.ValidateRequiredConfigVars(content, strict); err != nil {
+			return err
+		}
+
+		opts := connections.CreateOptions{
+			AllowPreview: allowPreview,
+			LocalKeyFile: cmd.Flag("local-key-file").Value.String(),
+		}
+
+		// the plan records the endpoint attachments it would create
+		checkEndpoints, _ := strconv.ParseBool(cmd.Flag("check-endpoints").Value.String())
+		if plan, _ := strconv.ParseBool(cmd.Flag("plan").Value.String()); plan {
+			return connections.PlanCreate(name, content, serviceAccountName,
+				serviceAccountProject, encryptionKey, grantPermission, createSecret,
+				checkEndpoints, endpointFiles, opts)
+		}
+
+		if checkEndpoints || len(endpointFiles) > 0 {
+			if err = connections.EnsureEndpointAttachments(content, endpointFiles); err != nil {
+				return err
+			}
+		}
+
+		// the connection state can only be checked once its operation is done
+		waitActive, _ := strconv.ParseBool(cmd.Flag("wait-active").Value.String())
+		if waitActive {
+			wait = true
+		}
 
-		return err
+		if apply {
+			_, err = connections.Apply(name, content, serviceAccountName,
+				serviceAccountProject, encryptionKey, grantPermission, createSecret, wait, strictIAM, updateSecrets,
+				opts)
+		} else {
+			_, err = connections.Create(name, content, serviceAccountName,
+				serviceAccountProject, encryptionKey, grantPermission, createSecret, wait, strictIAM, updateSecrets,
+				opts)
+		}
+		if err != nil || !waitActive {
+			return err
+		}
+		return connections.WaitForConnectionActive(name)
 	},
 }
 
 var connectionFile, serviceAccountName, serviceAccountProject, encryptionKey string
 
+var (
+	endpointFiles []string
+	authVars      map[string]string
+	destinations  []string
+)
+
 func init() {
 	var name string
-	grantPermission, wait, createSecret := false, false, false
+	grantPermission, wait, createSecret, strictIAM, apply, updateSecrets, strict := false, false, false, false, false, false, false
+	checkEndpoints, allowPreview, plan := false, false, false
+	minNodes, maxNodes := -1, -1
+	waitActive := false
+	var localKeyFile, caBundleFile string
 
 	CreateCmd.Flags().StringVarP(&name, "name", "n",
 		"", "Connection name")
@@ -90,11 +169,51 @@ func init() {
 		"", "Service Account Project for the connection. Default is the connection's project id")
 	CreateCmd.Flags().StringVarP(&encryptionKey, "encryption-keyid", "k",
 		"", "Cloud KMS key for decrypting Auth Config; Format = locations/*/keyRings/*/cryptoKeys/*")
+	CreateCmd.Flags().StringVarP(&localKeyFile, "local-key-file", "",
+		"", "File with a base64 encoded 16, 24 or 32 byte AES key for decrypting secret files instead of Cloud KMS")
 	CreateCmd.Flags().BoolVarP(&wait, "wait", "",
 		false, "Waits for the connector to finish, with success or error; default is false")
 	CreateCmd.Flags().BoolVarP(&createSecret, "create-secret", "",
 		false, "Create Secret Manager secrets when creating the connection; default is false")
-
+	CreateCmd.Flags().BoolVarP(&strictIAM, "strict-iam", "",
+		false, "Fail the create when granting IAM permissions fails; by default failures are logged as warnings")
+	CreateCmd.Flags().BoolVarP(&apply, "apply", "",
+		false, "Create the connection if it does not exist, otherwise update the fields that changed")
+	CreateCmd.Flags().BoolVarP(&updateSecrets, "update-secrets", "",
+		false, "Add a secret version to existing secrets when the payload changed; by default the latest version is used")
+	CreateCmd.Flags().BoolVarP(&strict, "strict", "",
+		false, "Fail the create when required config variables are missing; by default they are logged as warnings")
+	CreateCmd.Flags().StringToStringVarP(&authVars, "auth-vars", "",
+		nil, "Additional auth config variables, like audience=api,scope=read; set secrets in the file "+
+			"with secretDetails")
+	CreateCmd.Flags().StringArrayVarP(&destinations, "destination", "",
+		nil, "Destination in the format key=host:port, like base_url=api.example.com:443; "+
+			"repeat the flag to add more. Replaces the destinations of the key in the file")
+	CreateCmd.Flags().StringVarP(&caBundleFile, "ca-bundle", "",
+		"", "PEM file of the private CA certificates to trust, stored as the <name>-ca-bundle secret "+
+			"and set as the sslConfig privateServerCertificate; requires --create-secret. With "+
+			"--encryption-keyid or --local-key-file the file is decrypted with the same key as the "+
+			"other secret files, so it must be encrypted with that key too")
+	CreateCmd.Flags().IntVarP(&minNodes, "min-nodes", "",
+		-1, "Min node count of the connection, overrides nodeConfig in the file")
+	CreateCmd.Flags().IntVarP(&maxNodes, "max-nodes", "",
+		-1, "Max node count of the connection, overrides nodeConfig in the file")
+	CreateCmd.Flags().BoolVarP(&waitActive, "wait-active", "",
+		false, "Wait for the create and then for the connection state to be ACTIVE, failing "+
+			"if it ends in ERROR; implies --wait")
+	CreateCmd.Flags().BoolVarP(&plan, "plan", "",
+		false, "Print the service accounts, secrets and IAM bindings the create would apply without creating them")
+	CreateCmd.Flags().BoolVarP(&allowPreview, "allow-preview", "",
+		false, "Allow creating the connection on a connector version that is not GA; default is false")
+	CreateCmd.Flags().BoolVarP(&checkEndpoints, "check-endpoints", "",
+		false, "Fail the create when a destination serviceAttachment has no endpoint attachment; default is false")
+	CreateCmd.Flags().StringArrayVarP(&endpointFiles, "endpoint-file", "",
+		nil, "Endpoint attachment definition file, named after the endpoint attachment, to create "+
+			"when a destination serviceAttachment has no endpoint attachment; implies --check-endpoints")
+	addWaitFlags(CreateCmd)
+
+	CreateCmd.MarkFlagsMutuallyExclusive("encryption-keyid", "local-key-file")
+	CreateCmd.MarkFlagsMutuallyExclusive("plan", "apply")
 	_ = CreateCmd.MarkFlagRequired("name")
 	_ = CreateCmd.MarkFlagRequired("file")
 }
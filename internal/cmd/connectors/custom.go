@@ -31,4 +31,6 @@ func init() {
 	CustomCmd.AddCommand(DelCustomCmd)
 	CustomCmd.AddCommand(CrtCustomCmd)
 	CustomCmd.AddCommand(CustomVerCmd)
+	CustomCmd.AddCommand(ExportCustomCmd)
+	CustomCmd.AddCommand(ImportCustomCmd)
 }
@@ -16,6 +16,7 @@ package connectors
 
 import (
 	"errors"
+	"strconv"
 
 	"internal/apiclient"
 
@@ -42,17 +43,25 @@ var DelCmd = &cobra.Command{
 		return apiclient.SetProjectID(cmdProject.Value.String())
 	},
 	RunE: func(cmd *cobra.Command, args []string) (err error) {
+		if err = setWaitTiming(cmd); err != nil {
+			return err
+		}
 		name := cmd.Flag("name").Value.String()
-		_, err = connections.Delete(name)
+		wait, _ := strconv.ParseBool(cmd.Flag("wait").Value.String())
+		_, err = connections.Delete(name, wait)
 		return
 	},
 }
 
 func init() {
 	var name string
+	var wait bool
 
 	DelCmd.Flags().StringVarP(&name, "name", "n",
 		"", "The name of the connection")
+	DelCmd.Flags().BoolVarP(&wait, "wait", "",
+		false, "Waits for the delete to finish, with success or error; default is false")
+	addWaitFlags(DelCmd)
 
 	_ = DelCmd.MarkFlagRequired("name")
 }
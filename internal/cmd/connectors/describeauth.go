@@ -0,0 +1,39 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package connectors
+
+import (
+	"internal/client/connections"
+
+	"github.com/spf13/cobra"
+)
+
+// DescribeAuthCmd to describe the fields of an auth type
+var DescribeAuthCmd = &cobra.Command{
+	Use:   "describe-auth",
+	Short: "Describe the fields an auth type needs",
+	Long: "Lists the authConfig fields each auth type needs in the connection JSON and which " +
+		"of them are secrets. Describes all supported auth types when --auth-type is not set",
+	RunE: func(cmd *cobra.Command, args []string) (err error) {
+		return connections.DescribeAuth(cmd.Flag("auth-type").Value.String())
+	},
+}
+
+func init() {
+	var authType string
+
+	DescribeAuthCmd.Flags().StringVarP(&authType, "auth-type", "",
+		"", "Auth type, for ex: USER_PASSWORD or OAUTH2_JWT_BEARER")
+}
@@ -0,0 +1,54 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package connectors
+
+import (
+	"internal/apiclient"
+
+	"internal/client/connections"
+
+	"github.com/spf13/cobra"
+)
+
+// DiffFolderCmd to compare a folder of connections with the region
+var DiffFolderCmd = &cobra.Command{
+	Use:   "diff-folder",
+	Short: "Compare a folder of connection files with the connections in a region",
+	Long: "Compare every connection file in a folder with the live connection and print whether " +
+		"it is unchanged, changed, or missing locally or remotely. Exits with an error if any " +
+		"connection differs, so it can be used to review or gate an import",
+	Args: func(cmd *cobra.Command, args []string) (err error) {
+		cmdProject := cmd.Flag("proj")
+		cmdRegion := cmd.Flag("reg")
+
+		if err = apiclient.SetRegion(cmdRegion.Value.String()); err != nil {
+			return err
+		}
+		return apiclient.SetProjectID(cmdProject.Value.String())
+	},
+	RunE: func(cmd *cobra.Command, args []string) (err error) {
+		_, err = connections.DiffFolder(cmd.Flag("folder").Value.String())
+		return err
+	},
+}
+
+func init() {
+	var folder string
+
+	DiffFolderCmd.Flags().StringVarP(&folder, "folder", "f",
+		"", "Folder with the connection files")
+
+	_ = DiffFolderCmd.MarkFlagRequired("folder")
+}
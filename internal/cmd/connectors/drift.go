@@ -0,0 +1,53 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package connectors
+
+import (
+	"internal/apiclient"
+
+	"internal/client/connections"
+
+	"github.com/spf13/cobra"
+)
+
+// DriftCmd to compare connections with the last export
+var DriftCmd = &cobra.Command{
+	Use:   "drift",
+	Short: "List connections changed since the last export",
+	Long: "Compare the connections in a region with the manifest.json written by export " +
+		"and list the connections that were changed, added or deleted since",
+	Args: func(cmd *cobra.Command, args []string) (err error) {
+		cmdProject := cmd.Flag("proj")
+		cmdRegion := cmd.Flag("reg")
+
+		if err = apiclient.SetRegion(cmdRegion.Value.String()); err != nil {
+			return err
+		}
+		return apiclient.SetProjectID(cmdProject.Value.String())
+	},
+	RunE: func(cmd *cobra.Command, args []string) (err error) {
+		_, err = connections.DetectDrift(cmd.Flag("folder").Value.String())
+		return err
+	},
+}
+
+func init() {
+	var folder string
+
+	DriftCmd.Flags().StringVarP(&folder, "folder", "f",
+		"", "Folder of a previous export with a manifest.json")
+
+	_ = DriftCmd.MarkFlagRequired("folder")
+}
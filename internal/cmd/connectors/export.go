@@ -15,6 +15,11 @@
 package connectors
 
 import (
+	"fmt"
+	"path"
+	"regexp"
+	"strconv"
+
 	"internal/apiclient"
 
 	"internal/client/connections"
@@ -26,7 +31,10 @@ import (
 var ExportCmd = &cobra.Command{
 	Use:   "export",
 	Short: "Export connections in a region to a folder",
-	Long:  "Export connections in a region to a folder",
+	Long: "Export all the connections in a region, or a single connection, to a folder. " +
+		"Set the region to all, or to a comma separated list of regions, to export the " +
+		"connections of each region to a sub folder named after the region. Exporting all the " +
+		"connections also writes a manifest.json with the hash of each file for use with drift",
 	Args: func(cmd *cobra.Command, args []string) (err error) {
 		cmdProject := cmd.Flag("proj")
 		cmdRegion := cmd.Flag("reg")
@@ -41,15 +49,134 @@ var ExportCmd = &cobra.Command{
 			return err
 		}
 
-		return connections.Export(folder)
+		exportSecrets, _ := strconv.ParseBool(cmd.Flag("export-secrets").Value.String())
+		includeSecretValues, _ := strconv.ParseBool(cmd.Flag("include-secret-values").Value.String())
+		bundleEndpoints, _ := strconv.ParseBool(cmd.Flag("bundle-endpoints").Value.String())
+		onlyChanged, _ := strconv.ParseBool(cmd.Flag("only-changed").Value.String())
+
+		if cmd.Flag("terraform-import-script").Changed && !cmd.Flag("terraform").Changed {
+			return fmt.Errorf("terraform-import-script can only be used with terraform")
+		}
+		encryptionKey := cmd.Flag("encryption-keyid").Value.String()
+
+		if encryptionKey != "" {
+			re := regexp.MustCompile(`locations\/([a-zA-Z0-9_-]+)\/keyRings\/([a-zA-Z0-9_-]+)\/cryptoKeys\/([a-zA-Z0-9_-]+)`)
+			ok := re.Match([]byte(encryptionKey))
+			if !ok {
+				return fmt.Errorf("encryption key must be of the format " +
+					"locations/{location}/keyRings/{test}/cryptoKeys/{cryptoKey}")
+			}
+		}
+
+		// secret payloads are only written to disk encrypted
+		if exportSecrets && encryptionKey == "" {
+			return fmt.Errorf("export-secrets requires encryption-keyid, " +
+				"secret payloads are not written in plaintext")
+		}
+		if includeSecretValues {
+			if encryptionKey == "" {
+				return fmt.Errorf("include-secret-values requires encryption-keyid, " +
+					"secret payloads are not written in plaintext")
+			}
+			exportSecrets = true
+		}
+
+		maxPageSize, _ := strconv.Atoi(cmd.Flag("max-page-size").Value.String())
+		if err = apiclient.SetListPageSize(maxPageSize); err != nil {
+			return err
+		}
+
+		launchStage, _ := strconv.ParseBool(cmd.Flag("launch-stage").Value.String())
+
+		if region := cmd.Flag("reg").Value.String(); connections.IsMultiRegion(region) {
+			regions, err := connections.GetRegions(region)
+			if err != nil {
+				return err
+			}
+			return connections.ExportRegions(folder, regions, exportSecrets, encryptionKey, exportLabels,
+				bundleEndpoints, onlyChanged, launchStage, includeSecretValues)
+		}
+
+		if single := cmd.Flag("single").Value.String(); single != "" {
+			apiclient.DisableCmdPrintHttpResponse()
+			return connections.ExportConnection(folder, single, launchStage)
+		}
+
+		if terraform, _ := strconv.ParseBool(cmd.Flag("terraform").Value.String()); terraform {
+			importScript, _ := strconv.ParseBool(cmd.Flag("terraform-import-script").Value.String())
+			return connections.ExportTerraform(folder, exportLabels, importScript)
+		}
+
+		if err = connections.Export(folder, exportLabels, onlyChanged, launchStage, includeSecretValues); err != nil {
+			return err
+		}
+
+		if bundleEndpoints {
+			if err = connections.ExportEndpoints(path.Join(folder, "endpoints"), exportLabels); err != nil {
+				return err
+			}
+		}
+
+		if exportSecrets {
+			return connections.ExportSecrets(path.Join(folder, "secrets"), encryptionKey, exportLabels)
+		}
+		return nil
 	},
 }
 
-var folder string
+var (
+	folder       string
+	exportLabels map[string]string
+)
 
 func init() {
+	var encryptionKey, single string
+	var maxPageSize int
+	var exportSecrets, includeSecretValues, terraform, importScript, launchStage, bundleEndpoints, onlyChanged bool
+
 	ExportCmd.Flags().StringVarP(&folder, "folder", "f",
 		"", "Folder to export connections")
+	ExportCmd.Flags().BoolVarP(&exportSecrets, "export-secrets", "",
+		false, "Export the payload of the secrets referenced by the connections, encrypted with "+
+			"encryption-keyid, to a secrets sub folder; requires encryption-keyid")
+	ExportCmd.Flags().BoolVarP(&includeSecretValues, "include-secret-values", "",
+		false, "Export the secrets encrypted with encryption-keyid and reference them from the connections, "+
+			"so import --create-secret --encryption-keyid restores the connections with their secrets; "+
+			"connections with OAUTH2_CLIENT_CREDENTIALS or SSH_PUBLIC_KEY secrets are rejected")
+	ExportCmd.Flags().StringVarP(&encryptionKey, "encryption-keyid", "k",
+		"", "Cloud KMS key for encrypting exported secrets; Format = locations/*/keyRings/*/cryptoKeys/*")
+	ExportCmd.Flags().BoolVarP(&terraform, "terraform", "",
+		false, "Export connections as Terraform resources with import blocks instead of JSON")
+	ExportCmd.Flags().BoolVarP(&importScript, "terraform-import-script", "",
+		false, "With terraform, write an import.sh of terraform import commands instead of import blocks")
+	ExportCmd.Flags().StringVarP(&single, "single", "",
+		"", "Name of a single connection to export")
+	ExportCmd.Flags().StringToStringVarP(&exportLabels, "labels", "",
+		nil, "Export only the connections with all these labels, like env=prod,team=data")
+
+	ExportCmd.Flags().BoolVarP(&launchStage, "launch-stage", "",
+		false, "Add the launch stage of the connector version to connectorDetails, to flag deprecated "+
+			"versions; costs one API call per connector version")
+
+	ExportCmd.Flags().BoolVarP(&bundleEndpoints, "bundle-endpoints", "",
+		false, "Export the endpoint attachments the connections use to an endpoints sub folder; "+
+			"import creates the missing ones first")
+
+	ExportCmd.Flags().BoolVarP(&onlyChanged, "only-changed", "",
+		false, "Don't write connection files that already hold the same connection, "+
+			"ignoring formatting and key order, to keep version control diffs small")
+
+	ExportCmd.Flags().IntVarP(&maxPageSize, "max-page-size", "",
+		1000, "Page size used to list the connections, between 1 and 1000; every page is fetched")
 
+	ExportCmd.MarkFlagsMutuallyExclusive("single", "export-secrets")
+	ExportCmd.MarkFlagsMutuallyExclusive("single", "include-secret-values")
+	ExportCmd.MarkFlagsMutuallyExclusive("terraform", "include-secret-values")
+	ExportCmd.MarkFlagsMutuallyExclusive("single", "bundle-endpoints")
+	ExportCmd.MarkFlagsMutuallyExclusive("terraform", "bundle-endpoints")
+	ExportCmd.MarkFlagsMutuallyExclusive("single", "only-changed")
+	ExportCmd.MarkFlagsMutuallyExclusive("terraform", "only-changed")
+	ExportCmd.MarkFlagsMutuallyExclusive("single", "terraform")
+	ExportCmd.MarkFlagsMutuallyExclusive("single", "labels")
 	_ = ExportCmd.MarkFlagRequired("folder")
 }
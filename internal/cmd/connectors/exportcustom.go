@@ -0,0 +1,56 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package connectors
+
+import (
+	"internal/apiclient"
+
+	"internal/client/connections"
+
+	"github.com/spf13/cobra"
+)
+
+// ExportCustomCmd to export custom connectors
+var ExportCustomCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export custom connectors to a folder",
+	Long: "Export all custom connectors in the project and their versions to a folder, one sub " +
+		"folder per custom connector with its definition and a versions folder",
+	Args: func(cmd *cobra.Command, args []string) (err error) {
+		cmdProject := cmd.Flag("proj")
+		cmdRegion := cmd.Flag("reg")
+
+		if err = apiclient.SetRegion(cmdRegion.Value.String()); err != nil {
+			return err
+		}
+		return apiclient.SetProjectID(cmdProject.Value.String())
+	},
+	RunE: func(cmd *cobra.Command, args []string) (err error) {
+		customFolder := cmd.Flag("folder").Value.String()
+		if err = apiclient.FolderExists(customFolder); err != nil {
+			return err
+		}
+		return connections.ExportCustomConnectors(customFolder)
+	},
+}
+
+func init() {
+	var customFolder string
+
+	ExportCustomCmd.Flags().StringVarP(&customFolder, "folder", "f",
+		"", "Folder to export custom connectors")
+
+	_ = ExportCustomCmd.MarkFlagRequired("folder")
+}
@@ -0,0 +1,55 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package connectors
+
+import (
+	"internal/apiclient"
+
+	"internal/client/connections"
+
+	"github.com/spf13/cobra"
+)
+
+// FindCmd to search connections by config variable or destination values
+var FindCmd = &cobra.Command{
+	Use:   "find",
+	Short: "Find connections by config variable or destination value",
+	Long: "List the connections whose config variables or destinations match the key " +
+		"and contain the value. If the key is not set, all config variables and destinations are searched",
+	Args: func(cmd *cobra.Command, args []string) (err error) {
+		cmdProject := cmd.Flag("proj")
+		cmdRegion := cmd.Flag("reg")
+
+		if err = apiclient.SetRegion(cmdRegion.Value.String()); err != nil {
+			return err
+		}
+		return apiclient.SetProjectID(cmdProject.Value.String())
+	},
+	RunE: func(cmd *cobra.Command, args []string) (err error) {
+		_, err = connections.Find(cmd.Flag("key").Value.String(), cmd.Flag("value").Value.String())
+		return err
+	},
+}
+
+func init() {
+	var key, value string
+
+	FindCmd.Flags().StringVarP(&key, "key", "",
+		"", "Config variable or destination key, for ex: topic_id, host or serviceAttachment")
+	FindCmd.Flags().StringVarP(&value, "value", "",
+		"", "Value or part of the value to search for")
+
+	_ = FindCmd.MarkFlagRequired("value")
+}
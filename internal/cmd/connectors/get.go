@@ -15,7 +15,6 @@
 package connectors
 
 import (
-	"fmt"
 	"strconv"
 
 	"internal/apiclient"
@@ -29,7 +28,10 @@ import (
 var GetCmd = &cobra.Command{
 	Use:   "get",
 	Short: "Get connection details",
-	Long:  "Get connection details from a connection created in a region",
+	Long: "Get connection details from a connection created in a region. By default, and with " +
+		"--raw, the unmodified API response is printed. --minimal rewrites the response into a " +
+		"connection file that can be used with create, and --overrides also replaces secrets " +
+		"and project ids with placeholders for use in other projects",
 	Args: func(cmd *cobra.Command, args []string) (err error) {
 		cmdProject := cmd.Flag("proj")
 		cmdRegion := cmd.Flag("reg")
@@ -37,37 +39,64 @@ var GetCmd = &cobra.Command{
 		if err = apiclient.SetRegion(cmdRegion.Value.String()); err != nil {
 			return err
 		}
-		if view != "BASIC" && view != "FULL" {
-			return fmt.Errorf("view must be BASIC or FULL")
+		if err = connections.ValidateView(view); err != nil {
+			return err
 		}
 		return apiclient.SetProjectID(cmdProject.Value.String())
 	},
 	RunE: func(cmd *cobra.Command, args []string) (err error) {
 		name := cmd.Flag("name").Value.String()
+		launchStage, _ := strconv.ParseBool(cmd.Flag("launch-stage").Value.String())
 		minimal, _ := strconv.ParseBool(cmd.Flag("minimal").Value.String())
 		overrides, _ := strconv.ParseBool(cmd.Flag("overrides").Value.String())
+		raw, _ := strconv.ParseBool(cmd.Flag("raw").Value.String())
 		if overrides {
 			minimal = true
 		}
-		_, err = connections.Get(name, view, minimal, overrides)
+		if raw {
+			minimal, overrides = false, false
+		}
+		if len(selectFields) > 0 {
+			apiclient.DisableCmdPrintHttpResponse()
+			respBody, err := connections.Get(name, view, minimal, overrides, launchStage)
+			apiclient.EnableCmdPrintHttpResponse()
+			if err != nil {
+				return err
+			}
+			return connections.PrintFields(respBody, selectFields)
+		}
+		_, err = connections.Get(name, view, minimal, overrides, launchStage)
 		return err
 	},
 }
 
-var view string
+var (
+	view         string
+	selectFields []string
+)
 
 func init() {
 	var name string
-	minimal, overrides := false, false
+	minimal, overrides, raw, launchStage := false, false, false, false
 
 	GetCmd.Flags().StringVarP(&name, "name", "n",
 		"", "The name of the connection")
 	GetCmd.Flags().StringVarP(&view, "view", "",
-		"BASIC", "fields of the Connection to be returned; default is BASIC. FULL is the other option")
+		string(connections.BasicView), "fields of the Connection to be returned; BASIC omits the runtime configs "+
+			"and FULL includes them. The value is case sensitive; default is BASIC")
 	GetCmd.Flags().BoolVarP(&minimal, "minimal", "",
-		false, "fields of the Connection to be returned; default is false")
+		false, "Rewrite the response into a connection file for use with create; default is false")
 	GetCmd.Flags().BoolVarP(&overrides, "overrides", "",
-		false, "fetch connector details for use with scaffold")
+		false, "Like minimal, and replace secrets and project ids with placeholders for use with scaffold")
+	GetCmd.Flags().BoolVarP(&raw, "raw", "",
+		false, "Print the unmodified API response; default is true unless minimal or overrides are set")
+	GetCmd.Flags().BoolVarP(&launchStage, "launch-stage", "",
+		false, "With minimal, add the launch stage of the connector version to connectorDetails; "+
+			"costs one more API call")
+	GetCmd.Flags().StringSliceVarP(&selectFields, "select-fields", "",
+		nil, "Output only the comma separated fields as JSON lines; supports dot paths like authConfig.authType")
 
+	GetCmd.MarkFlagsMutuallyExclusive("raw", "minimal")
+	GetCmd.MarkFlagsMutuallyExclusive("raw", "overrides")
 	_ = GetCmd.MarkFlagRequired("name")
 }
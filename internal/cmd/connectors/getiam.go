@@ -15,6 +15,8 @@
 package connectors
 
 import (
+	"strconv"
+
 	"internal/apiclient"
 
 	"internal/client/connections"
@@ -38,11 +40,32 @@ var GetIamCmd = &cobra.Command{
 	},
 	RunE: func(cmd *cobra.Command, args []string) (err error) {
 		name := cmd.Flag("name").Value.String()
-		_, err = connections.GetIAM(name)
-		return
+		member := cmd.Flag("member").Value.String()
+		asJSON, _ := strconv.ParseBool(cmd.Flag("json").Value.String())
+
+		if asJSON && member == "" {
+			_, err = connections.GetIAM(name)
+			return
+		}
+
+		apiclient.DisableCmdPrintHttpResponse()
+		respBody, err := connections.GetIAM(name)
+		apiclient.EnableCmdPrintHttpResponse()
+		if err != nil {
+			return err
+		}
+		return connections.PrintIAM(respBody, member, asJSON)
 	},
 }
 
 func init() {
+	var member string
+	var asJSON bool
+
+	GetIamCmd.Flags().StringVarP(&member, "member", "m",
+		"", "Show only the roles granted to the member, for ex: user:foo@bar.com")
+	GetIamCmd.Flags().BoolVarP(&asJSON, "json", "",
+		false, "Print the policy as JSON instead of a table")
+
 	_ = GetCmd.MarkFlagRequired("name")
 }
@@ -0,0 +1,52 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package connectors
+
+import (
+	"internal/apiclient"
+
+	"internal/client/connections"
+
+	"github.com/spf13/cobra"
+)
+
+// GrantSecretAccessCmd to grant the connection service account access to its secrets
+var GrantSecretAccessCmd = &cobra.Command{
+	Use:   "grant-secret-access",
+	Short: "Grant a connection's service account access to its secrets",
+	Long: "Grant the service account of an existing connection the Secret Manager accessor and viewer " +
+		"roles on every secret referenced by the connection, without changing the connection",
+	Args: func(cmd *cobra.Command, args []string) (err error) {
+		cmdProject := cmd.Flag("proj")
+		cmdRegion := cmd.Flag("reg")
+
+		if err = apiclient.SetRegion(cmdRegion.Value.String()); err != nil {
+			return err
+		}
+		return apiclient.SetProjectID(cmdProject.Value.String())
+	},
+	RunE: func(cmd *cobra.Command, args []string) (err error) {
+		return connections.GrantSecretAccess(cmd.Flag("name").Value.String())
+	},
+}
+
+func init() {
+	var name string
+
+	GrantSecretAccessCmd.Flags().StringVarP(&name, "name", "n",
+		"", "Connection name")
+
+	_ = GrantSecretAccessCmd.MarkFlagRequired("name")
+}
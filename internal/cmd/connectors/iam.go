@@ -35,4 +35,5 @@ func init() {
 
 	IamCmd.AddCommand(GetIamCmd)
 	IamCmd.AddCommand(SetRoleCmd)
+	IamCmd.AddCommand(SetPolicyCmd)
 }
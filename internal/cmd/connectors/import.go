@@ -15,6 +15,8 @@
 package connectors
 
 import (
+	"fmt"
+	"regexp"
 	"strconv"
 
 	"internal/apiclient"
@@ -28,7 +30,17 @@ import (
 var ImportCmd = &cobra.Command{
 	Use:   "import",
 	Short: "Import connections to a region from a folder",
-	Long:  "Import connections to a region from a folder",
+	Long: "Import connections to a region from a folder. The connection name is taken from " +
+		"the connectionName field in the file if set, otherwise from the file name. " +
+		"If the folder contains a _defaults.json file, its fields are applied to every connection; " +
+		"nested objects are merged, while scalars and arrays in the connection file replace the default. " +
+		"Use --values to set config variables per environment from a JSON file keyed by " +
+		"connectionName.configVarKey; keys that match no imported config variable are reported as warnings. " +
+		"Before any connection is created, the service accounts of the connections, or the default compute " +
+		"service account for connections without one, are checked to exist and allow the caller " +
+		"iam.serviceAccounts.actAs. " +
+		"Use --prune to make the folder the source of truth: live connections that no file imports are " +
+		"deleted after confirmation, or with --force; --prune-dry-run lists them",
 	Args: func(cmd *cobra.Command, args []string) (err error) {
 		cmdProject := cmd.Flag("proj")
 		cmdRegion := cmd.Flag("reg")
@@ -39,19 +51,54 @@ var ImportCmd = &cobra.Command{
 		return apiclient.SetProjectID(cmdProject.Value.String())
 	},
 	RunE: func(cmd *cobra.Command, args []string) (err error) {
+		if err = setWaitTiming(cmd); err != nil {
+			return err
+		}
+		allowPreview, _ := strconv.ParseBool(cmd.Flag("allow-preview").Value.String())
 		createSecret, _ := strconv.ParseBool(cmd.Flag("create-secret").Value.String())
 		wait, _ := strconv.ParseBool(cmd.Flag("wait").Value.String())
+		sanitizeNames, _ := strconv.ParseBool(cmd.Flag("sanitize-names").Value.String())
+		patchOnly, _ := strconv.ParseBool(cmd.Flag("patch-only").Value.String())
+		updateSecrets, _ := strconv.ParseBool(cmd.Flag("update-secrets").Value.String())
+		encryptionKey := cmd.Flag("encryption-keyid").Value.String()
+		prune, _ := strconv.ParseBool(cmd.Flag("prune").Value.String())
+		force, _ := strconv.ParseBool(cmd.Flag("force").Value.String())
+		pruneDryRun, _ := strconv.ParseBool(cmd.Flag("prune-dry-run").Value.String())
+
+		if (force || pruneDryRun) && !prune {
+			return fmt.Errorf("force and prune-dry-run can only be used with prune")
+		}
+
+		if encryptionKey != "" {
+			re := regexp.MustCompile(`locations\/([a-zA-Z0-9_-]+)\/keyRings\/([a-zA-Z0-9_-]+)\/cryptoKeys\/([a-zA-Z0-9_-]+)`)
+			ok := re.Match([]byte(encryptionKey))
+			if !ok {
+				return fmt.Errorf("encryption key must be of the format " +
+					"locations/{location}/keyRings/{test}/cryptoKeys/{cryptoKey}")
+			}
+		}
 
 		if err = apiclient.FolderExists(folder); err != nil {
 			return err
 		}
 
-		return connections.Import(folder, createSecret, wait)
+		if patchOnly {
+			return connections.PatchFolder(folder, wait)
+		}
+
+		return connections.Import(folder, createSecret, wait, sanitizeNames, updateSecrets,
+			cmd.Flag("values").Value.String(), encryptionKey, prune, force, pruneDryRun,
+			connections.CreateOptions{
+				AllowPreview: allowPreview,
+				LocalKeyFile: cmd.Flag("local-key-file").Value.String(),
+			})
 	},
 }
 
 func init() {
-	createSecret, wait := false, false
+	createSecret, wait, sanitizeNames, patchOnly, updateSecrets := false, false, false, false, false
+	prune, force, pruneDryRun, allowPreview := false, false, false, false
+	var valuesFile, encryptionKey, localKeyFile string
 
 	ImportCmd.Flags().StringVarP(&folder, "folder", "f",
 		"", "Folder to import connections")
@@ -59,6 +106,32 @@ func init() {
 		false, "Create Secret Manager secrets when creating the connection")
 	ImportCmd.Flags().BoolVarP(&wait, "wait", "",
 		false, "Waits for the connector to finish, with success or error")
+	ImportCmd.Flags().BoolVarP(&sanitizeNames, "sanitize-names", "",
+		false, "Convert file names into valid connection names instead of failing")
+	ImportCmd.Flags().BoolVarP(&updateSecrets, "update-secrets", "",
+		false, "Add a secret version to existing secrets when the payload changed; by default the latest version is used")
+	ImportCmd.Flags().BoolVarP(&patchOnly, "patch-only", "",
+		false, "Patch existing connections with the partial connections in the folder; "+
+			"the update mask is read from an updateMask array in the file or a sidecar .mask file")
+	ImportCmd.Flags().StringVarP(&valuesFile, "values", "",
+		"", "JSON file of values keyed by connectionName.configVarKey to set on the imported connections")
+	ImportCmd.Flags().StringVarP(&encryptionKey, "encryption-keyid", "k",
+		"", "Cloud KMS key for decrypting secret files, like the ones written by export "+
+			"--include-secret-values; Format = [projects/*/]locations/*/keyRings/*/cryptoKeys/*")
+	ImportCmd.Flags().StringVarP(&localKeyFile, "local-key-file", "",
+		"", "File with a base64 encoded 16, 24 or 32 byte AES key for decrypting secret files instead of Cloud KMS")
+	ImportCmd.Flags().BoolVarP(&prune, "prune", "",
+		false, "Delete the connections that no file in the folder imports, after every file is imported")
+	ImportCmd.Flags().BoolVarP(&force, "force", "",
+		false, "Prune without asking for confirmation")
+	ImportCmd.Flags().BoolVarP(&pruneDryRun, "prune-dry-run", "",
+		false, "List the connections --prune would delete, without importing or deleting anything")
+	ImportCmd.Flags().BoolVarP(&allowPreview, "allow-preview", "",
+		false, "Allow creating connections on connector versions that are not GA; default is false")
+	addWaitFlags(ImportCmd)
+
+	ImportCmd.MarkFlagsMutuallyExclusive("encryption-keyid", "local-key-file")
+	ImportCmd.MarkFlagsMutuallyExclusive("prune", "patch-only")
 
 	_ = ImportCmd.MarkFlagRequired("folder")
 }
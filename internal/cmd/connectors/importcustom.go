@@ -0,0 +1,56 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package connectors
+
+import (
+	"internal/apiclient"
+
+	"internal/client/connections"
+
+	"github.com/spf13/cobra"
+)
+
+// ImportCustomCmd to import custom connectors
+var ImportCustomCmd = &cobra.Command{
+	Use:   "import",
+	Short: "Import custom connectors from a folder",
+	Long: "Create the custom connectors exported to a folder and the versions they are missing. " +
+		"Existing versions are not changed",
+	Args: func(cmd *cobra.Command, args []string) (err error) {
+		cmdProject := cmd.Flag("proj")
+		cmdRegion := cmd.Flag("reg")
+
+		if err = apiclient.SetRegion(cmdRegion.Value.String()); err != nil {
+			return err
+		}
+		return apiclient.SetProjectID(cmdProject.Value.String())
+	},
+	RunE: func(cmd *cobra.Command, args []string) (err error) {
+		customFolder := cmd.Flag("folder").Value.String()
+		if err = apiclient.FolderExists(customFolder); err != nil {
+			return err
+		}
+		return connections.ImportCustomConnectors(customFolder)
+	},
+}
+
+func init() {
+	var customFolder string
+
+	ImportCustomCmd.Flags().StringVarP(&customFolder, "folder", "f",
+		"", "Folder with the exported custom connectors")
+
+	_ = ImportCustomCmd.MarkFlagRequired("folder")
+}
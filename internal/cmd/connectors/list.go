@@ -15,6 +15,9 @@
 package connectors
 
 import (
+	"fmt"
+	"strconv"
+
 	"internal/apiclient"
 
 	"internal/client/connections"
@@ -26,7 +29,8 @@ import (
 var ListCmd = &cobra.Command{
 	Use:   "list",
 	Short: "List all connections in the region",
-	Long:  "List all connections in the region",
+	Long: "List all connections in the region. Set the region to all, or to a comma " +
+		"separated list of regions, to list the connections across regions",
 	Args: func(cmd *cobra.Command, args []string) (err error) {
 		cmdProject := cmd.Flag("proj")
 		cmdRegion := cmd.Flag("reg")
@@ -34,21 +38,77 @@ var ListCmd = &cobra.Command{
 		if err = apiclient.SetRegion(cmdRegion.Value.String()); err != nil {
 			return err
 		}
+		if len(projects) > 0 && connections.IsMultiRegion(cmdRegion.Value.String()) {
+			return fmt.Errorf("projects lists a single region and cannot be used with region %s",
+				cmdRegion.Value.String())
+		}
+		if err = connections.ValidateOrderBy(cmd.Flag("orderBy").Value.String()); err != nil {
+			return err
+		}
+		if err = connections.ValidateState(cmd.Flag("state").Value.String()); err != nil {
+			return err
+		}
 		return apiclient.SetProjectID(cmdProject.Value.String())
 	},
 	RunE: func(cmd *cobra.Command, args []string) (err error) {
-		_, err = connections.List(pageSize,
-			cmd.Flag("pageToken").Value.String(),
-			cmd.Flag("filter").Value.String(),
-			cmd.Flag("orderBy").Value.String())
+		if countOnly, _ := strconv.ParseBool(cmd.Flag("count-only").Value.String()); countOnly {
+			regions := []string{apiclient.GetRegion()}
+			if region := cmd.Flag("reg").Value.String(); connections.IsMultiRegion(region) {
+				if regions, err = connections.GetRegions(region); err != nil {
+					return err
+				}
+			}
+			_, err = connections.Count(regions, cmd.Flag("filter").Value.String())
+			return err
+		}
+		if len(selectFields) > 0 {
+			apiclient.DisableCmdPrintHttpResponse()
+		}
+		if len(projects) > 0 {
+			return connections.ListAcrossProjects(projects,
+				cmd.Flag("filter").Value.String(),
+				cmd.Flag("orderBy").Value.String())
+		}
+
+		var respBody []byte
+		if region := cmd.Flag("reg").Value.String(); connections.IsMultiRegion(region) {
+			var regions []string
+			if regions, err = connections.GetRegions(region); err != nil {
+				return err
+			}
+			respBody, err = connections.ListRegions(regions,
+				cmd.Flag("filter").Value.String(),
+				cmd.Flag("orderBy").Value.String(),
+				cmd.Flag("state").Value.String())
+		} else if state := cmd.Flag("state").Value.String(); state != "" {
+			respBody, err = connections.ListByState(state,
+				cmd.Flag("filter").Value.String(),
+				cmd.Flag("orderBy").Value.String())
+		} else {
+			respBody, err = connections.List(pageSize,
+				cmd.Flag("pageToken").Value.String(),
+				cmd.Flag("filter").Value.String(),
+				cmd.Flag("orderBy").Value.String())
+		}
+		if len(selectFields) > 0 {
+			apiclient.EnableCmdPrintHttpResponse()
+			if err != nil {
+				return err
+			}
+			return connections.PrintFields(respBody, selectFields)
+		}
 		return err
 	},
 }
 
-var pageSize int
+var (
+	pageSize int
+	projects []string
+)
 
 func init() {
-	var pageToken, filter, orderBy string
+	var pageToken, filter, orderBy, state string
+	var countOnly bool
 
 	ListCmd.Flags().IntVarP(&pageSize, "pageSize", "",
 		-1, "The maximum number of versions to return")
@@ -57,5 +117,24 @@ func init() {
 	ListCmd.Flags().StringVarP(&filter, "filter", "",
 		"", "Filter results")
 	ListCmd.Flags().StringVarP(&orderBy, "orderBy", "",
-		"", "The results would be returned in order")
+		"", "Order the results by name, createTime or updateTime, optionally followed by desc; "+
+			"the order is kept across pages and regions")
+	ListCmd.Flags().StringSliceVarP(&selectFields, "select-fields", "",
+		nil, "Output only the comma separated fields of each connection as JSON lines")
+	ListCmd.Flags().StringSliceVarP(&projects, "projects", "",
+		nil, "List the connections of the comma separated projects in the region as a single table")
+	ListCmd.Flags().BoolVarP(&countOnly, "count-only", "",
+		false, "Print only the number of connections that match the filter")
+	ListCmd.Flags().StringVarP(&state, "state", "",
+		"", "List every connection in this state, like ERROR; the state is filtered "+
+			"after all the pages are fetched, since the filter can't match it")
+
+	ListCmd.MarkFlagsMutuallyExclusive("count-only", "select-fields")
+	ListCmd.MarkFlagsMutuallyExclusive("count-only", "projects")
+	ListCmd.MarkFlagsMutuallyExclusive("state", "count-only")
+	ListCmd.MarkFlagsMutuallyExclusive("state", "projects")
+	ListCmd.MarkFlagsMutuallyExclusive("select-fields", "projects")
+	ListCmd.MarkFlagsMutuallyExclusive("pageToken", "projects")
+	ListCmd.MarkFlagsMutuallyExclusive("pageSize", "projects")
+	ListCmd.MarkFlagsMutuallyExclusive("state", "pageToken")
 }
@@ -16,6 +16,7 @@ package connectors
 
 import (
 	"os"
+	"strconv"
 
 	"internal/apiclient"
 
@@ -39,7 +40,11 @@ var PatchCmd = &cobra.Command{
 		return apiclient.SetProjectID(cmdProject.Value.String())
 	},
 	RunE: func(cmd *cobra.Command, args []string) (err error) {
+		if err = setWaitTiming(cmd); err != nil {
+			return err
+		}
 		name := cmd.Flag("name").Value.String()
+		wait, _ := strconv.ParseBool(cmd.Flag("wait").Value.String())
 
 		if _, err := os.Stat(connectionFile); os.IsNotExist(err) {
 			return err
@@ -58,7 +63,12 @@ var PatchCmd = &cobra.Command{
 			}
 		}
 
-		_, err = connections.Patch(name, content, updateMask)
+		strict, _ := strconv.ParseBool(cmd.Flag("strict").Value.String())
+		if err = connections.CheckImmutableConfigVars(name, content, updateMask, strict); err != nil {
+			return err
+		}
+
+		_, err = connections.Patch(name, content, updateMask, wait)
 		return err
 	},
 }
@@ -67,6 +77,7 @@ var updateMask []string
 
 func init() {
 	var name string
+	var wait, strict bool
 
 	PatchCmd.Flags().StringVarP(&name, "name", "n",
 		"", "Connection name")
@@ -74,6 +85,11 @@ func init() {
 		"", "Connection details JSON file path")
 	PatchCmd.Flags().StringArrayVarP(&updateMask, "update-mask", "",
 		nil, "Update mask: A list of comma separated values to update")
+	PatchCmd.Flags().BoolVarP(&wait, "wait", "",
+		false, "Waits for the update to finish, with success or error; default is false")
+	PatchCmd.Flags().BoolVarP(&strict, "strict", "",
+		false, "Fail instead of warning when the update changes immutable config variables")
+	addWaitFlags(PatchCmd)
 
 	_ = PatchCmd.MarkFlagRequired("updateMask")
 }
@@ -0,0 +1,73 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package connectors
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"internal/apiclient"
+
+	"internal/client/connections"
+
+	"github.com/spf13/cobra"
+)
+
+// PatchConfigVarsCmd to merge config variables into a connection
+var PatchConfigVarsCmd = &cobra.Command{
+	Use:   "update-config-vars",
+	Short: "Update config variables of an existing connection",
+	Long: "Merge config variables into an existing connection. Only the keys in the file " +
+		"are changed, other config variables on the connection are preserved",
+	Args: func(cmd *cobra.Command, args []string) (err error) {
+		cmdProject := cmd.Flag("proj")
+		cmdRegion := cmd.Flag("reg")
+
+		if err = apiclient.SetRegion(cmdRegion.Value.String()); err != nil {
+			return err
+		}
+		return apiclient.SetProjectID(cmdProject.Value.String())
+	},
+	RunE: func(cmd *cobra.Command, args []string) (err error) {
+		name := cmd.Flag("name").Value.String()
+		varsFile := cmd.Flag("file").Value.String()
+
+		content, err := os.ReadFile(varsFile)
+		if err != nil {
+			return fmt.Errorf("unable to open file %w", err)
+		}
+
+		vars := map[string]interface{}{}
+		if err = json.Unmarshal(content, &vars); err != nil {
+			return fmt.Errorf("config variables file must be a JSON object of key/value pairs: %w", err)
+		}
+
+		_, err = connections.PatchConfigVars(name, vars)
+		return err
+	},
+}
+
+func init() {
+	var name, varsFile string
+
+	PatchConfigVarsCmd.Flags().StringVarP(&name, "name", "n",
+		"", "Connection name")
+	PatchConfigVarsCmd.Flags().StringVarP(&varsFile, "file", "f",
+		"", "JSON file with config variables as key/value pairs")
+
+	_ = PatchConfigVarsCmd.MarkFlagRequired("name")
+	_ = PatchConfigVarsCmd.MarkFlagRequired("file")
+}
@@ -0,0 +1,67 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package connectors
+
+import (
+	"fmt"
+	"os"
+
+	"internal/apiclient"
+
+	"internal/client/connections"
+
+	"github.com/spf13/cobra"
+)
+
+// PatchDestinationsCmd to merge destination configs into a connection
+var PatchDestinationsCmd = &cobra.Command{
+	Use:   "update-destinations",
+	Short: "Update destination configs of an existing connection",
+	Long: "Merge destination configs into an existing connection. Destination configs are " +
+		"matched by key, other destination configs on the connection are preserved",
+	Args: func(cmd *cobra.Command, args []string) (err error) {
+		cmdProject := cmd.Flag("proj")
+		cmdRegion := cmd.Flag("reg")
+
+		if err = apiclient.SetRegion(cmdRegion.Value.String()); err != nil {
+			return err
+		}
+		return apiclient.SetProjectID(cmdProject.Value.String())
+	},
+	RunE: func(cmd *cobra.Command, args []string) (err error) {
+		name := cmd.Flag("name").Value.String()
+		destinationsFile := cmd.Flag("file").Value.String()
+
+		content, err := os.ReadFile(destinationsFile)
+		if err != nil {
+			return fmt.Errorf("unable to open file %w", err)
+		}
+
+		_, err = connections.PatchDestinations(name, content)
+		return err
+	},
+}
+
+func init() {
+	var name, destinationsFile string
+
+	PatchDestinationsCmd.Flags().StringVarP(&name, "name", "n",
+		"", "Connection name")
+	PatchDestinationsCmd.Flags().StringVarP(&destinationsFile, "file", "f",
+		"", "JSON file with an array of destination configs")
+
+	_ = PatchDestinationsCmd.MarkFlagRequired("name")
+	_ = PatchDestinationsCmd.MarkFlagRequired("file")
+}
@@ -0,0 +1,53 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package connectors
+
+import (
+	"internal/apiclient"
+
+	"internal/client/connections"
+
+	"github.com/spf13/cobra"
+)
+
+// RefreshTokenCmd to refresh the access token of a connection
+var RefreshTokenCmd = &cobra.Command{
+	Use:   "refresh-token",
+	Short: "Refresh the access token of an OAUTH2_AUTH_CODE_FLOW connection",
+	Long: "Refresh the access token of an OAUTH2_AUTH_CODE_FLOW connection with its refresh token " +
+		"and report when it expires. If the refresh token expired too, authorize the connection again",
+	Args: func(cmd *cobra.Command, args []string) (err error) {
+		cmdProject := cmd.Flag("proj")
+		cmdRegion := cmd.Flag("reg")
+
+		if err = apiclient.SetRegion(cmdRegion.Value.String()); err != nil {
+			return err
+		}
+		return apiclient.SetProjectID(cmdProject.Value.String())
+	},
+	RunE: func(cmd *cobra.Command, args []string) (err error) {
+		_, err = connections.RefreshToken(cmd.Flag("name").Value.String())
+		return err
+	},
+}
+
+func init() {
+	var name string
+
+	RefreshTokenCmd.Flags().StringVarP(&name, "name", "n",
+		"", "The name of the connection")
+
+	_ = RefreshTokenCmd.MarkFlagRequired("name")
+}
@@ -0,0 +1,55 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package connectors
+
+import (
+	"internal/apiclient"
+
+	"internal/client/connections"
+
+	"github.com/spf13/cobra"
+)
+
+// RemoveLabelCmd to remove a label from a connection
+var RemoveLabelCmd = &cobra.Command{
+	Use:   "remove-label",
+	Short: "Remove a label from an existing connection",
+	Long:  "Remove a label from an existing connection. Other labels on the connection are preserved",
+	Args: func(cmd *cobra.Command, args []string) (err error) {
+		cmdProject := cmd.Flag("proj")
+		cmdRegion := cmd.Flag("reg")
+
+		if err = apiclient.SetRegion(cmdRegion.Value.String()); err != nil {
+			return err
+		}
+		return apiclient.SetProjectID(cmdProject.Value.String())
+	},
+	RunE: func(cmd *cobra.Command, args []string) (err error) {
+		_, err = connections.RemoveLabel(cmd.Flag("name").Value.String(), cmd.Flag("key").Value.String())
+		return err
+	},
+}
+
+func init() {
+	var name, key string
+
+	RemoveLabelCmd.Flags().StringVarP(&name, "name", "n",
+		"", "Connection name")
+	RemoveLabelCmd.Flags().StringVarP(&key, "key", "k",
+		"", "Label key")
+
+	_ = RemoveLabelCmd.MarkFlagRequired("name")
+	_ = RemoveLabelCmd.MarkFlagRequired("key")
+}
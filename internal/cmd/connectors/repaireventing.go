@@ -0,0 +1,63 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package connectors
+
+import (
+	"strconv"
+
+	"internal/apiclient"
+
+	"internal/client/connections"
+
+	"github.com/spf13/cobra"
+)
+
+// RepairEventingCmd to repair the eventing of a connection
+var RepairEventingCmd = &cobra.Command{
+	Use:   "repair-eventing",
+	Short: "Repair the eventing of a connection",
+	Long: "Repair the eventing of an event enabled connection, for ex: when events stopped " +
+		"arriving because the registration with the backend broke",
+	Args: func(cmd *cobra.Command, args []string) (err error) {
+		cmdProject := cmd.Flag("proj")
+		cmdRegion := cmd.Flag("reg")
+
+		if err = apiclient.SetRegion(cmdRegion.Value.String()); err != nil {
+			return err
+		}
+		return apiclient.SetProjectID(cmdProject.Value.String())
+	},
+	RunE: func(cmd *cobra.Command, args []string) (err error) {
+		if err = setWaitTiming(cmd); err != nil {
+			return err
+		}
+		wait, _ := strconv.ParseBool(cmd.Flag("wait").Value.String())
+		_, err = connections.RepairEventing(cmd.Flag("name").Value.String(), wait)
+		return err
+	},
+}
+
+func init() {
+	var name string
+	var wait bool
+
+	RepairEventingCmd.Flags().StringVarP(&name, "name", "n",
+		"", "The name of the connection")
+	RepairEventingCmd.Flags().BoolVarP(&wait, "wait", "",
+		false, "Waits for the repair to finish, with success or error; default is false")
+	addWaitFlags(RepairEventingCmd)
+
+	_ = RepairEventingCmd.MarkFlagRequired("name")
+}
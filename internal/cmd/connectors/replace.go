@@ -0,0 +1,111 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package connectors
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+
+	"internal/apiclient"
+
+	"internal/client/connections"
+
+	"github.com/spf13/cobra"
+)
+
+// ReplaceCmd to delete and create a connection again
+var ReplaceCmd = &cobra.Command{
+	Use:   "replace",
+	Short: "Delete and create a connection again",
+	Long: "Delete a connection, wait for the delete to complete and create it again from a file. " +
+		"Use it for changes to fields that can't be patched. This is destructive: the connection " +
+		"is unavailable until the create completes. Use --dry-run to list the fields that change",
+	Args: func(cmd *cobra.Command, args []string) (err error) {
+		cmdProject := cmd.Flag("proj")
+		cmdRegion := cmd.Flag("reg")
+
+		if err = apiclient.SetRegion(cmdRegion.Value.String()); err != nil {
+			return err
+		}
+		return apiclient.SetProjectID(cmdProject.Value.String())
+	},
+	RunE: func(cmd *cobra.Command, args []string) (err error) {
+		if err = setWaitTiming(cmd); err != nil {
+			return err
+		}
+		allowPreview, _ := strconv.ParseBool(cmd.Flag("allow-preview").Value.String())
+		wait, _ := strconv.ParseBool(cmd.Flag("wait").Value.String())
+		dryRun, _ := strconv.ParseBool(cmd.Flag("dry-run").Value.String())
+
+		content, err := os.ReadFile(cmd.Flag("file").Value.String())
+		if err != nil {
+			return fmt.Errorf("unable to open file %w", err)
+		}
+
+		createSecret, _ := strconv.ParseBool(cmd.Flag("create-secret").Value.String())
+		grantPermission, _ := strconv.ParseBool(cmd.Flag("grant-permission").Value.String())
+		encryptionKey := cmd.Flag("encryption-keyid").Value.String()
+		if encryptionKey != "" {
+			re := regexp.MustCompile(`locations\/([a-zA-Z0-9_-]+)\/keyRings\/([a-zA-Z0-9_-]+)\/cryptoKeys\/([a-zA-Z0-9_-]+)`)
+			if !re.Match([]byte(encryptionKey)) {
+				return fmt.Errorf("encryption key must be of the format " +
+					"locations/{location}/keyRings/{test}/cryptoKeys/{cryptoKey}")
+			}
+		}
+
+		_, err = connections.Replace(cmd.Flag("name").Value.String(), content,
+			cmd.Flag("sa").Value.String(), cmd.Flag("sp").Value.String(), encryptionKey,
+			grantPermission, createSecret, wait, dryRun, connections.CreateOptions{
+				AllowPreview: allowPreview,
+				LocalKeyFile: cmd.Flag("local-key-file").Value.String(),
+			})
+		return err
+	},
+}
+
+func init() {
+	var name, file, sa, sp, encryptionKey, localKeyFile string
+	wait, dryRun, createSecret, grantPermission, allowPreview := false, false, false, false, false
+
+	ReplaceCmd.Flags().StringVarP(&name, "name", "n",
+		"", "Connection name")
+	ReplaceCmd.Flags().StringVarP(&file, "file", "f",
+		"", "Connection details JSON file path")
+	ReplaceCmd.Flags().BoolVarP(&wait, "wait", "",
+		false, "Waits for the create to finish, with success or error; default is false")
+	ReplaceCmd.Flags().BoolVarP(&dryRun, "dry-run", "",
+		false, "Print the fields that would change without deleting the connection; default is false")
+	ReplaceCmd.Flags().BoolVarP(&grantPermission, "grant-permission", "g",
+		false, "Grant the service account permission to the GCP resource; default is false")
+	ReplaceCmd.Flags().StringVarP(&sa, "sa", "",
+		"", "Service Account name for the connection; do not include @<project-id>.iam.gserviceaccount.com")
+	ReplaceCmd.Flags().StringVarP(&sp, "sp", "",
+		"", "Service Account Project for the connection. Default is the connection's project id")
+	ReplaceCmd.Flags().BoolVarP(&createSecret, "create-secret", "",
+		false, "Create Secret Manager secrets when creating the connection; default is false")
+	ReplaceCmd.Flags().StringVarP(&encryptionKey, "encryption-keyid", "k",
+		"", "Cloud KMS key for decrypting Auth Config; Format = locations/*/keyRings/*/cryptoKeys/*")
+	ReplaceCmd.Flags().StringVarP(&localKeyFile, "local-key-file", "",
+		"", "File with a base64 encoded 16, 24 or 32 byte AES key for decrypting secret files instead of Cloud KMS")
+	ReplaceCmd.Flags().BoolVarP(&allowPreview, "allow-preview", "",
+		false, "Allow creating connections on connector versions that are not GA; default is false")
+	addWaitFlags(ReplaceCmd)
+
+	ReplaceCmd.MarkFlagsMutuallyExclusive("encryption-keyid", "local-key-file")
+	_ = ReplaceCmd.MarkFlagRequired("name")
+	_ = ReplaceCmd.MarkFlagRequired("file")
+}
@@ -0,0 +1,78 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package connectors
+
+import (
+	"fmt"
+	"regexp"
+
+	"internal/apiclient"
+
+	"internal/client/connections"
+
+	"github.com/spf13/cobra"
+)
+
+// RotateSecretCmd to add a new secret version and repoint the connection
+var RotateSecretCmd = &cobra.Command{
+	Use:   "rotate-secret",
+	Short: "Rotate the secret used by a connection's auth config",
+	Long: "Add a new Secret Manager version to the secret referenced by the connection's " +
+		"auth config and update the connection to use it",
+	Args: func(cmd *cobra.Command, args []string) (err error) {
+		cmdProject := cmd.Flag("proj")
+		cmdRegion := cmd.Flag("reg")
+
+		if err = apiclient.SetRegion(cmdRegion.Value.String()); err != nil {
+			return err
+		}
+		return apiclient.SetProjectID(cmdProject.Value.String())
+	},
+	RunE: func(cmd *cobra.Command, args []string) (err error) {
+		name := cmd.Flag("name").Value.String()
+		secretFile := cmd.Flag("secret-file").Value.String()
+		encryptionKey := cmd.Flag("encryption-keyid").Value.String()
+
+		if encryptionKey != "" {
+			re := regexp.MustCompile(`locations\/([a-zA-Z0-9_-]+)\/keyRings\/([a-zA-Z0-9_-]+)\/cryptoKeys\/([a-zA-Z0-9_-]+)`)
+			ok := re.Match([]byte(encryptionKey))
+			if !ok {
+				return fmt.Errorf("encryption key must be of the format " +
+					"locations/{location}/keyRings/{test}/cryptoKeys/{cryptoKey}")
+			}
+		}
+
+		_, err = connections.RotateSecret(name, secretFile, encryptionKey,
+			cmd.Flag("local-key-file").Value.String())
+		return err
+	},
+}
+
+func init() {
+	var name, secretFile, encryptionKey, localKeyFile string
+
+	RotateSecretCmd.Flags().StringVarP(&name, "name", "n",
+		"", "Connection name")
+	RotateSecretCmd.Flags().StringVarP(&secretFile, "secret-file", "s",
+		"", "File containing the new secret payload")
+	RotateSecretCmd.Flags().StringVarP(&encryptionKey, "encryption-keyid", "k",
+		"", "Cloud KMS key for decrypting the secret file; Format = locations/*/keyRings/*/cryptoKeys/*")
+	RotateSecretCmd.Flags().StringVarP(&localKeyFile, "local-key-file", "",
+		"", "File with a base64 encoded 16, 24 or 32 byte AES key for decrypting secret files instead of Cloud KMS")
+
+	RotateSecretCmd.MarkFlagsMutuallyExclusive("encryption-keyid", "local-key-file")
+	_ = RotateSecretCmd.MarkFlagRequired("name")
+	_ = RotateSecretCmd.MarkFlagRequired("secret-file")
+}
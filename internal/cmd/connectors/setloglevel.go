@@ -0,0 +1,63 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package connectors
+
+import (
+	"strconv"
+
+	"internal/apiclient"
+
+	"internal/client/connections"
+
+	"github.com/spf13/cobra"
+)
+
+// SetLogLevelCmd to change the log configuration of a connection
+var SetLogLevelCmd = &cobra.Command{
+	Use:   "set-log-level",
+	Short: "Set the log configuration of an existing connection",
+	Long:  "Enable or disable logging and set the log level of an existing connection",
+	Args: func(cmd *cobra.Command, args []string) (err error) {
+		cmdProject := cmd.Flag("proj")
+		cmdRegion := cmd.Flag("reg")
+
+		if err = apiclient.SetRegion(cmdRegion.Value.String()); err != nil {
+			return err
+		}
+		return apiclient.SetProjectID(cmdProject.Value.String())
+	},
+	RunE: func(cmd *cobra.Command, args []string) (err error) {
+		name := cmd.Flag("name").Value.String()
+		level := cmd.Flag("level").Value.String()
+		enabled, _ := strconv.ParseBool(cmd.Flag("enabled").Value.String())
+
+		_, err = connections.PatchLogConfig(name, enabled, level)
+		return err
+	},
+}
+
+func init() {
+	var name, level string
+	var enabled bool
+
+	SetLogLevelCmd.Flags().StringVarP(&name, "name", "n",
+		"", "Connection name")
+	SetLogLevelCmd.Flags().StringVarP(&level, "level", "l",
+		"", "Log level; one of ERROR, INFO or DEBUG")
+	SetLogLevelCmd.Flags().BoolVarP(&enabled, "enabled", "",
+		true, "Enable logging for the connection")
+
+	_ = SetLogLevelCmd.MarkFlagRequired("name")
+}
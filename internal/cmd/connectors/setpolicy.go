@@ -0,0 +1,58 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package connectors
+
+import (
+	"strconv"
+
+	"internal/apiclient"
+
+	"internal/client/connections"
+
+	"github.com/spf13/cobra"
+)
+
+// SetPolicyCmd to set the IAM policy from a file
+var SetPolicyCmd = &cobra.Command{
+	Use:   "setpolicy",
+	Short: "Set the IAM policy of a Connection from a file",
+	Long: "Replace the IAM policy of a Connection with the policy in a file, as printed by " +
+		"iam get --json. The etag in the file must match the current policy. Use --dry-run to " +
+		"list the bindings that change",
+	Args: func(cmd *cobra.Command, args []string) (err error) {
+		if err = apiclient.SetRegion(cmd.Flag("reg").Value.String()); err != nil {
+			return err
+		}
+		return apiclient.SetProjectID(cmd.Flag("proj").Value.String())
+	},
+	RunE: func(cmd *cobra.Command, args []string) (err error) {
+		name := cmd.Flag("name").Value.String()
+		dryRun, _ := strconv.ParseBool(cmd.Flag("dry-run").Value.String())
+		_, err = connections.SetIAMFromFile(name, cmd.Flag("file").Value.String(), dryRun)
+		return err
+	},
+}
+
+func init() {
+	var file string
+	dryRun := false
+
+	SetPolicyCmd.Flags().StringVarP(&file, "file", "f",
+		"", "IAM policy JSON file path")
+	SetPolicyCmd.Flags().BoolVarP(&dryRun, "dry-run", "",
+		false, "Print the policy and the bindings that change without setting it; default is false")
+
+	_ = SetPolicyCmd.MarkFlagRequired("file")
+}
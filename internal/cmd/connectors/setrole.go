@@ -16,6 +16,7 @@ package connectors
 
 import (
 	"fmt"
+	"strconv"
 
 	"internal/apiclient"
 
@@ -28,7 +29,7 @@ import (
 var SetRoleCmd = &cobra.Command{
 	Use:   "setrole",
 	Short: "Set Connection IAM policy on a Connection",
-	Long:  "Set Connection IAM policy on a Connection",
+	Long:  "Set Connection IAM policy on a Connection. Use --dry-run to list the bindings that change",
 	Args: func(cmd *cobra.Command, args []string) (err error) {
 		if err = apiclient.SetRegion(cmd.Flag("reg").Value.String()); err != nil {
 			return err
@@ -40,7 +41,8 @@ var SetRoleCmd = &cobra.Command{
 		role := cmd.Flag("role").Value.String()
 		memberName := cmd.Flag("member").Value.String()
 		memberType := cmd.Flag("member-type").Value.String()
-		return connections.SetIAM(name, memberName, role, memberType)
+		dryRun, _ := strconv.ParseBool(cmd.Flag("dry-run").Value.String())
+		return connections.SetIAM(name, memberName, role, memberType, dryRun)
 	},
 }
 
@@ -74,6 +76,7 @@ func (c *connectorRole) Type() string {
 func init() {
 	var memberName, memberType, name string
 	var role connectorRole
+	dryRun := false
 
 	SetRoleCmd.Flags().StringVarP(&name, "name", "n",
 		"", "The name of the connection")
@@ -83,6 +86,8 @@ func init() {
 		"", "Member Name, example Service Account Name")
 	SetRoleCmd.Flags().StringVarP(&memberType, "member-type", "",
 		"serviceAccount", "memberType must be serviceAccount, user, or group (default serviceAccount)")
+	SetRoleCmd.Flags().BoolVarP(&dryRun, "dry-run", "",
+		false, "Print the new policy and the bindings that change without setting it; default is false")
 
 	_ = SetRoleCmd.MarkFlagRequired("name")
 	_ = SetRoleCmd.MarkFlagRequired("role")
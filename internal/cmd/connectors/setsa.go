@@ -0,0 +1,63 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package connectors
+
+import (
+	"strconv"
+
+	"internal/apiclient"
+
+	"internal/client/connections"
+
+	"github.com/spf13/cobra"
+)
+
+// SetServiceAccountCmd to change the service account of a connection
+var SetServiceAccountCmd = &cobra.Command{
+	Use:   "set-service-account",
+	Short: "Change the service account of a connection",
+	Long: "Change the service account used by a connection, optionally granting the new " +
+		"service account access to the connector resources and secrets",
+	Args: func(cmd *cobra.Command, args []string) (err error) {
+		cmdProject := cmd.Flag("proj")
+		cmdRegion := cmd.Flag("reg")
+
+		if err = apiclient.SetRegion(cmdRegion.Value.String()); err != nil {
+			return err
+		}
+		return apiclient.SetProjectID(cmdProject.Value.String())
+	},
+	RunE: func(cmd *cobra.Command, args []string) (err error) {
+		grantPermission, _ := strconv.ParseBool(cmd.Flag("grant-permission").Value.String())
+		_, err = connections.SetServiceAccount(cmd.Flag("name").Value.String(),
+			cmd.Flag("sa").Value.String(), grantPermission)
+		return err
+	},
+}
+
+func init() {
+	var name, serviceAccount string
+	var grantPermission bool
+
+	SetServiceAccountCmd.Flags().StringVarP(&name, "name", "n",
+		"", "Connection name")
+	SetServiceAccountCmd.Flags().StringVarP(&serviceAccount, "sa", "",
+		"", "Service account name or email; short names are in the current project")
+	SetServiceAccountCmd.Flags().BoolVarP(&grantPermission, "grant-permission", "g",
+		false, "Grant the service account permissions to the connector resources and secrets")
+
+	_ = SetServiceAccountCmd.MarkFlagRequired("name")
+	_ = SetServiceAccountCmd.MarkFlagRequired("sa")
+}
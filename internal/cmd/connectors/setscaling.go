@@ -0,0 +1,70 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package connectors
+
+import (
+	"strconv"
+
+	"internal/apiclient"
+
+	"internal/client/connections"
+
+	"github.com/spf13/cobra"
+)
+
+// SetScalingCmd to patch the node config of a connection
+var SetScalingCmd = &cobra.Command{
+	Use:   "set-scaling",
+	Short: "Set the min and max node count of a connection",
+	Long:  "Patch only the nodeConfig of a connection with the min and max node count",
+	Args: func(cmd *cobra.Command, args []string) (err error) {
+		cmdProject := cmd.Flag("proj")
+		cmdRegion := cmd.Flag("reg")
+
+		if err = apiclient.SetRegion(cmdRegion.Value.String()); err != nil {
+			return err
+		}
+		return apiclient.SetProjectID(cmdProject.Value.String())
+	},
+	RunE: func(cmd *cobra.Command, args []string) (err error) {
+		if err = setWaitTiming(cmd); err != nil {
+			return err
+		}
+		minNodes, _ := strconv.Atoi(cmd.Flag("min").Value.String())
+		maxNodes, _ := strconv.Atoi(cmd.Flag("max").Value.String())
+		wait, _ := strconv.ParseBool(cmd.Flag("wait").Value.String())
+		_, err = connections.PatchNodeCounts(cmd.Flag("name").Value.String(), minNodes, maxNodes, wait)
+		return err
+	},
+}
+
+func init() {
+	var name string
+	var minNodes, maxNodes int
+	var wait bool
+
+	SetScalingCmd.Flags().StringVarP(&name, "name", "n",
+		"", "The name of the connection")
+	SetScalingCmd.Flags().IntVarP(&minNodes, "min", "",
+		-1, "Min node count, at least 1")
+	SetScalingCmd.Flags().IntVarP(&maxNodes, "max", "",
+		-1, "Max node count, at least min")
+	SetScalingCmd.Flags().BoolVarP(&wait, "wait", "",
+		false, "Waits for the patch to finish, with success or error; default is false")
+	addWaitFlags(SetScalingCmd)
+
+	SetScalingCmd.MarkFlagsOneRequired("min", "max")
+	_ = SetScalingCmd.MarkFlagRequired("name")
+}
@@ -0,0 +1,72 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package connectors
+
+import (
+	"internal/apiclient"
+
+	"internal/client/connections"
+
+	"github.com/spf13/cobra"
+)
+
+// TemplateCmd to generate a skeleton connection
+var TemplateCmd = &cobra.Command{
+	Use:   "template",
+	Short: "Generate a connection template for a connector",
+	Long: "Generate a skeleton connection JSON for a connector version with the required " +
+		"config variables and the auth block filled with placeholders. With --openapi-file, generate " +
+		"it for the generic REST connector from an OpenAPI spec instead",
+	Args: func(cmd *cobra.Command, args []string) (err error) {
+		cmdProject := cmd.Flag("proj")
+		cmdRegion := cmd.Flag("reg")
+
+		if err = apiclient.SetRegion(cmdRegion.Value.String()); err != nil {
+			return err
+		}
+		return apiclient.SetProjectID(cmdProject.Value.String())
+	},
+	RunE: func(cmd *cobra.Command, args []string) (err error) {
+		if specFile := cmd.Flag("openapi-file").Value.String(); specFile != "" {
+			_, err = connections.GenerateFromOpenAPI(specFile, cmd.Flag("provider").Value.String())
+			return err
+		}
+		_, err = connections.GenerateTemplate(cmd.Flag("provider").Value.String(),
+			cmd.Flag("connector").Value.String(), templateVersion, cmd.Flag("auth-type").Value.String())
+		return err
+	},
+}
+
+var templateVersion int
+
+func init() {
+	var provider, connector, authType, specFile string
+
+	TemplateCmd.Flags().StringVarP(&provider, "provider", "",
+		"gcp", "Connector provider")
+	TemplateCmd.Flags().StringVarP(&connector, "connector", "c",
+		"", "Connector name, for ex: pubsub")
+	TemplateCmd.Flags().IntVarP(&templateVersion, "version", "v",
+		1, "Connector version")
+	TemplateCmd.Flags().StringVarP(&authType, "auth-type", "",
+		"", "Auth type, for ex: USER_PASSWORD or OAUTH2_JWT_BEARER")
+
+	TemplateCmd.Flags().StringVarP(&specFile, "openapi-file", "",
+		"", "OpenAPI 3 or Swagger 2 spec, in JSON or YAML, to generate a REST connection from")
+
+	TemplateCmd.MarkFlagsOneRequired("connector", "openapi-file")
+	TemplateCmd.MarkFlagsMutuallyExclusive("connector", "openapi-file")
+	TemplateCmd.MarkFlagsMutuallyExclusive("auth-type", "openapi-file")
+}
@@ -15,9 +15,6 @@
 package connectors
 
 import (
-	"errors"
-	"fmt"
-
 	"internal/apiclient"
 
 	"internal/client/connections"
@@ -37,38 +34,13 @@ var UpdateNodeCountCmd = &cobra.Command{
 		if err = apiclient.SetRegion(cmdRegion.Value.String()); err != nil {
 			return err
 		}
-		if min == -1 && max == -1 {
-			return errors.New("min or max must be set")
-		}
-		if min == 0 || max == 0 {
-			return errors.New("min or max cannot be set to 0")
-		}
-		if min > max && max != -1 {
-			return errors.New("min cannot be set higher than max")
+		if err = connections.ValidateNodeCounts(min, max); err != nil {
+			return err
 		}
 		return apiclient.SetProjectID(cmdProject.Value.String())
 	},
 	RunE: func(cmd *cobra.Command, args []string) (err error) {
-		nodeConfig := []string{}
-		var nodeCount string
-		name := cmd.Flag("name").Value.String()
-
-		content := "{\"nodeConfig\": {"
-
-		if min != -1 && max != -1 {
-			nodeConfig = append(nodeConfig, "nodeConfig.minNodeCount")
-			nodeConfig = append(nodeConfig, "nodeConfig.maxNodeCount")
-			nodeCount = fmt.Sprintf("\"maxNodeCount\": %d", max) + "," + fmt.Sprintf("\"minNodeCount\": %d", min)
-		} else if min != -1 {
-			nodeConfig = append(nodeConfig, "nodeConfig.minNodeCount")
-			nodeCount = fmt.Sprintf("\"minNodeCount\": %d", min)
-		} else if max != -1 {
-			nodeConfig = append(nodeConfig, "nodeConfig.maxNodeCount")
-			nodeCount = fmt.Sprintf("\"maxNodeCount\": %d", max)
-		}
-
-		content = content + nodeCount + "}}"
-		_, err = connections.Patch(name, []byte(content), nodeConfig)
+		_, err = connections.PatchNodeCounts(cmd.Flag("name").Value.String(), min, max, false)
 		return err
 	},
 }
@@ -0,0 +1,42 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package connectors
+
+import (
+	"internal/client/connections"
+
+	"github.com/spf13/cobra"
+)
+
+// ValidateFolderCmd to validate a folder of connection files
+var ValidateFolderCmd = &cobra.Command{
+	Use:   "validate-folder",
+	Short: "Validate a folder of connection files",
+	Long: "Validate every connection file in a folder without calling the API and print the " +
+		"problems found by file. Exits with an error if any file is invalid, so it can be used " +
+		"as an offline check before an import",
+	RunE: func(cmd *cobra.Command, args []string) (err error) {
+		return connections.ValidateFolder(cmd.Flag("folder").Value.String())
+	},
+}
+
+func init() {
+	var folder string
+
+	ValidateFolderCmd.Flags().StringVarP(&folder, "folder", "f",
+		"", "Folder with the connection files")
+
+	_ = ValidateFolderCmd.MarkFlagRequired("folder")
+}
@@ -22,7 +22,8 @@ import (
 var Cmd = &cobra.Command{
 	Use:   "endpoints",
 	Short: "Manage endpoint attachments for connections",
-	Long:  "Manage endpoint attachments for connections",
+	Long: "Manage endpoint attachments for connections. Endpoint attachments have no IAM policy " +
+		"of their own, access to them is granted with project level Connectors roles",
 }
 
 func init() {
@@ -15,6 +15,9 @@
 package endpoints
 
 import (
+	"fmt"
+	"strconv"
+
 	"internal/apiclient"
 
 	"internal/client/connections"
@@ -37,18 +40,42 @@ var ListCmd = &cobra.Command{
 		return apiclient.SetProjectID(project)
 	},
 	RunE: func(cmd *cobra.Command, args []string) (err error) {
+		var respBody []byte
 		pageToken := cmd.Flag("pageToken").Value.String()
 		filter := cmd.Flag("filter").Value.String()
+		all, _ := strconv.ParseBool(cmd.Flag("all").Value.String())
+		format := cmd.Flag("format").Value.String()
+
+		switch format {
+		case "json":
+		case "table":
+			apiclient.DisableCmdPrintHttpResponse()
+		default:
+			return fmt.Errorf("format must be one of json or table")
+		}
 
-		_, err = connections.ListEndpoints(pageSize, pageToken, filter, "")
-		return err
+		if all {
+			respBody, err = connections.ListAllEndpoints(filter, "")
+		} else {
+			respBody, err = connections.ListEndpoints(pageSize, pageToken, filter, "")
+		}
+		if format != "table" {
+			return err
+		}
+
+		apiclient.EnableCmdPrintHttpResponse()
+		if err != nil {
+			return err
+		}
+		return connections.PrintEndpointsTable(respBody)
 	},
 }
 
 var pageSize int
 
 func init() {
-	var pageToken, filter string
+	var pageToken, filter, format string
+	var all bool
 
 	ListCmd.Flags().IntVarP(&pageSize, "pageSize", "",
 		-1, "The maximum number of versions to return")
@@ -56,4 +83,8 @@ func init() {
 		"", "A page token, received from a previous call")
 	ListCmd.Flags().StringVarP(&filter, "filter", "",
 		"", "Filter results")
+	ListCmd.Flags().BoolVarP(&all, "all", "",
+		false, "Follow page tokens and return all endpoint attachments")
+	ListCmd.Flags().StringVarP(&format, "format", "",
+		"json", "Output format; one of json or table")
 }
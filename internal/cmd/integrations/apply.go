@@ -66,6 +66,8 @@ var ApplyCmd = &cobra.Command{
 	RunE: func(cmd *cobra.Command, args []string) (err error) {
 		var skaffoldConfigUri string
 
+		allowPreview, _ := strconv.ParseBool(cmd.Flag("allow-preview").Value.String())
+
 		if folder == "" {
 			skaffoldConfigUri, err = apiclient.GetCloudDeployGCSLocations(pipeline, release)
 			if err != nil {
@@ -123,7 +125,7 @@ var ApplyCmd = &cobra.Command{
 				return err
 			}
 
-			if err = processConnectors(connectorsFolder, grantPermission, createSecret, wait); err != nil {
+			if err = processConnectors(connectorsFolder, grantPermission, createSecret, wait, allowPreview); err != nil {
 				return err
 			}
 		} else {
@@ -150,7 +152,7 @@ var ApplyCmd = &cobra.Command{
 var serviceAccountName, serviceAccountProject, encryptionKey, pipeline, release, outputGCSPath string
 
 func init() {
-	grantPermission, createSecret, wait := false, false, false
+	grantPermission, createSecret, wait, allowPreview := false, false, false, false
 
 	ApplyCmd.Flags().StringVarP(&folder, "folder", "f",
 		"", "Folder containing scaffolding configuration")
@@ -176,6 +178,8 @@ func init() {
 		false, "Create Secret Manager secrets when creating the connection; default is false")
 	ApplyCmd.Flags().BoolVarP(&wait, "wait", "",
 		false, "Waits for the connector to finish, with success or error; default is false")
+	ApplyCmd.Flags().BoolVarP(&allowPreview, "allow-preview", "",
+		false, "Allow creating connections on connector versions that are not GA; default is false")
 	ApplyCmd.Flags().BoolVarP(&skipConnectors, "skip-connectors", "",
 		false, "Skip applying connector configuration; default is false")
 	ApplyCmd.Flags().BoolVarP(&skipAuthconfigs, "skip-authconfigs", "",
@@ -338,7 +342,9 @@ func processManagedZones(zonesFolder string) (err error) {
 	return nil
 }
 
-func processConnectors(connectorsFolder string, grantPermission bool, createSecret bool, wait bool) (err error) {
+func processConnectors(connectorsFolder string, grantPermission bool, createSecret bool, wait bool,
+	allowPreview bool,
+) (err error) {
 	var stat fs.FileInfo
 	rJSONFiles := regexp.MustCompile(`(\S*)\.json`)
 
@@ -352,7 +358,7 @@ func processConnectors(connectorsFolder string, grantPermission bool, createSecr
 				connectionFile := filepath.Base(path)
 				if rJSONFiles.MatchString(connectionFile) {
 					clilog.Info.Printf("Found configuration for connection: %s\n", connectionFile)
-					_, err = connections.Get(getFilenameWithoutExtension(connectionFile), "", true, false)
+					_, err = connections.Get(getFilenameWithoutExtension(connectionFile), "", true, false, false)
 					// create the connection only if the connection is not found
 					if err != nil {
 						connectionBytes, err := utils.ReadFile(path)
@@ -368,7 +374,10 @@ func processConnectors(connectorsFolder string, grantPermission bool, createSecr
 							encryptionKey,
 							grantPermission,
 							createSecret,
-							wait); err != nil {
+							wait,
+							false,
+							false,
+							connections.CreateOptions{AllowPreview: allowPreview}); err != nil {
 							return err
 						}
 					} else {
@@ -0,0 +1,40 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sfdcinstances
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// ChannelsCmd to manage sfdc channels of an instance
+var ChannelsCmd = &cobra.Command{
+	Use:   "channels",
+	Short: "Manage SFDC channels of an SFDC instance",
+	Long:  "Manage SFDC channels (platform event subscriptions) of an SFDC instance",
+}
+
+func init() {
+	var instance string
+
+	ChannelsCmd.PersistentFlags().StringVarP(&instance, "instance", "i",
+		"", "sfdc instance uuid")
+
+	_ = ChannelsCmd.MarkPersistentFlagRequired("instance")
+
+	ChannelsCmd.AddCommand(ListChannelsCmd)
+	ChannelsCmd.AddCommand(GetChannelCmd)
+	ChannelsCmd.AddCommand(CrtChannelCmd)
+	ChannelsCmd.AddCommand(DelChannelCmd)
+}
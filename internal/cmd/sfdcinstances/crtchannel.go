@@ -0,0 +1,67 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sfdcinstances
+
+import (
+	"fmt"
+	"os"
+
+	"internal/apiclient"
+
+	"internal/client/sfdc"
+
+	"github.com/spf13/cobra"
+)
+
+// CrtChannelCmd to create an sfdc channel
+var CrtChannelCmd = &cobra.Command{
+	Use:   "create",
+	Short: "Create an sfdcchannel in an sfdcinstance",
+	Long:  "Create an sfdcchannel in an sfdcinstance",
+	Args: func(cmd *cobra.Command, args []string) (err error) {
+		project := cmd.Flag("proj").Value.String()
+		region := cmd.Flag("reg").Value.String()
+
+		if err = apiclient.SetRegion(region); err != nil {
+			return err
+		}
+		return apiclient.SetProjectID(project)
+	},
+	RunE: func(cmd *cobra.Command, args []string) (err error) {
+		instance := cmd.Flag("instance").Value.String()
+		channelFile := cmd.Flag("file").Value.String()
+
+		if _, err = os.Stat(channelFile); err != nil {
+			return fmt.Errorf("unable to open file %w", err)
+		}
+
+		content, err := os.ReadFile(channelFile)
+		if err != nil {
+			return fmt.Errorf("unable to open file %w", err)
+		}
+
+		_, err = sfdc.CreateChannelFromContent(instance, content)
+		return err
+	},
+}
+
+func init() {
+	var channelFile string
+
+	CrtChannelCmd.Flags().StringVarP(&channelFile, "file", "f",
+		"", "sfdc channel details JSON file path")
+
+	_ = CrtChannelCmd.MarkFlagRequired("file")
+}
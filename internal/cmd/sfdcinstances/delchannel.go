@@ -0,0 +1,54 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sfdcinstances
+
+import (
+	"internal/apiclient"
+
+	"internal/client/sfdc"
+
+	"github.com/spf13/cobra"
+)
+
+// DelChannelCmd to delete an sfdc channel
+var DelChannelCmd = &cobra.Command{
+	Use:   "delete",
+	Short: "Delete an sfdcchannel from an sfdcinstance",
+	Long:  "Delete an sfdcchannel from an sfdcinstance",
+	Args: func(cmd *cobra.Command, args []string) (err error) {
+		project := cmd.Flag("proj").Value.String()
+		region := cmd.Flag("reg").Value.String()
+
+		if err = apiclient.SetRegion(region); err != nil {
+			return err
+		}
+		return apiclient.SetProjectID(project)
+	},
+	RunE: func(cmd *cobra.Command, args []string) (err error) {
+		instance := cmd.Flag("instance").Value.String()
+		channel := cmd.Flag("channel").Value.String()
+		_, err = sfdc.DeleteChannel(channel, instance)
+		return err
+	},
+}
+
+func init() {
+	var channel string
+
+	DelChannelCmd.Flags().StringVarP(&channel, "channel", "c",
+		"", "sfdc channel uuid")
+
+	_ = DelChannelCmd.MarkFlagRequired("channel")
+}
@@ -0,0 +1,61 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sfdcinstances
+
+import (
+	"strconv"
+
+	"internal/apiclient"
+
+	"internal/client/sfdc"
+
+	"github.com/spf13/cobra"
+)
+
+// GetChannelCmd to get an sfdc channel
+var GetChannelCmd = &cobra.Command{
+	Use:   "get",
+	Short: "Get an sfdcchannel of an sfdcinstance",
+	Long:  "Get an sfdcchannel of an sfdcinstance",
+	Args: func(cmd *cobra.Command, args []string) (err error) {
+		project := cmd.Flag("proj").Value.String()
+		region := cmd.Flag("reg").Value.String()
+
+		if err = apiclient.SetRegion(region); err != nil {
+			return err
+		}
+		return apiclient.SetProjectID(project)
+	},
+	RunE: func(cmd *cobra.Command, args []string) (err error) {
+		instance := cmd.Flag("instance").Value.String()
+		channel := cmd.Flag("channel").Value.String()
+		minimal, _ := strconv.ParseBool(cmd.Flag("minimal").Value.String())
+
+		_, err = sfdc.GetChannel(channel, instance, minimal)
+		return err
+	},
+}
+
+func init() {
+	var channel string
+	minimal := false
+
+	GetChannelCmd.Flags().StringVarP(&channel, "channel", "c",
+		"", "sfdc channel uuid")
+	GetChannelCmd.Flags().BoolVarP(&minimal, "minimal", "",
+		false, "Minimal number of fields returned; default is false")
+
+	_ = GetChannelCmd.MarkFlagRequired("channel")
+}
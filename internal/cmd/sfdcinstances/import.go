@@ -0,0 +1,53 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sfdcinstances
+
+import (
+	"internal/apiclient"
+
+	"internal/client/sfdc"
+
+	"github.com/spf13/cobra"
+)
+
+// ImportCmd to import sfdcinstances
+var ImportCmd = &cobra.Command{
+	Use:   "import",
+	Short: "Import sfdcinstances to a region from a folder",
+	Long:  "Import sfdcinstances to a region from a folder; existing instances are skipped",
+	Args: func(cmd *cobra.Command, args []string) (err error) {
+		project := cmd.Flag("proj").Value.String()
+		region := cmd.Flag("reg").Value.String()
+
+		if err = apiclient.SetRegion(region); err != nil {
+			return err
+		}
+		return apiclient.SetProjectID(project)
+	},
+	RunE: func(cmd *cobra.Command, args []string) (err error) {
+		if err = apiclient.FolderExists(folder); err != nil {
+			return err
+		}
+
+		return sfdc.ImportInstances(folder)
+	},
+}
+
+func init() {
+	ImportCmd.Flags().StringVarP(&folder, "folder", "f",
+		"", "Folder to import sfdcinstances")
+
+	_ = ImportCmd.MarkFlagRequired("folder")
+}
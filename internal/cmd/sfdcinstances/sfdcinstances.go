@@ -36,4 +36,7 @@ func init() {
 
 	Cmd.AddCommand(GetCmd)
 	Cmd.AddCommand(ListCmd)
+	Cmd.AddCommand(ExportCmd)
+	Cmd.AddCommand(ImportCmd)
+	Cmd.AddCommand(ChannelsCmd)
 }
@@ -15,6 +15,7 @@
 package secmgr
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 
@@ -105,3 +106,88 @@ func Create(project string, secretId string, payload []byte) (version string, er
 
 	return secretVersion.Name, nil
 }
+
+// AddVersion adds a new version to an existing secret and returns its name
+func AddVersion(project string, secretId string, payload []byte) (version string, err error) {
+	ctx := context.Background()
+
+	c, err := secretmanager.NewClient(ctx)
+	if err != nil {
+		return "", err
+	}
+	defer c.Close()
+
+	// Build the request.
+	addSecretVersionReq := &secretmanagerpb.AddSecretVersionRequest{
+		Parent: fmt.Sprintf("projects/%s/secrets/%s", project, secretId),
+		Payload: &secretmanagerpb.SecretPayload{
+			Data: payload,
+		},
+	}
+
+	// Call the API.
+	secretVersion, err := c.AddSecretVersion(ctx, addSecretVersionReq)
+	if err != nil {
+		return "", err
+	}
+
+	return secretVersion.Name, nil
+}
+
+// Access returns the payload of a secret version
+func Access(secretVersion string) (payload []byte, err error) {
+	ctx := context.Background()
+
+	c, err := secretmanager.NewClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer c.Close()
+
+	// Build the request.
+	req := &secretmanagerpb.AccessSecretVersionRequest{
+		Name: secretVersion,
+	}
+
+	// Call the API.
+	resp, err := c.AccessSecretVersion(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.Payload.Data, nil
+}
+
+// the Secret Manager calls of Upsert, replaced in tests
+var (
+	getLatestVersion = secretExists
+	accessVersion    = Access
+	createSecret     = Create
+	addVersion       = AddVersion
+)
+
+// Upsert creates the secret if it does not exist. If the secret exists, its latest
+// version is reused, unless update is set, in which case a new version is added when
+// the payload differs from the latest version. created reports if a version was added
+func Upsert(project string, secretId string, payload []byte, update bool) (version string, created bool, err error) {
+	if version, err = getLatestVersion(project, secretId); err != nil {
+		version, err = createSecret(project, secretId, payload)
+		return version, err == nil, err
+	}
+
+	if !update {
+		return version, false, nil
+	}
+
+	latest, err := accessVersion(version)
+	if err != nil {
+		return "", false, err
+	}
+
+	if bytes.Equal(latest, payload) {
+		return version, false, nil
+	}
+
+	version, err = addVersion(project, secretId, payload)
+	return version, err == nil, err
+}
@@ -0,0 +1,85 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package secmgr
+
+import (
+	"errors"
+	"testing"
+)
+
+// fakeSecrets replaces the Secret Manager calls of Upsert with an in memory secret
+// holding the payload of its latest version, if any
+func fakeSecrets(t *testing.T, latest []byte) (calls *[]string) {
+	calls = &[]string{}
+	getLatestVersion = func(project string, name string) (string, error) {
+		if latest == nil {
+			return "", errors.New("not found")
+		}
+		return "projects/p1/secrets/s1/versions/1", nil
+	}
+	accessVersion = func(version string) ([]byte, error) {
+		*calls = append(*calls, "access")
+		return latest, nil
+	}
+	createSecret = func(project string, name string, payload []byte) (string, error) {
+		*calls = append(*calls, "create")
+		return "projects/p1/secrets/s1/versions/1", nil
+	}
+	addVersion = func(project string, name string, payload []byte) (string, error) {
+		*calls = append(*calls, "add")
+		return "projects/p1/secrets/s1/versions/2", nil
+	}
+	t.Cleanup(func() {
+		getLatestVersion, accessVersion, createSecret, addVersion = secretExists, Access, Create, AddVersion
+	})
+	return calls
+}
+
+func TestUpsert(t *testing.T) {
+	tests := []struct {
+		name    string
+		latest  []byte
+		update  bool
+		version string
+		created bool
+		calls   []string
+	}{
+		{"create", nil, false, "projects/p1/secrets/s1/versions/1", true, []string{"create"}},
+		{"reuse", []byte("old"), false, "projects/p1/secrets/s1/versions/1", false, []string{}},
+		{"unchanged", []byte("new"), true, "projects/p1/secrets/s1/versions/1", false, []string{"access"}},
+		{"new version", []byte("old"), true, "projects/p1/secrets/s1/versions/2", true, []string{"access", "add"}},
+	}
+	for _, test := range tests {
+		calls := fakeSecrets(t, test.latest)
+		version, created, err := Upsert("p1", "s1", []byte("new"), test.update)
+		if err != nil {
+			t.Fatalf("%s: unexpected error %v", test.name, err)
+		}
+		if version != test.version || created != test.created {
+			t.Errorf("%s: expected %s (created %t), got %s (created %t)",
+				test.name, test.version, test.created, version, created)
+		}
+		if len(*calls) != len(test.calls) {
+			t.Errorf("%s: expected calls %v, got %v", test.name, test.calls, *calls)
+			continue
+		}
+		for i := range test.calls {
+			if (*calls)[i] != test.calls[i] {
+				t.Errorf("%s: expected calls %v, got %v", test.name, test.calls, *calls)
+				break
+			}
+		}
+	}
+}